@@ -0,0 +1,139 @@
+package bcc_terraform
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// cachedLookupPaths are the API paths whose GET responses are kept by the
+// lookup cache, the lists the helpers search by name or id. Single objects are
+// never kept, WaitLock polls them.
+var cachedLookupPaths = []string{
+	"v1/project",
+	"v1/vdc",
+	"v1/storage_profile",
+	"v1/template",
+	"v1/platform",
+	"v1/network",
+	"v1/dns",
+	"v1/s3_storage",
+}
+
+type cachedResponse struct {
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// lookupCache keeps the responses of lookups for a short time, so a refresh
+// of many resources does not list the same objects over and over. Any write
+// request drops the whole cache.
+type lookupCache struct {
+	ttl time.Duration
+
+	mu         sync.Mutex
+	generation uint64
+	responses  map[string]*cachedResponse
+}
+
+func newLookupCache(ttl time.Duration) *lookupCache {
+	return &lookupCache{ttl: ttl, responses: make(map[string]*cachedResponse)}
+}
+
+func (c *lookupCache) get(key string) (*cachedResponse, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	resp, ok := c.responses[key]
+	if ok && time.Now().After(resp.expires) {
+		delete(c.responses, key)
+		resp = nil
+	}
+	return resp, c.generation
+}
+
+// put keeps the response unless the cache was invalidated since the request
+// was sent, the response may be stale then.
+func (c *lookupCache) put(key string, generation uint64, resp *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	resp.expires = time.Now().Add(c.ttl)
+	c.responses[key] = resp
+}
+
+func (c *lookupCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.responses = make(map[string]*cachedResponse)
+}
+
+func isCachedLookup(req *http.Request) bool {
+	path := strings.TrimPrefix(req.URL.Path, "/")
+	for _, item := range cachedLookupPaths {
+		if path == item {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheTransport answers lookups from the lookup cache and drops the cache on
+// every other request.
+type cacheTransport struct {
+	next  http.RoundTripper
+	cache *lookupCache
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		t.cache.invalidate()
+		return t.next.RoundTrip(req)
+	}
+	if !isCachedLookup(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	cached, generation := t.cache.get(key)
+	if cached != nil {
+		tflog.SubsystemTrace(subsystemLogContext(req.Context(), apiSubsystem), apiSubsystem, "API response from the lookup cache", map[string]interface{}{
+			"method": req.Method,
+			"path":   req.URL.Path,
+		})
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", cached.status, http.StatusText(cached.status)),
+			StatusCode:    cached.status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        cached.header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(cached.body)),
+			ContentLength: int64(len(cached.body)),
+			Request:       req,
+		}, nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.cache.put(key, generation, &cachedResponse{status: resp.StatusCode, header: resp.Header.Clone(), body: body})
+	return resp, nil
+}
@@ -0,0 +1,116 @@
+package bcc_terraform
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingTransport answers every request with the given body and counts the
+// requests by "METHOD path".
+type countingTransport struct {
+	body     string
+	requests map[string]int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests[req.Method+" "+req.URL.Path]++
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(t.body)),
+		Request:    req,
+	}, nil
+}
+
+func testCacheClient(body string, ttl time.Duration) (*http.Client, *countingTransport) {
+	next := &countingTransport{body: body, requests: map[string]int{}}
+	return &http.Client{Transport: &cacheTransport{next: next, cache: newLookupCache(ttl)}}, next
+}
+
+func testCacheRequest(t *testing.T, client *http.Client, method, url string) string {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestLookupCache_reusesLists(t *testing.T) {
+	client, next := testCacheClient(`{"total": 0, "items": []}`, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		body := testCacheRequest(t, client, "GET", "http://bcc/v1/vdc?page=1")
+		if body != `{"total": 0, "items": []}` {
+			t.Fatalf("unexpected body %q", body)
+		}
+	}
+	if got := next.requests["GET /v1/vdc"]; got != 1 {
+		t.Fatalf("expected 1 request, got %d", got)
+	}
+
+	testCacheRequest(t, client, "GET", "http://bcc/v1/vdc?page=2")
+	if got := next.requests["GET /v1/vdc"]; got != 2 {
+		t.Fatalf("expected the other page to be requested, got %d requests", got)
+	}
+}
+
+func TestLookupCache_invalidatedByWrites(t *testing.T) {
+	client, next := testCacheClient(`{"total": 0, "items": []}`, time.Minute)
+
+	testCacheRequest(t, client, "GET", "http://bcc/v1/network")
+	testCacheRequest(t, client, "POST", "http://bcc/v1/vdc/1/network")
+	testCacheRequest(t, client, "GET", "http://bcc/v1/network")
+	if got := next.requests["GET /v1/network"]; got != 2 {
+		t.Fatalf("expected 2 requests, got %d", got)
+	}
+}
+
+func TestLookupCache_expires(t *testing.T) {
+	client, next := testCacheClient(`{"total": 0, "items": []}`, 10*time.Millisecond)
+
+	testCacheRequest(t, client, "GET", "http://bcc/v1/template")
+	time.Sleep(20 * time.Millisecond)
+	testCacheRequest(t, client, "GET", "http://bcc/v1/template")
+	if got := next.requests["GET /v1/template"]; got != 2 {
+		t.Fatalf("expected 2 requests, got %d", got)
+	}
+}
+
+func TestLookupCache_skipsObjects(t *testing.T) {
+	client, next := testCacheClient(`{"id": "1", "locked": false}`, time.Minute)
+
+	for _, path := range []string{"/v1/disk/1", "/v1/project/1"} {
+		testCacheRequest(t, client, "GET", "http://bcc"+path)
+		testCacheRequest(t, client, "GET", "http://bcc"+path)
+		if got := next.requests["GET "+path]; got != 2 {
+			t.Fatalf("expected %s not to be cached, got %d requests", path, got)
+		}
+	}
+}
+
+func TestLookupCache_keepsStatus(t *testing.T) {
+	client, _ := testCacheClient(`{"total": 0, "items": []}`, time.Minute)
+
+	testCacheRequest(t, client, "GET", "http://bcc/v1/vdc")
+	resp, err := client.Get("http://bcc/v1/vdc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.Status != "200 OK" {
+		t.Fatalf("expected the status 200 OK, got %q", resp.Status)
+	}
+}
@@ -0,0 +1,214 @@
+package bcc_terraform
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// validateCidr accepts a network in CIDR notation, the host bits of the
+// address must be zero.
+func validateCidr(v interface{}, k string) (ws []string, es []error) {
+	value := v.(string)
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		es = append(es, fmt.Errorf("expected %s to be a network in CIDR notation, e.g. 10.0.1.0/24, got %s", k, value))
+		return
+	}
+	if prefix.Masked() != prefix {
+		es = append(es, fmt.Errorf("expected %s to be a network address, got %s, did you mean %s?", k, value, prefix.Masked()))
+	}
+	return
+}
+
+// subnetAddresses are the addresses of a subnet as configured.
+type subnetAddresses struct {
+	cidr    string
+	gateway string
+	startIp string
+	endIp   string
+}
+
+func expandSubnetAddresses(args map[string]interface{}) subnetAddresses {
+	return subnetAddresses{
+		cidr:    args["cidr"].(string),
+		gateway: args["gateway"].(string),
+		startIp: args["start_ip"].(string),
+		endIp:   args["end_ip"].(string),
+	}
+}
+
+// validate checks that the gateway and the pool are inside the cidr and that
+// the gateway is no address of the pool. Values which don't parse, e.g. ones
+// unknown at plan time, are skipped: the schema validation reports them.
+func (s subnetAddresses) validate() error {
+	prefix, err := netip.ParsePrefix(s.cidr)
+	if err != nil || prefix.Masked() != prefix {
+		return nil
+	}
+	gateway, gatewayErr := netip.ParseAddr(s.gateway)
+	startIp, startErr := netip.ParseAddr(s.startIp)
+	endIp, endErr := netip.ParseAddr(s.endIp)
+
+	for _, field := range []struct {
+		name string
+		addr netip.Addr
+		err  error
+	}{{"gateway", gateway, gatewayErr}, {"start_ip", startIp, startErr}, {"end_ip", endIp, endErr}} {
+		if field.err == nil && !prefix.Contains(field.addr) {
+			return fmt.Errorf("%s: %s is not inside the cidr %s", field.name, field.addr, prefix)
+		}
+	}
+	if startErr != nil || endErr != nil {
+		return nil
+	}
+	if endIp.Less(startIp) {
+		return fmt.Errorf("end_ip: the pool %s - %s ends before it starts", startIp, endIp)
+	}
+	if gatewayErr == nil && !gateway.Less(startIp) && !endIp.Less(gateway) {
+		return fmt.Errorf("gateway: %s is inside the pool %s - %s", gateway, startIp, endIp)
+	}
+	return nil
+}
+
+// cidrsOverlap reports whether the networks share addresses, false if one of
+// them doesn't parse.
+func cidrsOverlap(a string, b string) bool {
+	prefixA, errA := netip.ParsePrefix(a)
+	prefixB, errB := netip.ParsePrefix(b)
+	return errA == nil && errB == nil && prefixA.Overlaps(prefixB)
+}
+
+// findOverlappingSubnet looks for a subnet of the other internal networks of
+// the VDC which overlaps the cidr. The subnets of the network skipNetworkId
+// are not checked.
+func findOverlappingSubnet(manager *bcc.Manager, vdcId string, skipNetworkId string, cidr string) (*bcc.Network, *bcc.Subnet, error) {
+	vdc, err := manager.GetVdc(vdcId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("vdc_id: vdc '%s' not found: %s", vdcId, err)
+	}
+	networks, err := vdc.GetNetworks()
+	if err != nil {
+		return nil, nil, fmt.Errorf("crash via getting networks of the vdc: %s", err)
+	}
+	for _, network := range networks {
+		if network.ID == skipNetworkId || network.External {
+			continue
+		}
+		for i := range network.Subnets {
+			if cidrsOverlap(cidr, network.Subnets[i].CIDR) {
+				return network, &network.Subnets[i], nil
+			}
+		}
+	}
+	return nil, nil, nil
+}
+
+// customizeDiffNetworkSubnets validates the addresses of the `subnets` of a
+// network, and that they overlap neither each other nor the subnets of the
+// other networks of the VDC.
+func customizeDiffNetworkSubnets(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.HasChange("subnets") {
+		return nil
+	}
+	items := d.Get("subnets").([]interface{})
+	subnets := make([]subnetAddresses, len(items))
+	for i, item := range items {
+		subnets[i] = expandSubnetAddresses(item.(map[string]interface{}))
+		if err := subnets[i].validate(); err != nil {
+			return fmt.Errorf("subnets.%d.%s", i, err)
+		}
+		for j := 0; j < i; j++ {
+			if cidrsOverlap(subnets[i].cidr, subnets[j].cidr) {
+				return fmt.Errorf("subnets.%d.cidr: %s overlaps the cidr %s of subnets.%d", i, subnets[i].cidr, subnets[j].cidr, j)
+			}
+		}
+	}
+
+	vdcId := d.Get("vdc_id").(string)
+	if !d.NewValueKnown("vdc_id") || vdcId == "" {
+		return nil
+	}
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	for i, subnet := range subnets {
+		if _, err := netip.ParsePrefix(subnet.cidr); err != nil {
+			continue
+		}
+		network, other, err := findOverlappingSubnet(manager, vdcId, d.Id(), subnet.cidr)
+		if err != nil {
+			return err
+		}
+		if other != nil {
+			return fmt.Errorf("subnets.%d.cidr: %s overlaps the subnet %s of the network %s (%s)", i, subnet.cidr, other.CIDR, network.Name, network.ID)
+		}
+	}
+	return nil
+}
+
+// customizeDiffSubnet validates the addresses of a basis_subnet, and that it
+// overlaps no other subnet of its network or of the other networks of the VDC.
+func customizeDiffSubnet(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	subnet := subnetAddresses{
+		cidr:    d.Get("cidr").(string),
+		gateway: d.Get("gateway").(string),
+		startIp: d.Get("start_ip").(string),
+		endIp:   d.Get("end_ip").(string),
+	}
+	if err := subnet.validate(); err != nil {
+		return err
+	}
+
+	networkId := d.Get("network_id").(string)
+	if !d.HasChange("cidr") || !d.NewValueKnown("cidr") || !d.NewValueKnown("network_id") || networkId == "" {
+		return nil
+	}
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	network, err := manager.GetNetwork(networkId)
+	if err != nil {
+		return fmt.Errorf("network_id: network '%s' not found: %s", networkId, err)
+	}
+	for i := range network.Subnets {
+		if network.Subnets[i].ID != d.Id() && cidrsOverlap(subnet.cidr, network.Subnets[i].CIDR) {
+			return fmt.Errorf("cidr: %s overlaps the subnet %s of the network", subnet.cidr, network.Subnets[i].CIDR)
+		}
+	}
+	other, otherSubnet, err := findOverlappingSubnet(manager, network.Vdc.Id, network.ID, subnet.cidr)
+	if err != nil {
+		return err
+	}
+	if otherSubnet != nil {
+		return fmt.Errorf("cidr: %s overlaps the subnet %s of the network %s (%s)", subnet.cidr, otherSubnet.CIDR, other.Name, other.ID)
+	}
+	return nil
+}
+
+// customizeDiffPortIp checks that a configured ip_address is inside a subnet
+// of the network given by network_id.
+func customizeDiffPortIp(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.HasChanges("ip_address", "network_id") || !d.NewValueKnown("ip_address") || !d.NewValueKnown("network_id") {
+		return nil
+	}
+	ip, err := netip.ParseAddr(d.Get("ip_address").(string))
+	networkId := d.Get("network_id").(string)
+	if err != nil || networkId == "" {
+		return nil
+	}
+
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	network, err := manager.GetNetwork(networkId)
+	if err != nil {
+		return fmt.Errorf("network_id: network '%s' not found: %s", networkId, err)
+	}
+	cidrs := make([]string, 0, len(network.Subnets))
+	for _, subnet := range network.Subnets {
+		prefix, err := netip.ParsePrefix(subnet.CIDR)
+		if err == nil && prefix.Contains(ip) {
+			return nil
+		}
+		cidrs = append(cidrs, subnet.CIDR)
+	}
+	return fmt.Errorf("ip_address: %s is not inside a subnet of the network %s %v", ip, network.Name, cidrs)
+}
@@ -0,0 +1,71 @@
+package bcc_terraform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateCidr(t *testing.T) {
+	cases := map[string]string{
+		"10.0.1.0/24":   "",
+		"fd00::/64":     "",
+		"10.0.1.1/24":   "did you mean 10.0.1.0/24?",
+		"10.0.1.0":      "to be a network in CIDR notation",
+		"10.0.300.0/24": "to be a network in CIDR notation",
+		"10.0.1.0/33":   "to be a network in CIDR notation",
+		"not-a-network": "to be a network in CIDR notation",
+	}
+	for value, expected := range cases {
+		_, errs := validateCidr(value, "cidr")
+		switch {
+		case expected == "" && len(errs) > 0:
+			t.Errorf("%s: unexpected error %s", value, errs[0])
+		case expected != "" && (len(errs) == 0 || !strings.Contains(errs[0].Error(), expected)):
+			t.Errorf("%s: expected an error containing %q, got %v", value, expected, errs)
+		}
+	}
+}
+
+func TestSubnetAddresses_validate(t *testing.T) {
+	cases := []struct {
+		subnet   subnetAddresses
+		expected string
+	}{
+		{subnetAddresses{"10.0.1.0/24", "10.0.1.1", "10.0.1.2", "10.0.1.254"}, ""},
+		{subnetAddresses{"10.0.1.0/24", "10.0.1.254", "10.0.1.10", "10.0.1.100"}, ""},
+		{subnetAddresses{"10.0.1.0/24", "10.0.2.1", "10.0.1.2", "10.0.1.254"}, "gateway: 10.0.2.1 is not inside the cidr 10.0.1.0/24"},
+		{subnetAddresses{"10.0.1.0/24", "10.0.1.1", "10.0.0.2", "10.0.1.254"}, "start_ip: 10.0.0.2 is not inside the cidr"},
+		{subnetAddresses{"10.0.1.0/24", "10.0.1.1", "10.0.1.2", "10.0.2.254"}, "end_ip: 10.0.2.254 is not inside the cidr"},
+		{subnetAddresses{"10.0.1.0/24", "10.0.1.1", "10.0.1.200", "10.0.1.100"}, "end_ip: the pool 10.0.1.200 - 10.0.1.100 ends before it starts"},
+		{subnetAddresses{"10.0.1.0/24", "10.0.1.50", "10.0.1.2", "10.0.1.254"}, "gateway: 10.0.1.50 is inside the pool"},
+		// unknown values are left to the schema validation
+		{subnetAddresses{"", "10.0.1.1", "10.0.1.2", "10.0.1.254"}, ""},
+		{subnetAddresses{"10.0.1.0/24", "", "10.0.1.2", ""}, ""},
+	}
+	for _, c := range cases {
+		err := c.subnet.validate()
+		switch {
+		case c.expected == "" && err != nil:
+			t.Errorf("%v: unexpected error %s", c.subnet, err)
+		case c.expected != "" && (err == nil || !strings.Contains(err.Error(), c.expected)):
+			t.Errorf("%v: expected an error containing %q, got %v", c.subnet, c.expected, err)
+		}
+	}
+}
+
+func TestCidrsOverlap(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"10.0.1.0/24", "10.0.1.0/24", true},
+		{"10.0.0.0/16", "10.0.1.0/24", true},
+		{"10.0.1.0/24", "10.0.2.0/24", false},
+		{"10.0.1.0/24", "", false},
+	}
+	for _, c := range cases {
+		if got := cidrsOverlap(c.a, c.b); got != c.expected {
+			t.Errorf("%s and %s: expected %t, got %t", c.a, c.b, c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package bcc_terraform
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	cloudinitConfigContentType = "text/cloud-config"
+	cloudinitConfigHeader      = "#cloud-config"
+	cloudinitDefaultBoundary   = "MIMEBOUNDARY"
+)
+
+// cloudinitKnownKeys are the top-level keys of the cloud-config schema of
+// cloud-init, the keys of its modules and of the base config, a key which is
+// not among them is most likely a typo.
+var cloudinitKnownKeys = []string{
+	"allow_public_ssh_keys", "ansible", "apk_repos", "apt", "apt_pipelining", "apt_reboot_if_required",
+	"apt_update", "apt_upgrade", "autoinstall", "bootcmd", "byobu_by_default", "ca-certs", "ca_certs", "chef",
+	"chpasswd", "cloud_config_modules", "cloud_final_modules", "cloud_init_modules", "create_hostname_file",
+	"datasource", "device_aliases", "disable_ec2_metadata", "disable_root", "disable_root_opts", "disk_setup",
+	"drivers", "fan", "final_message", "fqdn", "fs_setup", "groups", "growpart", "grub-dpkg", "grub_dpkg",
+	"hostname", "keyboard", "landscape", "launch-index", "locale", "locale_configfile", "lxd",
+	"manage_etc_hosts", "manage_resolv_conf", "mcollective", "merge_how", "merge_type", "migrate",
+	"mount_default_fields", "mounts", "no_ssh_fingerprints", "ntp", "output", "package_reboot_if_required",
+	"package_update", "package_upgrade", "packages", "password", "phone_home", "power_state",
+	"prefer_fqdn_over_hostname", "preserve_hostname", "puppet", "random_seed", "reporting", "resize_rootfs",
+	"resolv_conf", "rh_subscription", "rsyslog", "runcmd", "salt_minion", "snap", "spacewalk", "ssh",
+	"ssh_authorized_keys", "ssh_deletekeys", "ssh_fp_console_blacklist", "ssh_genkeytypes", "ssh_import_id",
+	"ssh_key_console_blacklist", "ssh_keys", "ssh_publish_hostkeys", "ssh_pwauth", "ssh_quiet_keygen", "swap",
+	"system_info", "timezone", "ubuntu_advantage", "ubuntu_pro", "updates", "user", "users", "vendor_data",
+	"version", "wireguard", "write_files", "yum_repo_dir", "yum_repos", "zypper",
+}
+
+func (args *Arguments) injectContextDataCloudinitConfig() {
+	args.merge(Arguments{
+		"gzip": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "compress the result with gzip, needs base64_encode",
+		},
+		"base64_encode": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "encode the result with base64",
+		},
+		"multipart": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "render a multipart MIME message, by default only when there are several parts",
+		},
+		"boundary": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      cloudinitDefaultBoundary,
+			ValidateFunc: validation.StringIsNotWhiteSpace,
+			Description:  "the boundary of the multipart MIME message",
+		},
+		"part": {
+			Type:        schema.TypeList,
+			Required:    true,
+			MinItems:    1,
+			Description: "parts of the cloud-init config",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"content_type": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      cloudinitConfigContentType,
+						ValidateFunc: validation.StringIsNotWhiteSpace,
+						Description:  "MIME type of the part, e.g. `text/x-shellscript`",
+					},
+					"filename": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "file name of the part in the multipart message",
+					},
+					"merge_type": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "how cloud-init merges the part with the previous ones, e.g. `list(append)+dict(recurse_array)`",
+					},
+					"content": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "raw content of the part, the other settings of a cloud-config part are merged into it",
+					},
+					"allow_unknown_keys": {
+						Type:        schema.TypeBool,
+						Optional:    true,
+						Default:     false,
+						Description: "accept top-level keys of content which are no known cloud-config module, they are reported as warnings",
+					},
+					"users": {
+						Type:        schema.TypeList,
+						Optional:    true,
+						Description: "users created on the first boot",
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"name": {
+									Type:         schema.TypeString,
+									Required:     true,
+									ValidateFunc: validation.StringIsNotWhiteSpace,
+									Description:  "name of the user",
+								},
+								"groups": {
+									Type:        schema.TypeList,
+									Optional:    true,
+									Elem:        &schema.Schema{Type: schema.TypeString},
+									Description: "groups of the user",
+								},
+								"sudo": {
+									Type:        schema.TypeString,
+									Optional:    true,
+									Description: "sudo rule of the user, e.g. `ALL=(ALL) NOPASSWD:ALL`",
+								},
+								"shell": {
+									Type:        schema.TypeString,
+									Optional:    true,
+									Description: "login shell of the user",
+								},
+								"ssh_authorized_keys": {
+									Type:        schema.TypeList,
+									Optional:    true,
+									Elem:        &schema.Schema{Type: schema.TypeString},
+									Description: "public ssh keys of the user",
+								},
+								"pub_key_ids": {
+									Type:        schema.TypeList,
+									Optional:    true,
+									Elem:        &schema.Schema{Type: schema.TypeString},
+									Description: "ids of public keys of the account added to ssh_authorized_keys",
+								},
+							},
+						},
+					},
+					"write_files": {
+						Type:        schema.TypeList,
+						Optional:    true,
+						Description: "files written on the first boot",
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"path": {
+									Type:         schema.TypeString,
+									Required:     true,
+									ValidateFunc: validation.StringIsNotWhiteSpace,
+									Description:  "absolute path of the file",
+								},
+								"content": {
+									Type:        schema.TypeString,
+									Required:    true,
+									Description: "content of the file",
+								},
+								"permissions": {
+									Type:        schema.TypeString,
+									Optional:    true,
+									Description: "octal permissions of the file, e.g. `0644`",
+								},
+								"owner": {
+									Type:        schema.TypeString,
+									Optional:    true,
+									Description: "owner of the file, e.g. `root:root`",
+								},
+							},
+						},
+					},
+					"packages": {
+						Type:        schema.TypeList,
+						Optional:    true,
+						Elem:        &schema.Schema{Type: schema.TypeString},
+						Description: "packages installed on the first boot",
+					},
+					"runcmd": {
+						Type:        schema.TypeList,
+						Optional:    true,
+						Elem:        &schema.Schema{Type: schema.TypeString},
+						Description: "commands run on the first boot",
+					},
+				},
+			},
+		},
+		"rendered": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "the rendered cloud-init config, to be used as user_data of a Vm",
+		},
+	})
+}
+
+// cloudinitPart is a rendered part of the config.
+type cloudinitPart struct {
+	contentType string
+	filename    string
+	mergeType   string
+	content     string
+}
+
+// cloudinitUser is a `users` block of a part.
+type cloudinitUser struct {
+	name              string
+	groups            []string
+	sudo              string
+	shell             string
+	sshAuthorizedKeys []string
+}
+
+// cloudinitFile is a `write_files` block of a part.
+type cloudinitFile struct {
+	path        string
+	content     string
+	permissions string
+	owner       string
+}
+
+// renderCloudConfig merges the structured settings into the raw content of a
+// cloud-config part. The keys of the content come first, lists set by both
+// are appended. Content which is no valid cloud-config is an error, so are
+// unknown keys unless allowUnknownKeys is set, then they are returned as
+// warnings.
+func renderCloudConfig(content string, allowUnknownKeys bool, users []cloudinitUser, files []cloudinitFile, packages []string, runcmd []string) (string, []string, error) {
+	var document yaml.MapSlice
+	if strings.TrimSpace(content) != "" {
+		if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+			return "", nil, fmt.Errorf("content is no valid YAML: %s", err)
+		}
+	}
+
+	var warnings []string
+	for _, item := range document {
+		key, ok := item.Key.(string)
+		if !ok {
+			return "", nil, fmt.Errorf("content: key %v is not a string", item.Key)
+		}
+		if isCloudinitKnownKey(key) {
+			continue
+		}
+		if !allowUnknownKeys {
+			return "", nil, fmt.Errorf("content: unknown cloud-config key `%s`, set allow_unknown_keys to accept it", key)
+		}
+		warnings = append(warnings, fmt.Sprintf("content: unknown cloud-config key `%s`", key))
+	}
+
+	// Without settings to merge the content is kept as it is, comments included.
+	if len(users) == 0 && len(files) == 0 && len(packages) == 0 && len(runcmd) == 0 {
+		if !strings.HasPrefix(content, cloudinitConfigHeader) {
+			content = cloudinitConfigHeader + "\n" + content
+		}
+		return content, warnings, nil
+	}
+
+	if len(users) > 0 {
+		items := make([]interface{}, 0, len(users))
+		for _, user := range users {
+			entry := yaml.MapSlice{{Key: "name", Value: user.name}}
+			if len(user.groups) > 0 {
+				entry = append(entry, yaml.MapItem{Key: "groups", Value: strings.Join(user.groups, ", ")})
+			}
+			if user.sudo != "" {
+				entry = append(entry, yaml.MapItem{Key: "sudo", Value: user.sudo})
+			}
+			if user.shell != "" {
+				entry = append(entry, yaml.MapItem{Key: "shell", Value: user.shell})
+			}
+			if len(user.sshAuthorizedKeys) > 0 {
+				entry = append(entry, yaml.MapItem{Key: "ssh_authorized_keys", Value: user.sshAuthorizedKeys})
+			}
+			items = append(items, entry)
+		}
+		document = mergeCloudConfigList(document, "users", items)
+	}
+	if len(files) > 0 {
+		items := make([]interface{}, 0, len(files))
+		for _, file := range files {
+			entry := yaml.MapSlice{{Key: "path", Value: file.path}, {Key: "content", Value: file.content}}
+			if file.permissions != "" {
+				entry = append(entry, yaml.MapItem{Key: "permissions", Value: file.permissions})
+			}
+			if file.owner != "" {
+				entry = append(entry, yaml.MapItem{Key: "owner", Value: file.owner})
+			}
+			items = append(items, entry)
+		}
+		document = mergeCloudConfigList(document, "write_files", items)
+	}
+	if len(packages) > 0 {
+		document = mergeCloudConfigList(document, "packages", stringsToInterfaces(packages))
+	}
+	if len(runcmd) > 0 {
+		document = mergeCloudConfigList(document, "runcmd", stringsToInterfaces(runcmd))
+	}
+
+	rendered, err := yaml.Marshal(document)
+	if err != nil {
+		return "", nil, err
+	}
+	return cloudinitConfigHeader + "\n" + string(rendered), warnings, nil
+}
+
+func mergeCloudConfigList(document yaml.MapSlice, key string, items []interface{}) yaml.MapSlice {
+	for i, item := range document {
+		if item.Key != key {
+			continue
+		}
+		if current, ok := item.Value.([]interface{}); ok {
+			document[i].Value = append(current, items...)
+		} else {
+			document[i].Value = items
+		}
+		return document
+	}
+	return append(document, yaml.MapItem{Key: key, Value: items})
+}
+
+func isCloudinitKnownKey(key string) bool {
+	for _, item := range cloudinitKnownKeys {
+		if key == item {
+			return true
+		}
+	}
+	return false
+}
+
+func stringsToInterfaces(items []string) []interface{} {
+	result := make([]interface{}, len(items))
+	for i, item := range items {
+		result[i] = item
+	}
+	return result
+}
+
+// renderCloudinitConfig joins the parts into a multipart MIME message, or
+// returns the only part as it is.
+func renderCloudinitConfig(parts []cloudinitPart, multipartMessage bool, boundary string) (string, error) {
+	if !multipartMessage {
+		if len(parts) != 1 {
+			return "", fmt.Errorf("%d parts can only be rendered as a multipart message", len(parts))
+		}
+		return parts[0].content, nil
+	}
+
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return "", fmt.Errorf("boundary: %s", err)
+	}
+
+	fmt.Fprintf(&buffer, "Content-Type: multipart/mixed; boundary=\"%s\"\r\n", boundary)
+	fmt.Fprintf(&buffer, "MIME-Version: 1.0\r\n\r\n")
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", fmt.Sprintf("%s; charset=\"utf-8\"", part.contentType))
+		header.Set("MIME-Version", "1.0")
+		header.Set("Content-Transfer-Encoding", "7bit")
+		if part.filename != "" {
+			header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", part.filename))
+		}
+		if part.mergeType != "" {
+			header.Set("X-Merge-Type", part.mergeType)
+		}
+
+		body, err := writer.CreatePart(header)
+		if err != nil {
+			return "", err
+		}
+		if _, err = body.Write([]byte(part.content)); err != nil {
+			return "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+// encodeCloudinitConfig compresses and encodes the rendered config.
+func encodeCloudinitConfig(rendered string, gzipped bool, base64Encode bool) (string, error) {
+	if !gzipped && !base64Encode {
+		return rendered, nil
+	}
+	if gzipped && !base64Encode {
+		return "", fmt.Errorf("gzip needs base64_encode, Terraform strings can't hold binary data")
+	}
+
+	data := []byte(rendered)
+	if gzipped {
+		var buffer bytes.Buffer
+		writer := gzip.NewWriter(&buffer)
+		if _, err := writer.Write(data); err != nil {
+			return "", err
+		}
+		if err := writer.Close(); err != nil {
+			return "", err
+		}
+		data = buffer.Bytes()
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
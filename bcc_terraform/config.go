@@ -1,50 +1,106 @@
 package bcc_terraform
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/sirupsen/logrus"
 )
 
 type Config struct {
-	Token              string
-	CaCert             string
-	Cert               string
-	CertKey            string
-	Insecure           bool
-	APIEndpoint        string
-	APIRequestTimeout  time.Duration
-	APIRequestInterval time.Duration
-	TerraformVersion   string
-	ClientID           string
+	Token                 string
+	TokenFile             string
+	CredentialProcess     string
+	Profile               string
+	CredentialsFile       string
+	CaCert                string
+	CaCertFile            string
+	Cert                  string
+	CertFile              string
+	CertKey               string
+	CertKeyFile           string
+	Insecure              bool
+	APIEndpoint           string
+	APIRequestTimeout     time.Duration
+	APIRequestInterval    time.Duration
+	TerraformVersion      string
+	ClientID              string
+	Retry                 *RetryPolicy
+	DefaultTags           []string
+	ProjectID             string
+	VdcID                 string
+	MaxRequestsPerSecond  float64
+	MaxConcurrentRequests int
+	LookupCacheTTL        time.Duration
 }
 
 type CombinedConfig struct {
-	manager *bcc.Manager
+	manager     *bcc.Manager
+	defaultTags []string
+	projectID   string
+	vdcID       string
+	locks       *objectLocks
+	cache       *lookupCache
 }
 
 func (c *CombinedConfig) Manager() *bcc.Manager { return c.manager }
 
 func (c *Config) Client() (*CombinedConfig, diag.Diagnostics) {
-	logger := logrus.New()
-	logger.SetLevel(logrus.DebugLevel)
+	source, err := c.loadCredentials()
+	if err != nil {
+		return nil, diag.Errorf("Error in loading credentials: %s", err)
+	}
+	token := c.Token
+	if source != nil {
+		if token, err = source.Token(context.Background()); err != nil {
+			return nil, diag.Errorf("Error in loading credentials: %s", err)
+		}
+	}
 
-	manager, err := bcc.NewManager(c.Token, c.CaCert, c.Cert, c.CertKey, c.Insecure)
+	manager, err := bcc.NewManager(token, c.CaCert, c.Cert, c.CertKey, c.Insecure)
 	if err != nil {
 		return nil, diag.Errorf("Error in create Manager: %s", err)
 	}
-	manager.Logger = logger
 	manager.BaseURL = strings.TrimSuffix(c.APIEndpoint, "/")
 	manager.ClientID = c.ClientID
 	manager.RequestTimeout = c.APIRequestTimeout
 	manager.RequestInterval = c.APIRequestInterval
 	manager.UserAgent = fmt.Sprintf("Terraform/%s", c.TerraformVersion)
 
+	retry := c.Retry
+	if retry == nil {
+		retry = defaultRetryPolicy()
+	}
+	transport := manager.Client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if source != nil {
+		transport = &authTransport{next: transport, source: source}
+	}
+	limiter := newRequestLimiter(c.MaxRequestsPerSecond, c.MaxConcurrentRequests)
+	transport = &retryTransport{
+		next:   &limitTransport{next: &loggingTransport{next: transport}, limiter: limiter},
+		policy: retry,
+	}
+
+	var cache *lookupCache
+	if c.LookupCacheTTL > 0 {
+		cache = newLookupCache(c.LookupCacheTTL)
+		transport = &cacheTransport{next: transport, cache: cache}
+	}
+	manager.Client.Transport = transport
+
 	return &CombinedConfig{
-		manager: manager,
+		manager:     manager,
+		defaultTags: c.DefaultTags,
+		projectID:   c.ProjectID,
+		vdcID:       c.VdcID,
+		locks:       newObjectLocks(),
+		cache:       cache,
 	}, nil
 }
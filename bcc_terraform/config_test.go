@@ -0,0 +1,119 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+)
+
+func testManager(t *testing.T, fake *fakeBcc) *bcc.Manager {
+	t.Helper()
+	config := Config{
+		Token:              fakeToken,
+		APIEndpoint:        fake.URL() + "/",
+		APIRequestTimeout:  time.Minute,
+		APIRequestInterval: 100 * time.Millisecond,
+		TerraformVersion:   "test",
+	}
+	client, diags := config.Client()
+	if diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	return client.Manager()
+}
+
+func testCreateProject(t *testing.T, manager *bcc.Manager, name string) *bcc.Project {
+	t.Helper()
+	clients, err := manager.GetClients()
+	if err != nil || len(clients) != 1 {
+		t.Fatalf("expected a single client, got %d: %v", len(clients), err)
+	}
+	project := bcc.NewProject(name)
+	if err = clients[0].CreateProject(&project); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return &project
+}
+
+func TestConfig_client(t *testing.T) {
+	fake := newFakeBcc(t)
+	manager := testManager(t, fake)
+
+	account, err := manager.GetAccount()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if account.ID != fakeAccountID {
+		t.Fatalf("expected account %s, got %s", fakeAccountID, account.ID)
+	}
+}
+
+func TestConfig_invalidToken(t *testing.T) {
+	fake := newFakeBcc(t)
+	manager := testManager(t, fake)
+	manager.Token = "invalid"
+
+	_, err := manager.GetAccount()
+	if err == nil {
+		t.Fatal("expected an error for an invalid token")
+	}
+	if apiErr, ok := err.(*bcc.ApiError); !ok || apiErr.Code() != 401 {
+		t.Fatalf("expected 401, got %#v", err)
+	}
+}
+
+func TestConfig_waitLock(t *testing.T) {
+	fake := newFakeBcc(t)
+	manager := testManager(t, fake)
+
+	fake.SetLockPolls(2)
+	project := testCreateProject(t, manager, "locked")
+	if err := project.WaitLock(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// two polls answer locked, the third one releases the lock
+	if got := fake.Requests("GET", "v1/project/"+project.ID); got != 3 {
+		t.Fatalf("expected 3 polls, got %d", got)
+	}
+}
+
+func TestConfig_lockedWrite(t *testing.T) {
+	fake := newFakeBcc(t)
+	manager := testManager(t, fake)
+
+	fake.SetLockPolls(1)
+	project := testCreateProject(t, manager, "locked")
+	fake.SetLockPolls(0)
+
+	// bcc-go retries a write to a locked object until it is released
+	project.Name = "renamed"
+	if err := project.Update(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := fake.Requests("PUT", "v1/project/"+project.ID); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+}
+
+func TestConfig_pagination(t *testing.T) {
+	fake := newFakeBcc(t)
+	manager := testManager(t, fake)
+
+	for i := 0; i < 25; i++ {
+		testCreateProject(t, manager, fmt.Sprintf("project-%02d", i))
+	}
+
+	projects, err := manager.GetProjects()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(projects) != 25 {
+		t.Fatalf("expected 25 projects, got %d", len(projects))
+	}
+	if got := fake.Requests("GET", "v1/project"); got != 2 {
+		t.Fatalf("expected 2 pages, got %d", got)
+	}
+}
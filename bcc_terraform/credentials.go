@@ -0,0 +1,309 @@
+package bcc_terraform
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const defaultCredentialsProfile = "default"
+
+var errProfileNotFound = errors.New("profile not found")
+
+// tokenRefreshMargin is how long before its expiry a token is replaced, so a
+// request does not go out with a token which expires on the way.
+const tokenRefreshMargin = time.Minute
+
+// credentialsProfileKeys are the settings a profile of the credentials file
+// may hold, they match the arguments of the provider.
+var credentialsProfileKeys = []string{
+	"token", "token_file", "credential_process", "ca_cert_file", "cert_file", "cert_key_file",
+}
+
+func defaultCredentialsFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".basis", "credentials")
+}
+
+// loadCredentialsProfile reads a profile of the credentials file, an INI file
+// with one section per profile:
+//
+//	[default]
+//	token_file = ~/.basis/token
+//
+//	[staging]
+//	credential_process = vault read -field=token secret/basis
+func loadCredentialsProfile(path string, profile string) (map[string]string, error) {
+	content, err := os.ReadFile(expandHome(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var section string
+	values := map[string]string{}
+	found := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		}
+		if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+			section = strings.TrimSpace(text[1 : len(text)-1])
+			found = found || section == profile
+			continue
+		}
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected `key = value`", path, line)
+		}
+		if section != profile {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if !isCredentialsProfileKey(key) {
+			return nil, fmt.Errorf("%s:%d: unknown setting `%s`", path, line, key)
+		}
+		values[key] = strings.TrimSpace(value)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: `%s` in %s", errProfileNotFound, profile, path)
+	}
+
+	return values, nil
+}
+
+func isCredentialsProfileKey(key string) bool {
+	for _, item := range credentialsProfileKeys {
+		if key == item {
+			return true
+		}
+	}
+	return false
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// readCredentialFile returns the trimmed content of a token or certificate file.
+func readCredentialFile(path string) (string, error) {
+	content, err := os.ReadFile(expandHome(path))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// processCredentials is the output of a credential_process command.
+type processCredentials struct {
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// runCredentialProcess runs the command with the shell and parses the token it
+// prints. A token without `expires_at` never expires.
+func runCredentialProcess(ctx context.Context, command string) (string, time.Time, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("credential_process failed: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var credentials processCredentials
+	if err = json.Unmarshal(output, &credentials); err != nil {
+		return "", time.Time{}, fmt.Errorf("credential_process must print a JSON object with `token` and `expires_at`: %s", err)
+	}
+	if credentials.Token == "" {
+		return "", time.Time{}, fmt.Errorf("credential_process printed no `token`")
+	}
+
+	var expires time.Time
+	if credentials.ExpiresAt != "" {
+		if expires, err = time.Parse(time.RFC3339, credentials.ExpiresAt); err != nil {
+			return "", time.Time{}, fmt.Errorf("credential_process printed an invalid `expires_at`: %s", err)
+		}
+	}
+
+	return credentials.Token, expires, nil
+}
+
+// processTokenSource runs the credential_process whenever its token is about
+// to expire.
+type processTokenSource struct {
+	command string
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (s *processTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && (s.expires.IsZero() || time.Until(s.expires) > tokenRefreshMargin) {
+		return s.token, nil
+	}
+
+	token, expires, err := runCredentialProcess(ctx, s.command)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.expires = token, expires
+
+	fields := map[string]interface{}{}
+	if !expires.IsZero() {
+		fields["expires_at"] = expires.Format(time.RFC3339)
+	}
+	tflog.Debug(ctx, "Token received from credential_process", fields)
+	return token, nil
+}
+
+// expire drops the token, the API refused it before its expiry.
+func (s *processTokenSource) expire(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == token {
+		s.token = ""
+	}
+}
+
+// authTransport sends every request with the current token of the source.
+type authTransport struct {
+	next   http.RoundTripper
+	source *processTokenSource
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(withToken(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	// The token may have been revoked before its expiry, try once with a new one.
+	t.source.expire(token)
+	if token, err = t.source.Token(req.Context()); err != nil {
+		return resp, nil
+	}
+	retry := withToken(req, token)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	resp.Body.Close()
+	return t.next.RoundTrip(retry)
+}
+
+func withToken(req *http.Request, token string) *http.Request {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	return req
+}
+
+// loadCredentials completes the config from the credentials file and reads
+// the token and certificate files. Settings of the provider take precedence
+// over the profile. The returned source is set when the token comes from a
+// credential_process and has to be refreshed.
+func (c *Config) loadCredentials() (*processTokenSource, error) {
+	hasToken := c.Token != "" || c.TokenFile != "" || c.CredentialProcess != ""
+	if c.Profile != "" || !hasToken {
+		profile := c.Profile
+		if profile == "" {
+			profile = defaultCredentialsProfile
+		}
+		path := c.CredentialsFile
+		if path == "" {
+			path = defaultCredentialsFile()
+		}
+
+		values, err := loadCredentialsProfile(path, profile)
+		explicit := c.Profile != "" || c.CredentialsFile != ""
+		switch {
+		case err == nil:
+			if !hasToken {
+				c.Token = values["token"]
+				c.TokenFile = values["token_file"]
+				c.CredentialProcess = values["credential_process"]
+			}
+			if c.CaCert == "" && c.CaCertFile == "" {
+				c.CaCertFile = values["ca_cert_file"]
+			}
+			if c.Cert == "" && c.CertFile == "" {
+				c.CertFile = values["cert_file"]
+			}
+			if c.CertKey == "" && c.CertKeyFile == "" {
+				c.CertKeyFile = values["cert_key_file"]
+			}
+		case explicit || !(errors.Is(err, fs.ErrNotExist) || errors.Is(err, errProfileNotFound)):
+			return nil, err
+		}
+	}
+
+	files := []struct {
+		path  string
+		value *string
+	}{
+		{c.TokenFile, &c.Token},
+		{c.CaCertFile, &c.CaCert},
+		{c.CertFile, &c.Cert},
+		{c.CertKeyFile, &c.CertKey},
+	}
+	for _, file := range files {
+		if file.path == "" || *file.value != "" {
+			continue
+		}
+		content, err := readCredentialFile(file.path)
+		if err != nil {
+			return nil, err
+		}
+		*file.value = content
+	}
+
+	if c.Token == "" && c.CredentialProcess != "" {
+		return &processTokenSource{command: c.CredentialProcess}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,151 @@
+package bcc_terraform
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testWriteFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const testCredentialsFile = `
+# comments are skipped
+[default]
+token = default-token
+
+[staging]
+token_file = %s
+cert_file  = %s
+`
+
+func TestLoadCredentialsProfile(t *testing.T) {
+	path := testWriteFile(t, "credentials", fmt.Sprintf(testCredentialsFile, "/token", "/cert"))
+
+	values, err := loadCredentialsProfile(path, "staging")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if values["token_file"] != "/token" || values["cert_file"] != "/cert" || values["token"] != "" {
+		t.Fatalf("unexpected profile %v", values)
+	}
+
+	if _, err = loadCredentialsProfile(path, "missing"); err == nil {
+		t.Fatal("expected an error for a missing profile")
+	}
+
+	path = testWriteFile(t, "invalid", "[default]\npassword = secret\n")
+	if _, err = loadCredentialsProfile(path, "default"); err == nil || !strings.Contains(err.Error(), "password") {
+		t.Fatalf("expected an error for an unknown setting, got %v", err)
+	}
+}
+
+func TestConfig_loadCredentials(t *testing.T) {
+	tokenPath := testWriteFile(t, "token", "file-token\n")
+	certPath := testWriteFile(t, "cert", "-----BEGIN CERTIFICATE-----\n")
+	credentialsPath := testWriteFile(t, "credentials", fmt.Sprintf(testCredentialsFile, tokenPath, certPath))
+
+	config := Config{Profile: "staging", CredentialsFile: credentialsPath}
+	if _, err := config.loadCredentials(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if config.Token != "file-token" || config.Cert != "-----BEGIN CERTIFICATE-----" {
+		t.Fatalf("expected the staging profile, got token %q and cert %q", config.Token, config.Cert)
+	}
+
+	// the default profile is used when the provider sets no token
+	config = Config{CredentialsFile: credentialsPath}
+	if _, err := config.loadCredentials(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if config.Token != "default-token" {
+		t.Fatalf("expected the default profile, got %q", config.Token)
+	}
+
+	// the provider settings take precedence over the profile
+	config = Config{Token: "inline", Profile: "staging", CredentialsFile: credentialsPath}
+	if _, err := config.loadCredentials(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if config.Token != "inline" {
+		t.Fatalf("expected the inline token, got %q", config.Token)
+	}
+
+	config = Config{Profile: "staging", CredentialsFile: filepath.Join(t.TempDir(), "missing")}
+	if _, err := config.loadCredentials(); err == nil {
+		t.Fatal("expected an error for a missing credentials file")
+	}
+}
+
+func TestProcessTokenSource_refresh(t *testing.T) {
+	counter := filepath.Join(t.TempDir(), "runs")
+	expires := time.Now().Add(30 * time.Second).UTC().Format(time.RFC3339)
+	command := fmt.Sprintf(`echo run >> %s; echo '{"token": "token-'$(wc -l < %s | tr -d ' ')'", "expires_at": "%s"}'`, counter, counter, expires)
+
+	source := &processTokenSource{command: command}
+	first, err := source.Token(context.Background())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	// the token expires within tokenRefreshMargin, so it is replaced right away
+	second, err := source.Token(context.Background())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if first != "token-1" || second != "token-2" {
+		t.Fatalf("expected the token to be refreshed, got %q and %q", first, second)
+	}
+
+	source = &processTokenSource{command: `echo '{"token": "static"}'`}
+	for i := 0; i < 2; i++ {
+		if token, err := source.Token(context.Background()); err != nil || token != "static" {
+			t.Fatalf("expected the token to be kept, got %q: %v", token, err)
+		}
+	}
+
+	source = &processTokenSource{command: `echo not json`}
+	if _, err = source.Token(context.Background()); err == nil {
+		t.Fatal("expected an error for invalid output")
+	}
+}
+
+// tokenCheckTransport accepts only the given token.
+type tokenCheckTransport struct {
+	token    string
+	requests int
+}
+
+func (t *tokenCheckTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	status := http.StatusOK
+	if req.Header.Get("Authorization") != "Bearer "+t.token {
+		status = http.StatusUnauthorized
+	}
+	return &http.Response{StatusCode: status, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+}
+
+func TestAuthTransport_revokedToken(t *testing.T) {
+	next := &tokenCheckTransport{token: "new"}
+	source := &processTokenSource{command: `echo '{"token": "new"}'`, token: "revoked"}
+	client := &http.Client{Transport: &authTransport{next: next, source: source}}
+
+	resp, err := client.Get("http://bcc/v1/account/me")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || next.requests != 2 {
+		t.Fatalf("expected a retry with a new token, got %d after %d requests", resp.StatusCode, next.requests)
+	}
+}
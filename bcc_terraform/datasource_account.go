@@ -32,7 +32,7 @@ func dataSourceAccount() *schema.Resource {
 }
 
 func dataSourceAccountRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	account, err := manager.GetAccount()
 
 	if err != nil {
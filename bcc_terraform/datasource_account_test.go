@@ -0,0 +1,36 @@
+package bcc_terraform
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceAccount_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(fake) + `
+data "basis_account" "test" {}
+
+data "basis_pub_key" "test" {
+  account_id = data.basis_account.test.id
+  name       = "terraform"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.basis_account.test", "id", fakeAccountID),
+					resource.TestCheckResourceAttrSet("data.basis_account.test", "username"),
+					resource.TestCheckResourceAttr("data.basis_pub_key.test", "id", fakePubKeyID),
+					resource.TestCheckResourceAttrSet("data.basis_pub_key.test", "fingerprint"),
+					resource.TestMatchResourceAttr("data.basis_pub_key.test", "public_key", regexp.MustCompile(`^ssh-ed25519 `)),
+				),
+			},
+		},
+	})
+}
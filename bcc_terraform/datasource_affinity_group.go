@@ -22,7 +22,10 @@ func dataSourceAffinityGroup() *schema.Resource {
 }
 
 func dataSourceAffinityGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	if err := setDefaultVdcId(d, meta); err != nil {
+		return diag.FromErr(err)
+	}
 	targetVdc, err := GetVdcById(d, manager)
 	if err != nil {
 		return diag.Errorf("[ERROR-055] crash via getting vdc by id: %s", err)
@@ -0,0 +1,44 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceAffinityGroup_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVdcConfig(fake) + `
+resource "basis_affinity_group" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-group"
+  policy = "soft-affinity"
+}
+
+data "basis_affinity_group" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-group"
+
+  depends_on = [basis_affinity_group.test]
+}
+
+data "basis_affinity_groups" "test" {
+  vdc_id = basis_vdc.test.id
+
+  depends_on = [basis_affinity_group.test]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.basis_affinity_group.test", "id", "basis_affinity_group.test", "id"),
+					resource.TestCheckResourceAttr("data.basis_affinity_groups.test", "affinity_groups.#", "1"),
+				),
+			},
+		},
+	})
+}
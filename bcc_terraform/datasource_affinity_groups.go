@@ -21,7 +21,10 @@ func dataSourceAffinityGroups() *schema.Resource {
 }
 
 func dataSourceAffinityGroupsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	if err := setDefaultVdcId(d, meta); err != nil {
+		return diag.FromErr(err)
+	}
 	targetVdc, err := GetVdcById(d, manager)
 	if err != nil {
 		return diag.Errorf("error getting target vdc: %s", err)
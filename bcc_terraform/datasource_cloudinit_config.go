@@ -0,0 +1,150 @@
+package bcc_terraform
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/mitchellh/hashstructure/v2"
+)
+
+func dataSourceCloudinitConfig() *schema.Resource {
+	args := Defaults()
+	args.injectContextDataCloudinitConfig()
+
+	return &schema.Resource{
+		ReadContext: dataSourceCloudinitConfigRead,
+		Schema:      args,
+	}
+}
+
+func dataSourceCloudinitConfigRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	partsData := d.Get("part").([]interface{})
+	parts := make([]cloudinitPart, len(partsData))
+	for i, item := range partsData {
+		args := item.(map[string]interface{})
+		part := cloudinitPart{
+			contentType: args["content_type"].(string),
+			filename:    args["filename"].(string),
+			mergeType:   args["merge_type"].(string),
+			content:     args["content"].(string),
+		}
+
+		users, err := expandCloudinitUsers(ctx, meta, args["users"].([]interface{}))
+		if err != nil {
+			return diag.Errorf("[ERROR-059] part.%d.users: %s", i, err)
+		}
+		files := expandCloudinitFiles(args["write_files"].([]interface{}))
+		packages := interfacesToStrings(args["packages"].([]interface{}))
+		runcmd := interfacesToStrings(args["runcmd"].([]interface{}))
+
+		if part.contentType != cloudinitConfigContentType {
+			if len(users) > 0 || len(files) > 0 || len(packages) > 0 || len(runcmd) > 0 {
+				return diag.Errorf("[ERROR-059] part.%d: users, write_files, packages and runcmd need the content_type %s", i, cloudinitConfigContentType)
+			}
+			parts[i] = part
+			continue
+		}
+
+		content, warnings, err := renderCloudConfig(part.content, args["allow_unknown_keys"].(bool), users, files, packages, runcmd)
+		if err != nil {
+			return diag.Errorf("[ERROR-059] part.%d: %s", i, err)
+		}
+		for _, warning := range warnings {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("part.%d: %s", i, warning),
+			})
+		}
+		part.content = content
+		parts[i] = part
+	}
+
+	multipartMessage := len(parts) > 1
+	if !d.GetRawConfig().GetAttr("multipart").IsNull() {
+		multipartMessage = d.Get("multipart").(bool)
+	}
+
+	rendered, err := renderCloudinitConfig(parts, multipartMessage, d.Get("boundary").(string))
+	if err != nil {
+		return append(diags, diag.Errorf("[ERROR-059]: %s", err)...)
+	}
+	rendered, err = encodeCloudinitConfig(rendered, d.Get("gzip").(bool), d.Get("base64_encode").(bool))
+	if err != nil {
+		return append(diags, diag.Errorf("[ERROR-059]: %s", err)...)
+	}
+
+	hash, err := hashstructure.Hash(rendered, hashstructure.FormatV2, nil)
+	if err != nil {
+		return append(diags, diag.Errorf("[ERROR-059] crash via compute hash: %s", err)...)
+	}
+
+	fields := map[string]interface{}{
+		"id":        fmt.Sprintf("cloudinit/%d", hash),
+		"multipart": multipartMessage,
+		"rendered":  rendered,
+	}
+
+	if err := setResourceDataFromMap(d, fields); err != nil {
+		return append(diags, diag.Errorf("[ERROR-059] crash via set attrs: %s", err)...)
+	}
+
+	return diags
+}
+
+// expandCloudinitUsers reads the `users` blocks, the keys of pub_key_ids are
+// requested from the account.
+func expandCloudinitUsers(ctx context.Context, meta interface{}, items []interface{}) ([]cloudinitUser, error) {
+	users := make([]cloudinitUser, len(items))
+	for i, item := range items {
+		args := item.(map[string]interface{})
+		users[i] = cloudinitUser{
+			name:              args["name"].(string),
+			groups:            interfacesToStrings(args["groups"].([]interface{})),
+			sudo:              args["sudo"].(string),
+			shell:             args["shell"].(string),
+			sshAuthorizedKeys: interfacesToStrings(args["ssh_authorized_keys"].([]interface{})),
+		}
+
+		pubKeyIds := interfacesToStrings(args["pub_key_ids"].([]interface{}))
+		if len(pubKeyIds) == 0 {
+			continue
+		}
+		manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+		for _, pubKeyId := range pubKeyIds {
+			publicKey, err := manager.GetPublicKey(pubKeyId)
+			if err != nil {
+				return nil, fmt.Errorf("crash via getting PublicKey by id=%s: %s", pubKeyId, err)
+			}
+			users[i].sshAuthorizedKeys = append(users[i].sshAuthorizedKeys, publicKey.PublicKey)
+		}
+	}
+	return users, nil
+}
+
+func expandCloudinitFiles(items []interface{}) []cloudinitFile {
+	files := make([]cloudinitFile, len(items))
+	for i, item := range items {
+		args := item.(map[string]interface{})
+		files[i] = cloudinitFile{
+			path:        args["path"].(string),
+			content:     args["content"].(string),
+			permissions: args["permissions"].(string),
+			owner:       args["owner"].(string),
+		}
+	}
+	return files
+}
+
+func interfacesToStrings(items []interface{}) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			result = append(result, item.(string))
+		}
+	}
+	return result
+}
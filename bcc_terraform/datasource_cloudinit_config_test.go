@@ -0,0 +1,148 @@
+package bcc_terraform
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceCloudinitConfig_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(fake) + `
+data "basis_cloudinit_config" "test" {
+  part {
+    content = "timezone: UTC\n"
+
+    users {
+      name        = "ops"
+      groups      = ["sudo"]
+      pub_key_ids = ["` + fakePubKeyID + `"]
+    }
+
+    write_files {
+      path        = "/etc/motd"
+      content     = "hello\n"
+      permissions = "0644"
+    }
+
+    packages = ["nginx"]
+    runcmd   = ["systemctl enable --now nginx"]
+  }
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.basis_cloudinit_config.test", "multipart", "false"),
+					resource.TestMatchResourceAttr("data.basis_cloudinit_config.test", "rendered", regexp.MustCompile(`^#cloud-config\ntimezone: UTC\n`)),
+					resource.TestMatchResourceAttr("data.basis_cloudinit_config.test", "rendered", regexp.MustCompile(`ssh_authorized_keys:\n  - ssh-ed25519 `)),
+					resource.TestMatchResourceAttr("data.basis_cloudinit_config.test", "rendered", regexp.MustCompile(`packages:\n- nginx\n`)),
+				),
+			},
+			{
+				Config: testAccProviderConfig(fake) + `
+data "basis_cloudinit_config" "test" {
+  part {
+    packages = ["nginx"]
+  }
+
+  part {
+    content_type = "text/x-shellscript"
+    filename     = "setup.sh"
+    content      = "#!/bin/sh\necho ready\n"
+  }
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.basis_cloudinit_config.test", "multipart", "true"),
+					resource.TestMatchResourceAttr("data.basis_cloudinit_config.test", "rendered", regexp.MustCompile(`Content-Type: multipart/mixed; boundary="MIMEBOUNDARY"`)),
+					resource.TestMatchResourceAttr("data.basis_cloudinit_config.test", "rendered", regexp.MustCompile(`filename="setup.sh"`)),
+				),
+			},
+			{
+				Config: testAccProviderConfig(fake) + `
+data "basis_cloudinit_config" "test" {
+  gzip          = true
+  base64_encode = true
+
+  part {
+    runcmd = ["echo ready"]
+  }
+}
+`,
+				Check: resource.TestMatchResourceAttr("data.basis_cloudinit_config.test", "rendered", regexp.MustCompile(`^H4sI`)),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceCloudinitConfig_invalidYaml(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(fake) + `
+data "basis_cloudinit_config" "test" {
+  part {
+    content = "packages: [nginx\n"
+  }
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`part.0: content is no valid YAML`),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceCloudinitConfig_unknownKey(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(fake) + `
+data "basis_cloudinit_config" "test" {
+  part {
+    content = "packges: [nginx]\n"
+  }
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("part.0: content: unknown cloud-config key `packges`"),
+			},
+			{
+				// every module of cloud-init is known
+				Config: testAccProviderConfig(fake) + `
+data "basis_cloudinit_config" "test" {
+  part {
+    content = "keyboard:\n  layout: us\nssh_import_id: [gh:ops]\nwireguard:\n  interfaces: []\n"
+  }
+}
+`,
+				Check: resource.TestMatchResourceAttr("data.basis_cloudinit_config.test", "rendered", regexp.MustCompile(`^#cloud-config\nkeyboard:\n`)),
+			},
+			{
+				Config: testAccProviderConfig(fake) + `
+data "basis_cloudinit_config" "test" {
+  part {
+    content            = "vendor_setting: true\n"
+    allow_unknown_keys = true
+  }
+}
+`,
+				Check: resource.TestMatchResourceAttr("data.basis_cloudinit_config.test", "rendered", regexp.MustCompile(`^#cloud-config\nvendor_setting: true\n`)),
+			},
+		},
+	})
+}
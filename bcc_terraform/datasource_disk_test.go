@@ -0,0 +1,37 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceDisk_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDiskConfig(fake, "tf-acc-disk", 10) + `
+data "basis_disk" "test" {
+  vdc_id = basis_vdc.test.id
+  id     = basis_disk.test.id
+}
+
+data "basis_disks" "test" {
+  vdc_id = basis_vdc.test.id
+
+  depends_on = [basis_disk.test]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.basis_disk.test", "name", "tf-acc-disk"),
+					resource.TestCheckResourceAttr("data.basis_disk.test", "size", "10"),
+					resource.TestCheckResourceAttr("data.basis_disks.test", "disks.#", "1"),
+				),
+			},
+		},
+	})
+}
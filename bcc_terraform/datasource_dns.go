@@ -22,7 +22,10 @@ func dataSourceDns() *schema.Resource {
 }
 
 func dataSourceDnsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	if err := setDefaultProjectId(d, meta); err != nil {
+		return diag.FromErr(err)
+	}
 
 	target, err := checkDatasourceNameOrId(d)
 	if err != nil {
@@ -0,0 +1,47 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceDns_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(fake) + `
+resource "basis_project" "test" {
+  name = "tf-acc-project"
+}
+
+resource "basis_dns" "test" {
+  project_id = basis_project.test.id
+  name       = "example.com."
+}
+
+data "basis_dns" "test" {
+  project_id = basis_project.test.id
+  name       = "example.com."
+
+  depends_on = [basis_dns.test]
+}
+
+data "basis_dnss" "test" {
+  project_id = basis_project.test.id
+
+  depends_on = [basis_dns.test]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.basis_dns.test", "id", "basis_dns.test", "id"),
+					resource.TestCheckResourceAttr("data.basis_dnss.test", "dnss.#", "1"),
+				),
+			},
+		},
+	})
+}
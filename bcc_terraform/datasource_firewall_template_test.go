@@ -0,0 +1,44 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceFirewallTemplate_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVdcConfig(fake) + `
+resource "basis_firewall_template" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-firewall"
+}
+
+data "basis_firewall_template" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-firewall"
+
+  depends_on = [basis_firewall_template.test]
+}
+
+data "basis_firewall_templates" "test" {
+  vdc_id = basis_vdc.test.id
+
+  depends_on = [basis_firewall_template.test]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.basis_firewall_template.test", "id", "basis_firewall_template.test", "id"),
+					// the shared templates are listed as well
+					resource.TestCheckResourceAttr("data.basis_firewall_templates.test", "firewall_templates.#", "2"),
+				),
+			},
+		},
+	})
+}
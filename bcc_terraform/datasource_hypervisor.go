@@ -22,7 +22,10 @@ func dataSourceHypervisor() *schema.Resource {
 }
 
 func dataSourceHypervisorRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	if err := setDefaultProjectId(d, meta); err != nil {
+		return diag.FromErr(err)
+	}
 	project, err := GetProjectById(d, manager)
 	if err != nil {
 		return diag.Errorf("[ERROR-004] crash via getting project: %s", err)
@@ -0,0 +1,39 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceHypervisor_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(fake) + `
+resource "basis_project" "test" {
+  name = "tf-acc-project"
+}
+
+data "basis_hypervisor" "test" {
+  project_id = basis_project.test.id
+  name       = "VMware"
+}
+
+data "basis_hypervisors" "test" {
+  project_id = basis_project.test.id
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.basis_hypervisor.test", "id", fakeVmwareHypervisorID),
+					resource.TestCheckResourceAttr("data.basis_hypervisor.test", "type", "Vmware"),
+					resource.TestCheckResourceAttr("data.basis_hypervisors.test", "hypervisors.#", "2"),
+				),
+			},
+		},
+	})
+}
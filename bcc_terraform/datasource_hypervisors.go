@@ -21,7 +21,10 @@ func dataSourceHypervisors() *schema.Resource {
 }
 
 func dataSourceHypervisorsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	if err := setDefaultProjectId(d, meta); err != nil {
+		return diag.FromErr(err)
+	}
 	project, err := GetProjectById(d, manager)
 	if err != nil {
 		return diag.Errorf("[ERROR-005] crash via getting project: %s", err)
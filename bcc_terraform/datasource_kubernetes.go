@@ -23,7 +23,10 @@ func dataSourceKubernetes() *schema.Resource {
 }
 
 func dataSourceKubernetesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	if err := setDefaultVdcId(d, meta); err != nil {
+		return diag.FromErr(err)
+	}
 
 	target, err := checkDatasourceNameOrId(d)
 	if err != nil {
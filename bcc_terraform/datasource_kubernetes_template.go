@@ -22,11 +22,17 @@ func dataSourceKubernetesTemplate() *schema.Resource {
 }
 
 func dataSourceKubernetesTemplateRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	if err := setDefaultVdcId(d, meta); err != nil {
+		return diag.FromErr(err)
+	}
 
-	target, err := checkDatasourceNameOrId(d)
-	if err != nil {
-		return diag.Errorf("[ERROR-036] crash via  getting KubernetesTemplate: %s", err)
+	var err error
+	target := "name"
+	if _, ok := d.GetOk("name_regex"); !ok {
+		if target, err = checkDatasourceNameOrId(d); err != nil {
+			return diag.Errorf("[ERROR-036] crash via  getting KubernetesTemplate: %s", err)
+		}
 	}
 
 	var k8sTemplate *bcc.KubernetesTemplate
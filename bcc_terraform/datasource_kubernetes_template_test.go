@@ -0,0 +1,35 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceKubernetesTemplate_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVdcConfig(fake) + `
+data "basis_kubernetes_template" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "Kubernetes 1.27"
+}
+
+data "basis_kubernetes_templates" "test" {
+  vdc_id = basis_vdc.test.id
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.basis_kubernetes_template.test", "id", fakeKubernetesTemplateID),
+					resource.TestCheckResourceAttr("data.basis_kubernetes_template.test", "min_node_cpu", "2"),
+					resource.TestCheckResourceAttr("data.basis_kubernetes_templates.test", "kubernetes_templates.#", "1"),
+				),
+			},
+		},
+	})
+}
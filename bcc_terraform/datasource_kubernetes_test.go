@@ -0,0 +1,39 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceKubernetes_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccKubernetesConfig(fake, 1, false) + `
+data "basis_kubernetes" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-k8s"
+
+  depends_on = [basis_kubernetes.test]
+}
+
+data "basis_kubernetess" "test" {
+  vdc_id = basis_vdc.test.id
+
+  depends_on = [basis_kubernetes.test]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.basis_kubernetes.test", "id", "basis_kubernetes.test", "id"),
+					resource.TestCheckResourceAttr("data.basis_kubernetes.test", "vms.#", "1"),
+					resource.TestCheckResourceAttr("data.basis_kubernetess.test", "kubernetess.#", "1"),
+				),
+			},
+		},
+	})
+}
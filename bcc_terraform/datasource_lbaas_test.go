@@ -0,0 +1,39 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceLbaas_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLbaasConfig(fake, "tf-acc-lbaas", false) + `
+data "basis_lbaas" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-lbaas"
+
+  depends_on = [basis_lbaas.test]
+}
+
+data "basis_lbaass" "test" {
+  vdc_id = basis_vdc.test.id
+
+  depends_on = [basis_lbaas.test]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.basis_lbaas.test", "id", "basis_lbaas.test", "id"),
+					resource.TestCheckResourceAttr("data.basis_lbaas.test", "port.0.ip_address", "10.0.2.60"),
+					resource.TestCheckResourceAttr("data.basis_lbaass.test", "lbaass.#", "1"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,40 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceNetwork_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNetworkConfig(fake) + `
+data "basis_network" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-network"
+
+  depends_on = [basis_network.test]
+}
+
+data "basis_networks" "test" {
+  vdc_id = basis_vdc.test.id
+
+  depends_on = [basis_network.test]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.basis_network.test", "id", "basis_network.test", "id"),
+					resource.TestCheckResourceAttr("data.basis_network.test", "subnets.0.cidr", "10.0.2.0/24"),
+					// the default network of the vdc is listed as well
+					resource.TestCheckResourceAttr("data.basis_networks.test", "networks.#", "2"),
+				),
+			},
+		},
+	})
+}
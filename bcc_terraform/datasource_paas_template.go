@@ -2,6 +2,7 @@ package bcc_terraform
 
 import (
 	"context"
+	"strconv"
 	"strings"
 
 	"github.com/basis-cloud/bcc-go/bcc"
@@ -21,8 +22,9 @@ func dataSourcePaasTemplate() *schema.Resource {
 			},
 			"vdc_id": {
 				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Vdc identifier",
+				Optional:    true,
+				Computed:    true,
+				Description: "Vdc identifier, the provider `vdc_id` by default",
 			},
 			"name": {
 				Type:        schema.TypeString,
@@ -35,15 +37,17 @@ func dataSourcePaasTemplate() *schema.Resource {
 }
 
 func dataSourcePaasTemplateRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
-	manager = manager.WithContext(ctx)
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	if err := setDefaultVdcId(d, meta); err != nil {
+		return diag.FromErr(err)
+	}
 
 	vdc, err := GetVdcById(d, manager)
 	if err != nil {
 		return diag.Errorf("[ERROR-010] crash via getting vdc: %s", err)
 	}
 
-	err = ensureLocationCreated(vdc.ID, manager)
+	err = ensureLocationCreated(ctx, vdc.ID, manager)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -68,7 +72,7 @@ func dataSourcePaasTemplateRead(ctx context.Context, d *schema.ResourceData, met
 	}
 
 	fields := map[string]interface{}{
-		"id":     paasTmp.ID,
+		"id":     strconv.Itoa(paasTmp.ID),
 		"vdc_id": vdc.ID,
 		"name":   paasTmp.Name,
 	}
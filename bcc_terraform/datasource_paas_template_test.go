@@ -0,0 +1,27 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourcePaasTemplate_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVdcConfig(fake) + `
+data "basis_paas_template" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "PostgreSQL"
+}
+`,
+				Check: resource.TestCheckResourceAttr("data.basis_paas_template.test", "id", "1"),
+			},
+		},
+	})
+}
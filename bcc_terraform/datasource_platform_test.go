@@ -0,0 +1,34 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourcePlatform_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVdcConfig(fake) + `
+data "basis_platform" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "Intel Cascade Lake"
+}
+
+data "basis_platforms" "test" {
+  vdc_id = basis_vdc.test.id
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.basis_platform.test", "id", fakePlatformID),
+					resource.TestCheckResourceAttr("data.basis_platforms.test", "platforms.#", "1"),
+				),
+			},
+		},
+	})
+}
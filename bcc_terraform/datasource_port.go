@@ -21,7 +21,10 @@ func dataSourcePort() *schema.Resource {
 }
 
 func dataSourcePortRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	if err := setDefaultVdcId(d, meta); err != nil {
+		return diag.FromErr(err)
+	}
 
 	vdc, err := GetVdcById(d, manager)
 	if err != nil {
@@ -0,0 +1,42 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourcePort_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNetworkConfig(fake) + `
+resource "basis_port" "test" {
+  vdc_id     = basis_vdc.test.id
+  network_id = basis_network.test.id
+  ip_address = "10.0.2.50"
+}
+
+data "basis_port" "test" {
+  vdc_id = basis_vdc.test.id
+  id     = basis_port.test.id
+}
+
+data "basis_ports" "test" {
+  vdc_id = basis_vdc.test.id
+
+  depends_on = [basis_port.test]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.basis_port.test", "ip_address", "10.0.2.50"),
+					resource.TestCheckResourceAttrSet("data.basis_ports.test", "ports.#"),
+				),
+			},
+		},
+	})
+}
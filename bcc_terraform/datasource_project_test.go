@@ -0,0 +1,42 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceProject_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(fake) + `
+resource "basis_project" "test" {
+  name = "tf-acc-project"
+  tags = ["project"]
+}
+
+data "basis_project" "test" {
+  name = "tf-acc-project"
+
+  depends_on = [basis_project.test]
+}
+
+data "basis_projects" "test" {
+  depends_on = [basis_project.test]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.basis_project.test", "id", "basis_project.test", "id"),
+					resource.TestCheckResourceAttr("data.basis_project.test", "tags.#", "1"),
+					resource.TestCheckResourceAttr("data.basis_projects.test", "projects.#", "1"),
+					resource.TestCheckResourceAttr("data.basis_projects.test", "projects.0.name", "tf-acc-project"),
+				),
+			},
+		},
+	})
+}
@@ -21,7 +21,7 @@ func dataSourcePublicKey() *schema.Resource {
 }
 
 func dataSourcePublicKeyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	target, err := checkDatasourceNameOrId(d)
 	if err != nil {
@@ -45,8 +45,8 @@ func dataSourcePublicKeyRead(ctx context.Context, d *schema.ResourceData, meta i
 	flatten := map[string]interface{}{
 		"id":          publicKey.ID,
 		"name":        publicKey.Name,
-		"public_key":  publicKey.Fingerprint,
-		"fingerprint": publicKey.PublicKey,
+		"public_key":  publicKey.PublicKey,
+		"fingerprint": publicKey.Fingerprint,
 	}
 
 	if err := setResourceDataFromMap(d, flatten); err != nil {
@@ -0,0 +1,44 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceRouter_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRouterConfig(fake, `
+resource "basis_router" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-router"
+  ports  = [basis_port.router.id]
+}
+
+data "basis_router" "test" {
+  vdc_id = basis_vdc.test.id
+  id     = basis_router.test.id
+}
+
+data "basis_routers" "test" {
+  vdc_id = basis_vdc.test.id
+
+  depends_on = [basis_router.test]
+}
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.basis_router.test", "name", "tf-acc-router"),
+					resource.TestCheckResourceAttr("data.basis_router.test", "ports.#", "1"),
+					// the default router of the vdc is listed as well
+					resource.TestCheckResourceAttr("data.basis_routers.test", "routers.#", "2"),
+				),
+			},
+		},
+	})
+}
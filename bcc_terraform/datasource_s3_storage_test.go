@@ -0,0 +1,39 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceS3Storage_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccS3StorageConfig(fake, "tf-acc-s3") + `
+data "basis_s3_storage" "test" {
+  project_id = basis_project.test.id
+  name       = "tf-acc-s3"
+
+  depends_on = [basis_s3_storage.test]
+}
+
+data "basis_s3_storages" "test" {
+  project_id = basis_project.test.id
+
+  depends_on = [basis_s3_storage.test]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.basis_s3_storage.test", "id", "basis_s3_storage.test", "id"),
+					resource.TestCheckResourceAttrPair("data.basis_s3_storage.test", "access_key", "basis_s3_storage.test", "access_key"),
+					resource.TestCheckResourceAttr("data.basis_s3_storages.test", "s3_storages.#", "1"),
+				),
+			},
+		},
+	})
+}
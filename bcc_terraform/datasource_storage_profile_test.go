@@ -0,0 +1,35 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceStorageProfile_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVdcConfig(fake) + `
+data "basis_storage_profile" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "ssd"
+}
+
+data "basis_storage_profiles" "test" {
+  vdc_id = basis_vdc.test.id
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.basis_storage_profile.test", "id", fakeStorageProfileID),
+					resource.TestCheckResourceAttr("data.basis_storage_profile.test", "max_disk_size", "2048"),
+					resource.TestCheckResourceAttr("data.basis_storage_profiles.test", "storage_profiles.#", "2"),
+				),
+			},
+		},
+	})
+}
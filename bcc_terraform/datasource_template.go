@@ -21,16 +21,21 @@ func dataSourceTemplate() *schema.Resource {
 }
 
 func dataSourceTemplateRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	if err := setDefaultVdcId(d, meta); err != nil {
+		return diag.FromErr(err)
+	}
 
 	vdc, err := GetVdcById(d, manager)
 	if err != nil {
 		return diag.Errorf("[ERROR-017] crash via getting vdc: %s", err)
 	}
 
-	target, err := checkDatasourceNameOrId(d)
-	if err != nil {
-		return diag.Errorf("[ERROR-017] crash via chose target: %s", err)
+	target := "name"
+	if _, ok := d.GetOk("name_regex"); !ok {
+		if target, err = checkDatasourceNameOrId(d); err != nil {
+			return diag.Errorf("[ERROR-017] crash via chose target: %s", err)
+		}
 	}
 
 	var template *bcc.Template
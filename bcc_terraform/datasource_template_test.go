@@ -0,0 +1,69 @@
+package bcc_terraform
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceTemplate_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVdcConfig(fake) + `
+data "basis_template" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "Ubuntu 22.04"
+}
+
+data "basis_templates" "test" {
+  vdc_id = basis_vdc.test.id
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.basis_template.test", "id", fakeTemplateID),
+					resource.TestCheckResourceAttr("data.basis_template.test", "min_disk", "10"),
+					resource.TestCheckResourceAttr("data.basis_templates.test", "templates.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceTemplate_nameRegex(t *testing.T) {
+	fake := newFakeBcc(t)
+	fake.AddTemplate("Ubuntu 22.04 (2024-9)")
+	latestId := fake.AddTemplate("Ubuntu 22.04 (2024-10)")
+
+	config := testAccVdcConfig(fake) + `
+data "basis_template" "test" {
+  vdc_id     = basis_vdc.test.id
+  name_regex = "^Ubuntu 22\\.04 \\("
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config,
+				ExpectError: regexp.MustCompile(`2 Templates match name_regex`),
+			},
+			{
+				Config: strings.Replace(config, `name_regex = "^Ubuntu 22\\.04 \\("`, `name_regex  = "^Ubuntu 22\\.04 \\("
+  most_recent = true`, 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.basis_template.test", "id", latestId),
+					resource.TestCheckResourceAttr("data.basis_template.test", "name", "Ubuntu 22.04 (2024-10)"),
+				),
+			},
+		},
+	})
+}
@@ -22,7 +22,7 @@ func dataSourceVdc() *schema.Resource {
 }
 
 func dataSourceVdcRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	target, err := checkDatasourceNameOrId(d)
 	if err != nil {
@@ -0,0 +1,36 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceVdc_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVdcConfig(fake) + `
+data "basis_vdc" "test" {
+  id = basis_vdc.test.id
+}
+
+data "basis_vdcs" "test" {
+  project_id = basis_project.test.id
+
+  depends_on = [basis_vdc.test]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.basis_vdc.test", "name", "tf-acc-vdc"),
+					resource.TestCheckResourceAttrPair("data.basis_vdc.test", "default_network_id", "basis_vdc.test", "default_network_id"),
+					resource.TestCheckResourceAttr("data.basis_vdcs.test", "vdcs.#", "1"),
+				),
+			},
+		},
+	})
+}
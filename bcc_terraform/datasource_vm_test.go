@@ -0,0 +1,41 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceVm_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVmConfig(fake, "tf-acc-vm") + `
+data "basis_vm" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-vm"
+
+  depends_on = [basis_vm.test]
+}
+
+data "basis_vms" "test" {
+  vdc_id = basis_vdc.test.id
+
+  depends_on = [basis_vm.test]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.basis_vm.test", "id", "basis_vm.test", "id"),
+					resource.TestCheckResourceAttr("data.basis_vm.test", "cpu", "1"),
+					resource.TestCheckResourceAttr("data.basis_vm.test", "system_disk.0.size", "10"),
+					resource.TestCheckResourceAttr("data.basis_vm.test", "networks.#", "1"),
+					resource.TestCheckResourceAttr("data.basis_vms.test", "vms.#", "1"),
+				),
+			},
+		},
+	})
+}
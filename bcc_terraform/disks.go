@@ -49,7 +49,8 @@ func (args *Arguments) injectContextResourceDisk() {
 			Computed:    true,
 			Description: "external id of the volume. It can be empty",
 		},
-		"tags": newTagNamesResourceSchema("tags of the Vm"),
+		"tags":     newTagNamesResourceSchema("tags of the Disk"),
+		"tags_all": newTagNamesDataSchema("tags of the Disk including the provider default tags"),
 	})
 }
 
@@ -103,3 +104,22 @@ func (args *Arguments) injectContextDataDiskList() {
 		},
 	})
 }
+
+func (args *Arguments) injectContextResourceVmDiskAttachment() {
+	args.merge(Arguments{
+		"vm_id": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.StringIsNotEmpty,
+			Description:  "id of the Vm",
+		},
+		"disk_id": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.StringIsNotEmpty,
+			Description:  "id of the Disk attached to the Vm",
+		},
+	})
+}
@@ -48,7 +48,8 @@ func (args *Arguments) injectContextResourceDns() {
 			),
 			Description: "name of the Dns",
 		},
-		"tags": newTagNamesResourceSchema("tags of the Vm"),
+		"tags":     newTagNamesResourceSchema("tags of the Dns"),
+		"tags_all": newTagNamesDataSchema("tags of the Dns including the provider default tags"),
 	})
 }
 
@@ -0,0 +1,871 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+type fakeDisk struct {
+	fakeBase
+	Name           string
+	Size           int
+	StorageProfile string
+	Vdc            string
+	Vm             string
+	IsRoot         bool
+	Tags           []string
+}
+
+type fakeVm struct {
+	fakeBase
+	Name        string
+	Description string
+	Cpu         int
+	Ram         float64
+	Power       bool
+	HotAdd      bool
+	Vdc         string
+	Template    string
+	Platform    string
+	UserData    interface{}
+	Floating    string
+	Tags        []string
+}
+
+type fakeAffinityGroup struct {
+	fakeBase
+	Name        string
+	Description string
+	Policy      string
+	Vdc         string
+}
+
+type fakeKubernetes struct {
+	fakeBase
+	Name               string
+	Vdc                string
+	NodeCpu            int
+	NodeRam            int
+	NodeDiskSize       int
+	NodesCount         int
+	NodeStorageProfile string
+	NodePlatform       string
+	Template           string
+	UserPublicKey      string
+	Floating           string
+	Tags               []string
+
+	// Nodes are ids of the node vms, they are not served by the vm endpoints.
+	Nodes []string
+}
+
+type fakeS3Storage struct {
+	fakeBase
+	Name    string
+	Project string
+	Backend string
+	Tags    []string
+}
+
+type fakeS3Bucket struct {
+	fakeBase
+	S3   string
+	Name string
+}
+
+type fakePaasService struct {
+	fakeBase
+	Name     string
+	Vdc      string
+	Template int
+	Inputs   interface{}
+}
+
+func (f *fakeBcc) diskJSON(disk *fakeDisk) map[string]interface{} {
+	var vm interface{}
+	if v, ok := f.vms[disk.Vm]; ok {
+		vm = map[string]interface{}{"id": v.ID, "name": v.Name}
+	}
+	return map[string]interface{}{
+		"id":              disk.ID,
+		"name":            disk.Name,
+		"scsi":            "0:" + strconv.Itoa(disk.seq%16),
+		"external_id":     "disk-" + strconv.Itoa(disk.seq),
+		"is_root":         disk.IsRoot,
+		"size":            disk.Size,
+		"vm":              vm,
+		"storage_profile": f.storageProfileJSON(disk.StorageProfile),
+		"locked":          false,
+		"tags":            fakeTags(disk.Tags),
+		"vdc":             f.vdcRef(disk.Vdc),
+	}
+}
+
+func (f *fakeBcc) applyDisk(disk *fakeDisk, req *fakeRequest) (int, interface{}) {
+	profile := f.storageProfile(req.str("storage_profile"))
+	if profile == nil {
+		return fakeBadRequest("storage_profile: unknown storage profile %q", req.str("storage_profile"))
+	}
+	if req.int("size") < 1 || req.int("size") > profile.MaxDiskSize {
+		return fakeBadRequest("size: must be between 1 and %d", profile.MaxDiskSize)
+	}
+	if disk.Size > req.int("size") {
+		return fakeBadRequest("size: disk can not be shrunk")
+	}
+	disk.Name = req.str("name")
+	disk.Size = req.int("size")
+	disk.StorageProfile = profile.ID
+	if req.has("tags") {
+		disk.Tags = req.strings("tags")
+	}
+	return 0, nil
+}
+
+func (f *fakeBcc) serveDisk(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{}
+		for _, disk := range fakeSorted(f.disks) {
+			if vdc := req.query.Get("vdc"); vdc != "" && vdc != disk.Vdc {
+				continue
+			}
+			if fakeNameMatches(req.query, disk.Name) {
+				items = append(items, f.diskJSON(disk))
+			}
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 1):
+		disk := &fakeDisk{fakeBase: f.newBase(), Vdc: req.str("vdc")}
+		if vm, ok := f.vms[req.str("vm")]; ok {
+			disk.Vdc = vm.Vdc
+			disk.Vm = vm.ID
+		}
+		if _, ok := f.vdcs[disk.Vdc]; !ok {
+			return fakeBadRequest("vdc: unknown vdc %q", disk.Vdc)
+		}
+		if status, resp := f.applyDisk(disk, req); resp != nil {
+			return status, resp
+		}
+		f.disks[disk.ID] = disk
+		f.touch(disk.ID)
+		return fakeCreated(f.diskJSON(disk))
+	}
+
+	disk, ok := f.disks[req.parts[1]]
+	if !ok {
+		return fakeNotFound()
+	}
+	switch {
+	case req.is(http.MethodGet, 2):
+		return f.withLock(disk.ID, f.diskJSON(disk))
+	case req.is(http.MethodPut, 2):
+		if status, resp := f.applyDisk(disk, req); resp != nil {
+			return status, resp
+		}
+		f.touch(disk.ID)
+		return fakeOK(f.diskJSON(disk))
+	case req.is(http.MethodDelete, 2):
+		if disk.Vm != "" {
+			return fakeBadRequest("disk is attached to vm %s", disk.Vm)
+		}
+		delete(f.disks, disk.ID)
+		return fakeNoContent()
+	case req.is(http.MethodPost, 3) && req.parts[2] == "attach":
+		vm, ok := f.vms[req.str("vm")]
+		if !ok {
+			return fakeBadRequest("vm: unknown vm %q", req.str("vm"))
+		}
+		if disk.Vm != "" {
+			return fakeBadRequest("disk is already attached to vm %s", disk.Vm)
+		}
+		if vm.Vdc != disk.Vdc {
+			return fakeBadRequest("vm and disk are in different vdcs")
+		}
+		disk.Vm = vm.ID
+		f.touch(disk.ID)
+		f.touch(vm.ID)
+		return fakeOK(f.diskJSON(disk))
+	case req.is(http.MethodPost, 3) && req.parts[2] == "detach":
+		if disk.IsRoot {
+			return fakeBadRequest("system disk can not be detached")
+		}
+		if disk.Vm != "" {
+			f.touch(disk.Vm)
+		}
+		disk.Vm = ""
+		f.touch(disk.ID)
+		return fakeOK(f.diskJSON(disk))
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) vmDisks(vm *fakeVm) []*fakeDisk {
+	root := []*fakeDisk{}
+	disks := []*fakeDisk{}
+	for _, disk := range fakeSorted(f.disks) {
+		if disk.Vm != vm.ID {
+			continue
+		}
+		if disk.IsRoot {
+			root = append(root, disk)
+		} else {
+			disks = append(disks, disk)
+		}
+	}
+	return append(root, disks...)
+}
+
+func (f *fakeBcc) vmJSON(vm *fakeVm) map[string]interface{} {
+	ports := []interface{}{}
+	for _, port := range f.devicePorts(vm.ID) {
+		ports = append(ports, f.portJSON(port))
+	}
+	disks := []interface{}{}
+	for _, disk := range f.vmDisks(vm) {
+		disks = append(disks, f.diskJSON(disk))
+	}
+	return map[string]interface{}{
+		"id":              vm.ID,
+		"name":            vm.Name,
+		"description":     vm.Description,
+		"cpu":             vm.Cpu,
+		"ram":             vm.Ram,
+		"power":           vm.Power,
+		"vdc":             f.vdcRef(vm.Vdc),
+		"hotadd_feature":  vm.HotAdd,
+		"template":        f.templateJSON(vm.Template),
+		"metadata":        []interface{}{},
+		"user_data":       vm.UserData,
+		"ports":           ports,
+		"disks":           disks,
+		"floating":        f.floatingJSON(vm.Floating),
+		"locked":          false,
+		"tags":            fakeTags(vm.Tags),
+		"platform":        f.platformJSON(vm.Platform),
+		"affinity_groups": []interface{}{},
+		"kubernetes":      nil,
+	}
+}
+
+func (f *fakeBcc) serveVm(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{}
+		for _, vm := range fakeSorted(f.vms) {
+			if vdc := req.query.Get("vdc"); vdc != "" && vdc != vm.Vdc {
+				continue
+			}
+			if fakeNameMatches(req.query, vm.Name) {
+				items = append(items, f.vmJSON(vm))
+			}
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 1):
+		return f.createVm(req)
+	}
+
+	vm, ok := f.vms[req.parts[1]]
+	if !ok {
+		return fakeNotFound()
+	}
+	switch {
+	case req.is(http.MethodGet, 2):
+		return f.withLock(vm.ID, f.vmJSON(vm))
+	case req.is(http.MethodPut, 2):
+		if req.int("cpu") < 1 || req.float("ram") <= 0 {
+			return fakeBadRequest("cpu and ram must be positive")
+		}
+		vm.Name = req.str("name")
+		vm.Description = req.str("description")
+		vm.Cpu = req.int("cpu")
+		vm.Ram = req.float("ram")
+		vm.HotAdd = req.bool("hotadd_feature")
+		vm.Tags = req.strings("tags")
+		vm.Floating = f.setFloating(vm.Floating, req)
+		f.touch(vm.ID)
+		return fakeOK(f.vmJSON(vm))
+	case req.is(http.MethodPost, 3) && req.parts[2] == "state":
+		switch req.str("state") {
+		case "power_on", "reboot":
+			vm.Power = true
+		case "power_off":
+			vm.Power = false
+		case "shutdown":
+			vm.Power = vm.Power && f.ignoreShutdown
+		default:
+			return fakeBadRequest("state: %q is not a valid choice", req.str("state"))
+		}
+		f.vmStates[vm.ID] = append(f.vmStates[vm.ID], req.str("state"))
+		f.touch(vm.ID)
+		// The state change runs as a task, the API answers without a body.
+		return fakeOK(nil)
+	case req.is(http.MethodDelete, 2):
+		f.deleteVm(vm)
+		return fakeNoContent()
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) createVm(req *fakeRequest) (int, interface{}) {
+	vdc, ok := f.vdcs[req.str("vdc")]
+	if !ok {
+		return fakeBadRequest("vdc: unknown vdc %q", req.str("vdc"))
+	}
+	template := f.template(req.str("template"))
+	if template == nil {
+		return fakeBadRequest("template: unknown template %q", req.str("template"))
+	}
+	if req.int("cpu") < template.MinCpu || req.float("ram") < template.MinRam {
+		return fakeBadRequest("cpu and ram must not be less than the template minimum")
+	}
+	disks, _ := req.body["disks"].([]interface{})
+	if len(disks) == 0 {
+		return fakeBadRequest("disks: system disk is required")
+	}
+	for _, id := range req.ids("ports") {
+		port, ok := f.ports[id]
+		if !ok {
+			return fakeBadRequest("ports: unknown port %q", id)
+		}
+		if port.Device != "" {
+			return fakeBadRequest("ports: port %s is already connected", id)
+		}
+	}
+
+	vm := &fakeVm{
+		fakeBase:    f.newBase(),
+		Name:        req.str("name"),
+		Description: req.str("description"),
+		Cpu:         req.int("cpu"),
+		Ram:         req.float("ram"),
+		Power:       true,
+		Vdc:         vdc.ID,
+		Template:    template.ID,
+		Platform:    f.defaultPlatform(vdc.ID),
+		UserData:    req.body["user_data"],
+		Tags:        req.strings("tags"),
+	}
+	for i, raw := range disks {
+		diskReq := &fakeRequest{}
+		diskReq.body, _ = raw.(map[string]interface{})
+		disk := &fakeDisk{fakeBase: f.newBase(), Vdc: vdc.ID, Vm: vm.ID, IsRoot: i == 0}
+		if status, resp := f.applyDisk(disk, diskReq); resp != nil {
+			return status, resp
+		}
+		if disk.IsRoot && disk.Size < template.MinHdd {
+			return fakeBadRequest("disks: system disk must be at least %d GB", template.MinHdd)
+		}
+		f.disks[disk.ID] = disk
+	}
+	for _, id := range req.ids("ports") {
+		f.connectPort(f.ports[id], vm.ID, "vm_int")
+	}
+	vm.Floating = f.setFloating("", req)
+	f.vms[vm.ID] = vm
+	f.touch(vm.ID)
+	return fakeCreated(f.vmJSON(vm))
+}
+
+// deleteVm removes the vm with its system disk, other disks and ports are
+// released.
+func (f *fakeBcc) deleteVm(vm *fakeVm) {
+	for _, disk := range f.vmDisks(vm) {
+		if disk.IsRoot {
+			delete(f.disks, disk.ID)
+		} else {
+			disk.Vm = ""
+		}
+	}
+	for _, port := range f.devicePorts(vm.ID) {
+		f.disconnectPort(port)
+	}
+	for _, pool := range f.lbaasPools {
+		members := pool.Members[:0]
+		for _, member := range pool.Members {
+			if member.Vm != vm.ID {
+				members = append(members, member)
+			}
+		}
+		pool.Members = members
+	}
+	if vm.Floating != "" {
+		delete(f.floatings, vm.Floating)
+	}
+	delete(f.vms, vm.ID)
+}
+
+func (f *fakeBcc) affinityGroupJSON(group *fakeAffinityGroup) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          group.ID,
+		"name":        group.Name,
+		"description": group.Description,
+		"policy":      group.Policy,
+		"locked":      false,
+		"vdc":         f.vdcRef(group.Vdc),
+		"vms":         []interface{}{},
+	}
+}
+
+func (f *fakeBcc) serveAffinityGroup(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{}
+		for _, group := range fakeSorted(f.affinityGroups) {
+			if vdc := req.query.Get("vdc"); vdc != "" && vdc != group.Vdc {
+				continue
+			}
+			items = append(items, f.affinityGroupJSON(group))
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 1):
+		if _, ok := f.vdcs[req.str("vdc")]; !ok {
+			return fakeBadRequest("vdc: unknown vdc %q", req.str("vdc"))
+		}
+		switch req.str("policy") {
+		case "affinity", "anti-affinity", "soft-affinity", "soft-anti-affinity":
+		default:
+			return fakeBadRequest("policy: %q is not a valid choice", req.str("policy"))
+		}
+		group := &fakeAffinityGroup{
+			fakeBase:    f.newBase(),
+			Name:        req.str("name"),
+			Description: req.str("description"),
+			Policy:      req.str("policy"),
+			Vdc:         req.str("vdc"),
+		}
+		f.affinityGroups[group.ID] = group
+		f.touch(group.ID)
+		return fakeCreated(f.affinityGroupJSON(group))
+	}
+
+	group, ok := f.affinityGroups[req.parts[1]]
+	if !ok || len(req.parts) != 2 {
+		return fakeNotFound()
+	}
+	switch req.method {
+	case http.MethodGet:
+		return f.withLock(group.ID, f.affinityGroupJSON(group))
+	case http.MethodPut:
+		group.Name = req.str("name")
+		group.Description = req.str("description")
+		f.touch(group.ID)
+		return fakeOK(f.affinityGroupJSON(group))
+	case http.MethodDelete:
+		delete(f.affinityGroups, group.ID)
+		return fakeNoContent()
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) kubernetesTemplateJSON(id string) map[string]interface{} {
+	for _, template := range f.kubernetesTemplate {
+		if template.ID == id {
+			return map[string]interface{}{
+				"id":           template.ID,
+				"name":         template.Name,
+				"min_node_cpu": template.MinNodeCpu,
+				"min_node_ram": template.MinNodeRam,
+				"min_node_hdd": template.MinNodeHdd,
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeBcc) serveKubernetesTemplate(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{}
+		for _, template := range f.kubernetesTemplate {
+			items = append(items, f.kubernetesTemplateJSON(template.ID))
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodGet, 2):
+		if template := f.kubernetesTemplateJSON(req.parts[1]); template != nil {
+			return fakeOK(template)
+		}
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) kubernetesJSON(k *fakeKubernetes) map[string]interface{} {
+	project := map[string]interface{}{"id": "", "name": ""}
+	if vdc, ok := f.vdcs[k.Vdc]; ok {
+		if p, ok := f.projects[vdc.Project]; ok {
+			project = map[string]interface{}{"id": p.ID, "name": p.Name}
+		}
+	}
+	// The platform is reported as an empty object on hypervisors without
+	// platforms.
+	platform := f.platformJSON(k.NodePlatform)
+	if platform == nil {
+		platform = map[string]interface{}{"id": ""}
+	}
+	vms := []interface{}{}
+	for i, id := range k.Nodes {
+		vms = append(vms, map[string]interface{}{"id": id, "name": fmt.Sprintf("%s-node-%d", k.Name, i+1)})
+	}
+	return map[string]interface{}{
+		"id":                   k.ID,
+		"name":                 k.Name,
+		"locked":               false,
+		"vdc":                  f.vdcRef(k.Vdc),
+		"vms":                  vms,
+		"project":              project,
+		"floating":             f.floatingJSON(k.Floating),
+		"job_id":               "",
+		"node_cpu":             k.NodeCpu,
+		"node_disk_size":       k.NodeDiskSize,
+		"node_platform":        platform,
+		"node_ram":             k.NodeRam,
+		"node_storage_profile": f.storageProfileJSON(k.NodeStorageProfile),
+		"nodes_count":          k.NodesCount,
+		"template":             f.kubernetesTemplateJSON(k.Template),
+		"user_public_key":      k.UserPublicKey,
+		"tags":                 fakeTags(k.Tags),
+	}
+}
+
+func (f *fakeBcc) serveKubernetes(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{}
+		for _, k := range fakeSorted(f.kubernetes) {
+			if vdc := req.query.Get("vdc"); vdc != "" && vdc != k.Vdc {
+				continue
+			}
+			items = append(items, f.kubernetesJSON(k))
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 1):
+		vdc, ok := f.vdcs[req.str("vdc")]
+		if !ok {
+			return fakeBadRequest("vdc: unknown vdc %q", req.str("vdc"))
+		}
+		if f.kubernetesTemplateJSON(req.str("template")) == nil {
+			return fakeBadRequest("template: unknown template %q", req.str("template"))
+		}
+		if f.storageProfile(req.str("node_storage_profile")) == nil {
+			return fakeBadRequest("node_storage_profile: unknown storage profile %q", req.str("node_storage_profile"))
+		}
+		if req.str("user_public_key") != fakePubKeyID {
+			return fakeBadRequest("user_public_key: unknown key %q", req.str("user_public_key"))
+		}
+		k := &fakeKubernetes{
+			fakeBase:           f.newBase(),
+			Name:               req.str("name"),
+			Vdc:                vdc.ID,
+			NodeCpu:            req.int("node_cpu"),
+			NodeRam:            req.int("node_ram"),
+			NodeDiskSize:       req.int("node_disk_size"),
+			NodesCount:         req.int("nodes_count"),
+			NodeStorageProfile: req.str("node_storage_profile"),
+			NodePlatform:       req.str("node_platform"),
+			Template:           req.str("template"),
+			UserPublicKey:      req.str("user_public_key"),
+			Tags:               req.strings("tags"),
+		}
+		k.Floating = f.setFloating("", req)
+		f.scaleKubernetes(k)
+		f.kubernetes[k.ID] = k
+		f.touch(k.ID)
+		return fakeCreated(f.kubernetesJSON(k))
+	}
+
+	k, ok := f.kubernetes[req.parts[1]]
+	if !ok {
+		return fakeNotFound()
+	}
+	switch {
+	case req.is(http.MethodGet, 2):
+		return f.withLock(k.ID, f.kubernetesJSON(k))
+	case req.is(http.MethodGet, 3) && req.parts[2] == "config":
+		// bcc-go saves a non-empty config next to the binary, keep it empty.
+		return http.StatusOK, nil
+	case req.is(http.MethodGet, 3) && req.parts[2] == "dashboard":
+		return fakeOK(map[string]interface{}{"url": fmt.Sprintf("/k8s/%s/dashboard", k.ID)})
+	case req.is(http.MethodPut, 2):
+		if req.int("nodes_count") < k.NodesCount {
+			return fakeBadRequest("nodes_count: can not be decreased")
+		}
+		k.Name = req.str("name")
+		k.NodesCount = req.int("nodes_count")
+		k.NodeCpu = req.int("node_cpu")
+		k.NodeRam = req.int("node_ram")
+		k.NodeDiskSize = req.int("node_disk_size")
+		k.NodeStorageProfile = req.str("node_storage_profile")
+		k.UserPublicKey = req.str("user_public_key")
+		k.Tags = req.strings("tags")
+		k.Floating = f.setFloating(k.Floating, req)
+		f.scaleKubernetes(k)
+		f.touch(k.ID)
+		return fakeOK(f.kubernetesJSON(k))
+	case req.is(http.MethodDelete, 2):
+		f.deleteKubernetes(k)
+		return fakeNoContent()
+	}
+	return fakeNotFound()
+}
+
+// scaleKubernetes adds node vms up to the requested nodes count.
+func (f *fakeBcc) scaleKubernetes(k *fakeKubernetes) {
+	for len(k.Nodes) < k.NodesCount {
+		base := f.newBase()
+		k.Nodes = append(k.Nodes, base.ID)
+	}
+}
+
+func (f *fakeBcc) deleteKubernetes(k *fakeKubernetes) {
+	if k.Floating != "" {
+		delete(f.floatings, k.Floating)
+	}
+	delete(f.kubernetes, k.ID)
+}
+
+func (f *fakeBcc) s3StorageJSON(s3 *fakeS3Storage) map[string]interface{} {
+	var project interface{}
+	if p, ok := f.projects[s3.Project]; ok {
+		project = f.projectJSON(p)
+	}
+	return map[string]interface{}{
+		"id":              s3.ID,
+		"locked":          false,
+		"job_id":          "",
+		"client_endpoint": "https://s3.example.com",
+		"access_key":      "access-" + s3.ID[len(s3.ID)-6:],
+		"secret_key":      "secret-" + s3.ID[len(s3.ID)-6:],
+		"backend":         s3.Backend,
+		"name":            s3.Name,
+		"project":         project,
+		"tags":            fakeTags(s3.Tags),
+	}
+}
+
+func (f *fakeBcc) serveS3Storage(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{}
+		for _, s3 := range fakeSorted(f.s3Storages) {
+			if project := req.query.Get("project"); project != "" && project != s3.Project {
+				continue
+			}
+			items = append(items, f.s3StorageJSON(s3))
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 1):
+		if _, ok := f.projects[req.str("project")]; !ok {
+			return fakeBadRequest("project: unknown project %q", req.str("project"))
+		}
+		s3 := &fakeS3Storage{
+			fakeBase: f.newBase(),
+			Name:     req.str("name"),
+			Project:  req.str("project"),
+			Backend:  req.str("backend"),
+			Tags:     req.strings("tags"),
+		}
+		f.s3Storages[s3.ID] = s3
+		f.touch(s3.ID)
+		return fakeCreated(f.s3StorageJSON(s3))
+	}
+
+	s3, ok := f.s3Storages[req.parts[1]]
+	if !ok {
+		return fakeNotFound()
+	}
+	switch {
+	case req.is(http.MethodGet, 2):
+		return f.withLock(s3.ID, f.s3StorageJSON(s3))
+	case req.is(http.MethodPut, 2):
+		s3.Name = req.str("name")
+		s3.Tags = req.strings("tags")
+		f.touch(s3.ID)
+		return fakeOK(f.s3StorageJSON(s3))
+	case req.is(http.MethodDelete, 2):
+		for _, bucket := range f.s3Buckets {
+			if bucket.S3 == s3.ID {
+				delete(f.s3Buckets, bucket.ID)
+			}
+		}
+		delete(f.s3Storages, s3.ID)
+		return fakeNoContent()
+	case len(req.parts) >= 3 && req.parts[2] == "bucket":
+		return f.serveS3Bucket(req, s3)
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) s3BucketJSON(bucket *fakeS3Bucket) map[string]interface{} {
+	return map[string]interface{}{
+		"id":            bucket.ID,
+		"name":          bucket.Name,
+		"external_name": bucket.S3[len(bucket.S3)-6:] + "-" + bucket.Name,
+	}
+}
+
+func (f *fakeBcc) serveS3Bucket(req *fakeRequest, s3 *fakeS3Storage) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 3):
+		items := []interface{}{}
+		for _, bucket := range fakeSorted(f.s3Buckets) {
+			if bucket.S3 == s3.ID {
+				items = append(items, f.s3BucketJSON(bucket))
+			}
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 3):
+		if req.str("name") == "" {
+			return fakeBadRequest("name: this field is required")
+		}
+		bucket := &fakeS3Bucket{fakeBase: f.newBase(), S3: s3.ID, Name: req.str("name")}
+		f.s3Buckets[bucket.ID] = bucket
+		f.touch(s3.ID)
+		return fakeCreated(f.s3BucketJSON(bucket))
+	}
+
+	bucket, ok := f.s3Buckets[req.parts[3]]
+	if !ok || bucket.S3 != s3.ID || len(req.parts) != 4 {
+		return fakeNotFound()
+	}
+	switch req.method {
+	case http.MethodGet:
+		return fakeOK(f.s3BucketJSON(bucket))
+	case http.MethodPut:
+		bucket.Name = req.str("name")
+		return fakeOK(f.s3BucketJSON(bucket))
+	case http.MethodDelete:
+		delete(f.s3Buckets, bucket.ID)
+		return fakeNoContent()
+	}
+	return fakeNotFound()
+}
+
+// servePaas creates the PaaS location of a VDC.
+func (f *fakeBcc) servePaas(req *fakeRequest) (int, interface{}) {
+	if !req.is(http.MethodPost, 1) {
+		return fakeNotFound()
+	}
+	vdc, ok := f.vdcs[req.str("vdc")]
+	if !ok {
+		return fakeBadRequest("vdc: unknown vdc %q", req.str("vdc"))
+	}
+	if vdc.Paas != "" {
+		return fakeBadRequest("vdc: paas location already exists")
+	}
+	base := f.newBase()
+	vdc.Paas = base.ID
+	return fakeCreated(map[string]interface{}{"id": base.ID})
+}
+
+func (f *fakeBcc) paasTemplateJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"id":                    fakePaasTemplateID,
+		"name":                  fakePaasTemplateName,
+		"description":           "Managed PostgreSQL",
+		"display_name":          fakePaasTemplateName,
+		"tenant":                "",
+		"base64_icon":           "",
+		"platform_tags":         []string{},
+		"platforms":             []int{},
+		"tags":                  []string{},
+		"published_to_showcase": true,
+	}
+}
+
+// paasVdc returns the VDC named by `vdc_id` once it has a PaaS location.
+func (f *fakeBcc) paasVdc(req *fakeRequest) *fakeVdc {
+	vdc, ok := f.vdcs[req.query.Get("vdc_id")]
+	if !ok || vdc.Paas == "" {
+		return nil
+	}
+	return vdc
+}
+
+func (f *fakeBcc) servePaasTemplate(req *fakeRequest) (int, interface{}) {
+	if f.paasVdc(req) == nil && req.method == http.MethodGet && len(req.parts) < 3 {
+		return fakeBadRequest("vdc_id: vdc has no paas location")
+	}
+	switch {
+	case req.is(http.MethodGet, 1):
+		return fakePage([]interface{}{f.paasTemplateJSON()}, req.query)
+	case req.is(http.MethodGet, 2) && req.parts[1] == strconv.Itoa(fakePaasTemplateID):
+		return fakeOK(f.paasTemplateJSON())
+	case req.is(http.MethodGet, 3) && req.parts[1] == strconv.Itoa(fakePaasTemplateID) && req.parts[2] == "inputs":
+		return fakeOK(map[string]interface{}{
+			"inputs": []interface{}{
+				map[string]interface{}{"id": 1, "name": "db_name", "required": true},
+				map[string]interface{}{"id": 2, "name": "db_size", "required": false, "default": 10},
+			},
+		})
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) paasServiceJSON(service *fakePaasService) map[string]interface{} {
+	vdcName := ""
+	if vdc, ok := f.vdcs[service.Vdc]; ok {
+		vdcName = vdc.Name
+	}
+	return map[string]interface{}{
+		"id":                  service.ID,
+		"name":                service.Name,
+		"vdc":                 map[string]interface{}{"id": service.Vdc, "name": vdcName},
+		"paas_deploy_id":      service.seq,
+		"paas_service_id":     service.Template,
+		"paas_service_name":   fakePaasTemplateName,
+		"status":              "ready",
+		"paas_internal_id":    "paas-" + strconv.Itoa(service.seq),
+		"paas_service_inputs": service.Inputs,
+		"locked":              false,
+	}
+}
+
+func (f *fakeBcc) servePaasService(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{}
+		for _, service := range fakeSorted(f.paasServices) {
+			if vdc := req.query.Get("vdc"); vdc != "" && vdc != service.Vdc {
+				continue
+			}
+			items = append(items, f.paasServiceJSON(service))
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 1):
+		vdc, ok := f.vdcs[req.str("vdc")]
+		if !ok || vdc.Paas == "" {
+			return fakeBadRequest("vdc: vdc %q has no paas location", req.str("vdc"))
+		}
+		if req.int("paas_service_id") != fakePaasTemplateID {
+			return fakeBadRequest("paas_service_id: unknown template %d", req.int("paas_service_id"))
+		}
+		service := &fakePaasService{
+			fakeBase: f.newBase(),
+			Name:     req.str("name"),
+			Vdc:      vdc.ID,
+			Template: req.int("paas_service_id"),
+			Inputs:   req.body["paas_service_inputs"],
+		}
+		f.paasServices[service.ID] = service
+		f.touch(service.ID)
+		return fakeCreated(f.paasServiceJSON(service))
+	}
+
+	service, ok := f.paasServices[req.parts[1]]
+	if !ok || len(req.parts) != 2 {
+		return fakeNotFound()
+	}
+	switch req.method {
+	case http.MethodGet:
+		return f.withLock(service.ID, f.paasServiceJSON(service))
+	case http.MethodPut:
+		service.Name = req.str("name")
+		service.Inputs = req.body["paas_service_inputs"]
+		f.touch(service.ID)
+		return fakeOK(f.paasServiceJSON(service))
+	case http.MethodDelete:
+		delete(f.paasServices, service.ID)
+		return fakeNoContent()
+	}
+	return fakeNotFound()
+}
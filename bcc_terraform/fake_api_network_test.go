@@ -0,0 +1,1216 @@
+package bcc_terraform
+
+import (
+	"net/http"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+const (
+	fakeFirewallTemplateID   = "00000000-0000-4000-8000-f10000000001"
+	fakeFirewallTemplateName = "Разрешить исходящие"
+)
+
+type fakeNetwork struct {
+	fakeBase
+	Name      string
+	Vdc       string
+	IsDefault bool
+	Mtu       *int
+	Tags      []string
+}
+
+type fakeSubnet struct {
+	fakeBase
+	Network string
+	CIDR    string
+	Gateway string
+	StartIp string
+	EndIp   string
+	DHCP    bool
+	DNS     []string
+	Routes  []interface{}
+}
+
+type fakePort struct {
+	fakeBase
+	Vdc        string
+	Network    string
+	IP         string
+	Firewalls  []string
+	Tags       []string
+	Device     string
+	DeviceType string
+
+	// connected orders the ports of a device the way they were attached.
+	connected int
+}
+
+type fakeFirewall struct {
+	fakeBase
+	Name        string
+	Description string
+	Vdc         string
+	Tags        []string
+}
+
+type fakeFirewallRule struct {
+	fakeBase
+	Firewall      string
+	Name          string
+	DestinationIp string
+	Direction     string
+	Protocol      string
+	PortMin       *int
+	PortMax       *int
+}
+
+type fakeRoute struct {
+	ID          string
+	Destination string
+	NextHop     string
+}
+
+type fakeRouter struct {
+	fakeBase
+	Name      string
+	Vdc       string
+	IsDefault bool
+	Floating  string
+	Routes    []*fakeRoute
+	Tags      []string
+}
+
+type fakeLbaas struct {
+	fakeBase
+	Name     string
+	Vdc      string
+	Port     string
+	Floating string
+	Tags     []string
+}
+
+type fakePoolMember struct {
+	ID     string
+	Port   int
+	Weight int
+	Vm     string
+}
+
+type fakeLbaasPool struct {
+	fakeBase
+	Lbaas              string
+	Port               int
+	Connlimit          int
+	Members            []*fakePoolMember
+	Method             string
+	Protocol           string
+	SessionPersistence interface{}
+}
+
+type fakeDns struct {
+	fakeBase
+	Name    string
+	Project string
+	Tags    []string
+}
+
+type fakeDnsRecord struct {
+	fakeBase
+	Dns      string
+	Data     string
+	Flag     int
+	Host     string
+	Port     int
+	Priority int
+	Tag      string
+	Ttl      int
+	Type     string
+	Weight   int
+}
+
+func (f *fakeBcc) networkSubnets(networkId string) []*fakeSubnet {
+	subnets := []*fakeSubnet{}
+	for _, subnet := range fakeSorted(f.subnets) {
+		if subnet.Network == networkId {
+			subnets = append(subnets, subnet)
+		}
+	}
+	return subnets
+}
+
+func (f *fakeBcc) subnetJSON(subnet *fakeSubnet) map[string]interface{} {
+	dns := make([]interface{}, len(subnet.DNS))
+	for i, server := range subnet.DNS {
+		dns[i] = map[string]interface{}{"dns_server": server}
+	}
+	routes := subnet.Routes
+	if routes == nil {
+		routes = []interface{}{}
+	}
+	return map[string]interface{}{
+		"id":            subnet.ID,
+		"cidr":          subnet.CIDR,
+		"gateway":       subnet.Gateway,
+		"start_ip":      subnet.StartIp,
+		"end_ip":        subnet.EndIp,
+		"enable_dhcp":   subnet.DHCP,
+		"locked":        false,
+		"dns_servers":   dns,
+		"subnet_routes": routes,
+	}
+}
+
+func (f *fakeBcc) networkJSON(network *fakeNetwork) map[string]interface{} {
+	subnets := []interface{}{}
+	for _, subnet := range f.networkSubnets(network.ID) {
+		subnets = append(subnets, f.subnetJSON(subnet))
+	}
+	vdcName := ""
+	if vdc, ok := f.vdcs[network.Vdc]; ok {
+		vdcName = vdc.Name
+	}
+	var mtu interface{}
+	if network.Mtu != nil {
+		mtu = *network.Mtu
+	}
+	return map[string]interface{}{
+		"id":         network.ID,
+		"name":       network.Name,
+		"is_default": network.IsDefault,
+		"mtu":        mtu,
+		"vdc":        map[string]interface{}{"id": network.Vdc, "name": vdcName},
+		"locked":     false,
+		"subnets":    subnets,
+		"tags":       fakeTags(network.Tags),
+		"external":   false,
+	}
+}
+
+func (f *fakeBcc) serveNetwork(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{}
+		for _, network := range fakeSorted(f.networks) {
+			if vdc := req.query.Get("vdc"); vdc != "" && vdc != network.Vdc {
+				continue
+			}
+			if req.query.Get("defaults_only") == "true" && !network.IsDefault {
+				continue
+			}
+			if fakeNameMatches(req.query, network.Name) {
+				items = append(items, f.networkJSON(network))
+			}
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 1):
+		if req.str("name") == "" {
+			return fakeBadRequest("name: this field is required")
+		}
+		if _, ok := f.vdcs[req.str("vdc")]; !ok {
+			return fakeBadRequest("vdc: unknown vdc %q", req.str("vdc"))
+		}
+		network := &fakeNetwork{
+			fakeBase: f.newBase(),
+			Name:     req.str("name"),
+			Vdc:      req.str("vdc"),
+			Mtu:      req.intPtr("mtu"),
+			Tags:     req.strings("tags"),
+		}
+		f.networks[network.ID] = network
+		f.touch(network.ID)
+		return fakeCreated(f.networkJSON(network))
+	}
+
+	network, ok := f.networks[req.parts[1]]
+	if !ok {
+		return fakeNotFound()
+	}
+	switch {
+	case req.is(http.MethodGet, 2):
+		return f.withLock(network.ID, f.networkJSON(network))
+	case req.is(http.MethodPut, 2):
+		network.Name = req.str("name")
+		network.Mtu = req.intPtr("mtu")
+		network.Tags = req.strings("tags")
+		f.touch(network.ID)
+		return fakeOK(f.networkJSON(network))
+	case req.is(http.MethodDelete, 2):
+		if network.IsDefault {
+			return fakeBadRequest("default network can not be deleted")
+		}
+		for _, port := range f.ports {
+			if port.Network == network.ID {
+				return fakeBadRequest("network has ports")
+			}
+		}
+		f.deleteNetwork(network)
+		return fakeNoContent()
+	case len(req.parts) >= 3 && req.parts[2] == "subnet":
+		return f.serveSubnet(req, network)
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) deleteNetwork(network *fakeNetwork) {
+	for _, subnet := range f.subnets {
+		if subnet.Network == network.ID {
+			delete(f.subnets, subnet.ID)
+		}
+	}
+	delete(f.networks, network.ID)
+}
+
+// applySubnet validates the subnet fields of the request and stores them.
+func (f *fakeBcc) applySubnet(subnet *fakeSubnet, req *fakeRequest) (int, interface{}) {
+	prefix, err := netip.ParsePrefix(req.str("cidr"))
+	if err != nil || prefix.Masked() != prefix {
+		return fakeBadRequest("cidr: %q is not a valid network", req.str("cidr"))
+	}
+	for _, field := range []string{"gateway", "start_ip", "end_ip"} {
+		addr, err := netip.ParseAddr(req.str(field))
+		if err != nil || !prefix.Contains(addr) {
+			return fakeBadRequest("%s: %q is not in %s", field, req.str(field), prefix)
+		}
+	}
+	subnet.CIDR = req.str("cidr")
+	subnet.Gateway = req.str("gateway")
+	subnet.StartIp = req.str("start_ip")
+	subnet.EndIp = req.str("end_ip")
+	subnet.DHCP = req.bool("enable_dhcp")
+	subnet.DNS = []string{}
+	servers, _ := req.body["dns_servers"].([]interface{})
+	for _, server := range servers {
+		if server, ok := server.(map[string]interface{}); ok {
+			if addr, ok := server["dns_server"].(string); ok {
+				subnet.DNS = append(subnet.DNS, addr)
+			}
+		}
+	}
+	subnet.Routes, _ = req.body["subnet_routes"].([]interface{})
+	return 0, nil
+}
+
+func (f *fakeBcc) serveSubnet(req *fakeRequest, network *fakeNetwork) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 3):
+		items := []interface{}{}
+		for _, subnet := range f.networkSubnets(network.ID) {
+			items = append(items, f.subnetJSON(subnet))
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 3):
+		subnet := &fakeSubnet{fakeBase: f.newBase(), Network: network.ID}
+		if status, resp := f.applySubnet(subnet, req); resp != nil {
+			return status, resp
+		}
+		for _, existing := range f.networkSubnets(network.ID) {
+			if existing.CIDR == subnet.CIDR {
+				return fakeBadRequest("cidr: subnet %s already exists", subnet.CIDR)
+			}
+		}
+		f.subnets[subnet.ID] = subnet
+		f.touch(network.ID)
+		return fakeCreated(f.subnetJSON(subnet))
+	}
+
+	subnet, ok := f.subnets[req.parts[3]]
+	if !ok || subnet.Network != network.ID || len(req.parts) != 4 {
+		return fakeNotFound()
+	}
+	switch req.method {
+	case http.MethodGet:
+		return f.withLock(subnet.ID, f.subnetJSON(subnet))
+	case http.MethodPut:
+		if req.str("cidr") != subnet.CIDR {
+			return fakeBadRequest("cidr: can not be changed")
+		}
+		if status, resp := f.applySubnet(subnet, req); resp != nil {
+			return status, resp
+		}
+		f.touch(subnet.ID)
+		return fakeOK(f.subnetJSON(subnet))
+	case http.MethodDelete:
+		delete(f.subnets, subnet.ID)
+		return fakeNoContent()
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) firewallJSON(firewall *fakeFirewall) map[string]interface{} {
+	count := 0
+	for _, rule := range f.firewallRules {
+		if rule.Firewall == firewall.ID {
+			count++
+		}
+	}
+	var vdc interface{}
+	if firewall.Vdc != "" {
+		vdc = f.vdcRef(firewall.Vdc)
+	}
+	return map[string]interface{}{
+		"id":          firewall.ID,
+		"name":        firewall.Name,
+		"description": firewall.Description,
+		"locked":      false,
+		"rules_count": count,
+		"vdc":         vdc,
+		"tags":        fakeTags(firewall.Tags),
+	}
+}
+
+// firewall returns a template of the VDC or the shared one every VDC sees.
+func (f *fakeBcc) firewall(id string) *fakeFirewall {
+	if id == fakeFirewallTemplateID {
+		return &fakeFirewall{fakeBase: fakeBase{ID: fakeFirewallTemplateID}, Name: fakeFirewallTemplateName}
+	}
+	return f.firewalls[id]
+}
+
+func (f *fakeBcc) serveFirewall(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{f.firewallJSON(f.firewall(fakeFirewallTemplateID))}
+		for _, firewall := range fakeSorted(f.firewalls) {
+			if vdc := req.query.Get("vdc"); vdc == "" || vdc == firewall.Vdc {
+				items = append(items, f.firewallJSON(firewall))
+			}
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 1):
+		if req.str("name") == "" {
+			return fakeBadRequest("name: this field is required")
+		}
+		if _, ok := f.vdcs[req.str("vdc")]; !ok {
+			return fakeBadRequest("vdc: unknown vdc %q", req.str("vdc"))
+		}
+		firewall := &fakeFirewall{
+			fakeBase:    f.newBase(),
+			Name:        req.str("name"),
+			Description: req.str("description"),
+			Vdc:         req.str("vdc"),
+			Tags:        req.strings("tags"),
+		}
+		f.firewalls[firewall.ID] = firewall
+		f.touch(firewall.ID)
+		return fakeCreated(f.firewallJSON(firewall))
+	}
+
+	firewall := f.firewall(req.parts[1])
+	if firewall == nil {
+		return fakeNotFound()
+	}
+	if req.method != http.MethodGet && len(req.parts) == 2 && firewall.ID == fakeFirewallTemplateID {
+		return http.StatusForbidden, fakeErrorBody("permission_denied", "Default template can not be changed.")
+	}
+	switch {
+	case req.is(http.MethodGet, 2):
+		return f.withLock(firewall.ID, f.firewallJSON(firewall))
+	case req.is(http.MethodPut, 2):
+		firewall.Name = req.str("name")
+		firewall.Description = req.str("description")
+		firewall.Tags = req.strings("tags")
+		f.touch(firewall.ID)
+		return fakeOK(f.firewallJSON(firewall))
+	case req.is(http.MethodDelete, 2):
+		for _, port := range f.ports {
+			for _, id := range port.Firewalls {
+				if id == firewall.ID {
+					return fakeBadRequest("firewall template is used by ports")
+				}
+			}
+		}
+		f.deleteFirewall(firewall)
+		return fakeNoContent()
+	case len(req.parts) >= 3 && req.parts[2] == "rule":
+		return f.serveFirewallRule(req, firewall)
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) deleteFirewall(firewall *fakeFirewall) {
+	for _, rule := range f.firewallRules {
+		if rule.Firewall == firewall.ID {
+			delete(f.firewallRules, rule.ID)
+		}
+	}
+	delete(f.firewalls, firewall.ID)
+}
+
+func (f *fakeBcc) firewallRuleJSON(rule *fakeFirewallRule) map[string]interface{} {
+	var portMin, portMax interface{}
+	if rule.PortMin != nil {
+		portMin = *rule.PortMin
+	}
+	if rule.PortMax != nil {
+		portMax = *rule.PortMax
+	}
+	return map[string]interface{}{
+		"id":                 rule.ID,
+		"name":               rule.Name,
+		"destination_ip":     rule.DestinationIp,
+		"direction":          rule.Direction,
+		"protocol":           rule.Protocol,
+		"dst_port_range_min": portMin,
+		"dst_port_range_max": portMax,
+		"locked":             false,
+	}
+}
+
+func (f *fakeBcc) applyFirewallRule(rule *fakeFirewallRule, req *fakeRequest) (int, interface{}) {
+	switch req.str("direction") {
+	case "ingress", "egress":
+	default:
+		return fakeBadRequest("direction: %q is not a valid choice", req.str("direction"))
+	}
+	rule.Name = req.str("name")
+	rule.DestinationIp = req.str("destination_ip")
+	rule.Direction = req.str("direction")
+	rule.Protocol = req.str("protocol")
+	rule.PortMin = req.intPtr("dst_port_range_min")
+	rule.PortMax = req.intPtr("dst_port_range_max")
+	return 0, nil
+}
+
+func (f *fakeBcc) serveFirewallRule(req *fakeRequest, firewall *fakeFirewall) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 3):
+		// Rules are listed as a plain array, not as a page.
+		items := []interface{}{}
+		for _, rule := range fakeSorted(f.firewallRules) {
+			if rule.Firewall == firewall.ID {
+				items = append(items, f.firewallRuleJSON(rule))
+			}
+		}
+		return fakeOK(items)
+	case req.is(http.MethodPost, 3):
+		rule := &fakeFirewallRule{fakeBase: f.newBase(), Firewall: firewall.ID}
+		if status, resp := f.applyFirewallRule(rule, req); resp != nil {
+			return status, resp
+		}
+		f.firewallRules[rule.ID] = rule
+		f.touch(rule.ID)
+		return fakeCreated(f.firewallRuleJSON(rule))
+	}
+
+	rule, ok := f.firewallRules[req.parts[3]]
+	if !ok || rule.Firewall != firewall.ID || len(req.parts) != 4 {
+		return fakeNotFound()
+	}
+	switch req.method {
+	case http.MethodGet:
+		return f.withLock(rule.ID, f.firewallRuleJSON(rule))
+	case http.MethodPut:
+		if status, resp := f.applyFirewallRule(rule, req); resp != nil {
+			return status, resp
+		}
+		f.touch(rule.ID)
+		return fakeOK(f.firewallRuleJSON(rule))
+	case http.MethodDelete:
+		delete(f.firewallRules, rule.ID)
+		return fakeNoContent()
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) portJSON(port *fakePort) map[string]interface{} {
+	firewalls := []interface{}{}
+	for _, id := range port.Firewalls {
+		if firewall := f.firewall(id); firewall != nil {
+			firewalls = append(firewalls, f.firewallJSON(firewall))
+		}
+	}
+	var network interface{}
+	if n, ok := f.networks[port.Network]; ok {
+		network = f.networkJSON(n)
+	}
+	var connected interface{}
+	if port.Device != "" {
+		connected = map[string]interface{}{
+			"id":   port.Device,
+			"name": f.deviceName(port.Device),
+			"type": port.DeviceType,
+			"vdc":  f.vdcRef(port.Vdc),
+		}
+	}
+	return map[string]interface{}{
+		"id":           port.ID,
+		"ip_address":   port.IP,
+		"network":      network,
+		"fw_templates": firewalls,
+		"connected":    connected,
+		"locked":       false,
+		"tags":         fakeTags(port.Tags),
+		"vdc":          f.vdcRef(port.Vdc),
+	}
+}
+
+func (f *fakeBcc) deviceName(id string) string {
+	if vm, ok := f.vms[id]; ok {
+		return vm.Name
+	}
+	if router, ok := f.routers[id]; ok {
+		return router.Name
+	}
+	if lbaas, ok := f.lbaases[id]; ok {
+		return lbaas.Name
+	}
+	return ""
+}
+
+func (f *fakeBcc) devicePorts(id string) []*fakePort {
+	ports := []*fakePort{}
+	for _, port := range fakeSorted(f.ports) {
+		if port.Device == id {
+			ports = append(ports, port)
+		}
+	}
+	sort.SliceStable(ports, func(i, j int) bool { return ports[i].connected < ports[j].connected })
+	return ports
+}
+
+func (f *fakeBcc) connectPort(port *fakePort, device string, deviceType string) {
+	f.seq++
+	port.Device = device
+	port.DeviceType = deviceType
+	port.connected = f.seq
+	f.touch(port.ID)
+}
+
+func (f *fakeBcc) disconnectPort(port *fakePort) {
+	port.Device = ""
+	port.DeviceType = ""
+	f.touch(port.ID)
+}
+
+// applyPortConnection connects the port to the vm or the router named in the
+// request, if any.
+func (f *fakeBcc) applyPortConnection(port *fakePort, req *fakeRequest) (int, interface{}) {
+	if vm := req.str("vm"); vm != "" {
+		if _, ok := f.vms[vm]; !ok {
+			return fakeBadRequest("vm: unknown vm %q", vm)
+		}
+		f.connectPort(port, vm, "vm_int")
+	} else if router := req.str("router"); router != "" {
+		if _, ok := f.routers[router]; !ok {
+			return fakeBadRequest("router: unknown router %q", router)
+		}
+		f.connectPort(port, router, "router_int")
+	}
+	return 0, nil
+}
+
+func (f *fakeBcc) applyPortFirewalls(port *fakePort, req *fakeRequest) (int, interface{}) {
+	if !req.has("fw_templates") {
+		return 0, nil
+	}
+	firewalls := req.ids("fw_templates")
+	for _, id := range firewalls {
+		if f.firewall(id) == nil {
+			return fakeBadRequest("fw_templates: unknown firewall template %q", id)
+		}
+	}
+	port.Firewalls = firewalls
+	return 0, nil
+}
+
+func (f *fakeBcc) servePort(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{}
+		for _, port := range fakeSorted(f.ports) {
+			if vdc := req.query.Get("vdc"); vdc != "" && vdc != port.Vdc {
+				continue
+			}
+			items = append(items, f.portJSON(port))
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 1):
+		network, ok := f.networks[req.str("network")]
+		if !ok {
+			return fakeBadRequest("network: unknown network %q", req.str("network"))
+		}
+		port := &fakePort{fakeBase: f.newBase(), Vdc: network.Vdc, Network: network.ID, Tags: req.strings("tags")}
+		ip, err := f.allocateIp(network.ID, req.str("ip_address"), "")
+		if err != nil {
+			return fakeBadRequest("%s", err)
+		}
+		port.IP = ip
+		if status, resp := f.applyPortFirewalls(port, req); resp != nil {
+			return status, resp
+		}
+		if status, resp := f.applyPortConnection(port, req); resp != nil {
+			return status, resp
+		}
+		f.ports[port.ID] = port
+		f.touch(port.ID)
+		return fakeCreated(f.portJSON(port))
+	}
+
+	port, ok := f.ports[req.parts[1]]
+	if !ok {
+		return fakeNotFound()
+	}
+	switch {
+	case req.is(http.MethodGet, 2):
+		return f.withLock(port.ID, f.portJSON(port))
+	case req.is(http.MethodPut, 2):
+		if req.has("ip_address") && req.str("ip_address") != port.IP {
+			ip, err := f.allocateIp(port.Network, req.str("ip_address"), port.ID)
+			if err != nil {
+				return fakeBadRequest("%s", err)
+			}
+			port.IP = ip
+		}
+		if status, resp := f.applyPortFirewalls(port, req); resp != nil {
+			return status, resp
+		}
+		if status, resp := f.applyPortConnection(port, req); resp != nil {
+			return status, resp
+		}
+		if req.has("tags") {
+			port.Tags = req.strings("tags")
+		}
+		f.touch(port.ID)
+		return fakeOK(f.portJSON(port))
+	case req.is(http.MethodPatch, 3) && req.parts[2] == "disconnect":
+		f.disconnectPort(port)
+		return fakeOK(f.portJSON(port))
+	case req.is(http.MethodDelete, 2):
+		if port.Device != "" {
+			return fakeBadRequest("port is connected to %s", port.Device)
+		}
+		delete(f.ports, port.ID)
+		return fakeNoContent()
+	case req.is(http.MethodDelete, 3) && req.parts[2] == "force":
+		delete(f.ports, port.ID)
+		return fakeNoContent()
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) routerJSON(router *fakeRouter) map[string]interface{} {
+	ports := []interface{}{}
+	for _, port := range f.devicePorts(router.ID) {
+		ports = append(ports, f.portJSON(port))
+	}
+	routes := []interface{}{}
+	for _, route := range router.Routes {
+		routes = append(routes, map[string]interface{}{
+			"id":          route.ID,
+			"destination": route.Destination,
+			"nexthop":     route.NextHop,
+		})
+	}
+	return map[string]interface{}{
+		"id":         router.ID,
+		"name":       router.Name,
+		"is_default": router.IsDefault,
+		"vdc":        f.vdcRef(router.Vdc),
+		"ports":      ports,
+		"routes":     routes,
+		"floating":   f.floatingJSON(router.Floating),
+		"locked":     false,
+		"tags":       fakeTags(router.Tags),
+	}
+}
+
+func (f *fakeBcc) newRoute(destination string, nextHop string) (*fakeRoute, error) {
+	if _, err := netip.ParsePrefix(destination); err != nil {
+		return nil, err
+	}
+	if _, err := netip.ParseAddr(nextHop); err != nil {
+		return nil, err
+	}
+	base := f.newBase()
+	return &fakeRoute{ID: base.ID, Destination: destination, NextHop: nextHop}, nil
+}
+
+func (f *fakeBcc) serveRouter(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{}
+		for _, router := range fakeSorted(f.routers) {
+			if vdc := req.query.Get("vdc"); vdc != "" && vdc != router.Vdc {
+				continue
+			}
+			if fakeNameMatches(req.query, router.Name) {
+				items = append(items, f.routerJSON(router))
+			}
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 1):
+		vdc, ok := f.vdcs[req.str("vdc")]
+		if !ok {
+			return fakeBadRequest("vdc: unknown vdc %q", req.str("vdc"))
+		}
+		router := &fakeRouter{fakeBase: f.newBase(), Name: req.str("name"), Vdc: vdc.ID, Tags: req.strings("tags")}
+		routes, _ := req.body["routes"].([]interface{})
+		for _, raw := range routes {
+			r, _ := raw.(map[string]interface{})
+			destination, _ := r["destination"].(string)
+			nextHop, _ := r["nexthop"].(string)
+			route, err := f.newRoute(destination, nextHop)
+			if err != nil {
+				return fakeBadRequest("routes: %s", err)
+			}
+			router.Routes = append(router.Routes, route)
+		}
+		for _, id := range req.ids("ports") {
+			port, ok := f.ports[id]
+			if !ok {
+				return fakeBadRequest("ports: unknown port %q", id)
+			}
+			if port.Device != "" {
+				return fakeBadRequest("ports: port %s is already connected", id)
+			}
+		}
+		for _, id := range req.ids("ports") {
+			f.connectPort(f.ports[id], router.ID, "router_int")
+		}
+		router.Floating = f.setFloating("", req)
+		f.routers[router.ID] = router
+		f.touch(router.ID)
+		return fakeCreated(f.routerJSON(router))
+	}
+
+	router, ok := f.routers[req.parts[1]]
+	if !ok {
+		return fakeNotFound()
+	}
+	switch {
+	case req.is(http.MethodGet, 2):
+		return f.withLock(router.ID, f.routerJSON(router))
+	case req.is(http.MethodPut, 2):
+		// Ports and routes have their own endpoints, the router update
+		// only changes the router itself.
+		if req.has("name") {
+			router.Name = req.str("name")
+		}
+		if req.has("tags") {
+			router.Tags = req.strings("tags")
+		}
+		if req.has("is_default") {
+			router.IsDefault = req.bool("is_default")
+		}
+		router.Floating = f.setFloating(router.Floating, req)
+		f.touch(router.ID)
+		return fakeOK(f.routerJSON(router))
+	case req.is(http.MethodDelete, 2):
+		if router.IsDefault {
+			return fakeBadRequest("default router can not be deleted")
+		}
+		f.deleteRouter(router)
+		return fakeNoContent()
+	case len(req.parts) >= 3 && req.parts[2] == "route":
+		return f.serveRoute(req, router)
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) deleteRouter(router *fakeRouter) {
+	for _, port := range f.devicePorts(router.ID) {
+		if router.IsDefault {
+			delete(f.ports, port.ID)
+		} else {
+			f.disconnectPort(port)
+		}
+	}
+	if router.Floating != "" {
+		delete(f.floatings, router.Floating)
+	}
+	delete(f.routers, router.ID)
+}
+
+func (f *fakeBcc) serveRoute(req *fakeRequest, router *fakeRouter) (int, interface{}) {
+	routeJSON := func(route *fakeRoute) map[string]interface{} {
+		return map[string]interface{}{"id": route.ID, "destination": route.Destination, "nexthop": route.NextHop}
+	}
+	if req.is(http.MethodPost, 3) {
+		route, err := f.newRoute(req.str("destination"), req.str("nexthop"))
+		if err != nil {
+			return fakeBadRequest("%s", err)
+		}
+		router.Routes = append(router.Routes, route)
+		f.touch(router.ID)
+		return fakeCreated(routeJSON(route))
+	}
+	if len(req.parts) != 4 {
+		return fakeNotFound()
+	}
+	for i, route := range router.Routes {
+		if route.ID != req.parts[3] {
+			continue
+		}
+		switch req.method {
+		case http.MethodGet:
+			return f.withLock(route.ID, routeJSON(route))
+		case http.MethodPut:
+			updated, err := f.newRoute(req.str("destination"), req.str("nexthop"))
+			if err != nil {
+				return fakeBadRequest("%s", err)
+			}
+			route.Destination = updated.Destination
+			route.NextHop = updated.NextHop
+			f.touch(router.ID)
+			return fakeOK(routeJSON(route))
+		case http.MethodDelete:
+			router.Routes = append(router.Routes[:i], router.Routes[i+1:]...)
+			f.touch(router.ID)
+			return fakeNoContent()
+		}
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) lbaasJSON(lbaas *fakeLbaas) map[string]interface{} {
+	var port interface{}
+	if p, ok := f.ports[lbaas.Port]; ok {
+		port = f.portJSON(p)
+	}
+	return map[string]interface{}{
+		"id":         lbaas.ID,
+		"name":       lbaas.Name,
+		"locked":     false,
+		"vdc":        f.vdcRef(lbaas.Vdc),
+		"job_id":     "",
+		"kubernetes": nil,
+		"port":       port,
+		"floating":   f.floatingJSON(lbaas.Floating),
+		"tags":       fakeTags(lbaas.Tags),
+	}
+}
+
+func (f *fakeBcc) serveLbaas(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{}
+		for _, lbaas := range fakeSorted(f.lbaases) {
+			if vdc := req.query.Get("vdc"); vdc != "" && vdc != lbaas.Vdc {
+				continue
+			}
+			if fakeNameMatches(req.query, lbaas.Name) {
+				items = append(items, f.lbaasJSON(lbaas))
+			}
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 1):
+		vdc, ok := f.vdcs[req.str("vdc")]
+		if !ok {
+			return fakeBadRequest("vdc: unknown vdc %q", req.str("vdc"))
+		}
+		portReq := &fakeRequest{body: map[string]interface{}{}}
+		portReq.body, _ = req.body["port"].(map[string]interface{})
+		network, ok := f.networks[portReq.str("network")]
+		if !ok || network.Vdc != vdc.ID {
+			return fakeBadRequest("port.network: unknown network %q", portReq.str("network"))
+		}
+		ip, err := f.allocateIp(network.ID, portReq.str("ip_address"), "")
+		if err != nil {
+			return fakeBadRequest("port.%s", err)
+		}
+		lbaas := &fakeLbaas{fakeBase: f.newBase(), Name: req.str("name"), Vdc: vdc.ID, Tags: req.strings("tags")}
+		port := &fakePort{fakeBase: f.newBase(), Vdc: vdc.ID, Network: network.ID, IP: ip}
+		f.ports[port.ID] = port
+		f.connectPort(port, lbaas.ID, "lbaas")
+		lbaas.Port = port.ID
+		lbaas.Floating = f.setFloating("", req)
+		f.lbaases[lbaas.ID] = lbaas
+		f.touch(lbaas.ID)
+		return fakeCreated(f.lbaasJSON(lbaas))
+	}
+
+	lbaas, ok := f.lbaases[req.parts[1]]
+	if !ok {
+		return fakeNotFound()
+	}
+	switch {
+	case req.is(http.MethodGet, 2):
+		return f.withLock(lbaas.ID, f.lbaasJSON(lbaas))
+	case req.is(http.MethodPut, 2):
+		if port, ok := f.ports[lbaas.Port]; ok && req.str("ip_address") != "" && req.str("ip_address") != port.IP {
+			ip, err := f.allocateIp(port.Network, req.str("ip_address"), port.ID)
+			if err != nil {
+				return fakeBadRequest("%s", err)
+			}
+			port.IP = ip
+		}
+		lbaas.Name = req.str("name")
+		lbaas.Tags = req.strings("tags")
+		lbaas.Floating = f.setFloating(lbaas.Floating, req)
+		f.touch(lbaas.ID)
+		return fakeOK(f.lbaasJSON(lbaas))
+	case req.is(http.MethodDelete, 2):
+		f.deleteLbaas(lbaas)
+		return fakeNoContent()
+	case len(req.parts) >= 3 && req.parts[2] == "pool":
+		return f.serveLbaasPool(req, lbaas)
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) deleteLbaas(lbaas *fakeLbaas) {
+	for _, pool := range f.lbaasPools {
+		if pool.Lbaas == lbaas.ID {
+			delete(f.lbaasPools, pool.ID)
+		}
+	}
+	delete(f.ports, lbaas.Port)
+	if lbaas.Floating != "" {
+		delete(f.floatings, lbaas.Floating)
+	}
+	delete(f.lbaases, lbaas.ID)
+}
+
+func (f *fakeBcc) lbaasPoolJSON(pool *fakeLbaasPool) map[string]interface{} {
+	members := []interface{}{}
+	for _, member := range pool.Members {
+		var vm interface{}
+		if v, ok := f.vms[member.Vm]; ok {
+			vm = map[string]interface{}{
+				"id":       v.ID,
+				"name":     v.Name,
+				"cpu":      v.Cpu,
+				"ram":      v.Ram,
+				"power":    v.Power,
+				"platform": v.Platform,
+				"vdc":      f.vdcRef(v.Vdc),
+			}
+		}
+		members = append(members, map[string]interface{}{
+			"id":     member.ID,
+			"port":   member.Port,
+			"weight": member.Weight,
+			"vm":     vm,
+		})
+	}
+	return map[string]interface{}{
+		"id":                  pool.ID,
+		"locked":              false,
+		"port":                pool.Port,
+		"connlimit":           pool.Connlimit,
+		"members":             members,
+		"method":              pool.Method,
+		"protocol":            pool.Protocol,
+		"session_persistence": pool.SessionPersistence,
+		"cookie_name":         nil,
+	}
+}
+
+func (f *fakeBcc) applyLbaasPool(pool *fakeLbaasPool, req *fakeRequest) (int, interface{}) {
+	members, _ := req.body["members"].([]interface{})
+	pool.Members = nil
+	for _, raw := range members {
+		member := &fakeRequest{}
+		member.body, _ = raw.(map[string]interface{})
+		vm, ok := f.vms[member.str("vm")]
+		if !ok {
+			return fakeBadRequest("members: unknown vm %q", member.str("vm"))
+		}
+		base := f.newBase()
+		pool.Members = append(pool.Members, &fakePoolMember{
+			ID:     base.ID,
+			Port:   member.int("port"),
+			Weight: member.int("weight"),
+			Vm:     vm.ID,
+		})
+	}
+	pool.Port = req.int("port")
+	pool.Connlimit = req.int("connlimit")
+	pool.Method = req.str("method")
+	if pool.Method == "" {
+		pool.Method = "ROUND_ROBIN"
+	}
+	pool.Protocol = req.str("protocol")
+	if pool.Protocol == "" {
+		pool.Protocol = "TCP"
+	}
+	pool.SessionPersistence = req.body["session_persistence"]
+	if pool.SessionPersistence == "" {
+		pool.SessionPersistence = nil
+	}
+	return 0, nil
+}
+
+func (f *fakeBcc) serveLbaasPool(req *fakeRequest, lbaas *fakeLbaas) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 3):
+		// Pools are listed as a plain array, not as a page.
+		items := []interface{}{}
+		for _, pool := range fakeSorted(f.lbaasPools) {
+			if pool.Lbaas == lbaas.ID {
+				items = append(items, f.lbaasPoolJSON(pool))
+			}
+		}
+		return fakeOK(items)
+	case req.is(http.MethodPost, 3):
+		pool := &fakeLbaasPool{fakeBase: f.newBase(), Lbaas: lbaas.ID}
+		if status, resp := f.applyLbaasPool(pool, req); resp != nil {
+			return status, resp
+		}
+		for _, existing := range f.lbaasPools {
+			if existing.Lbaas == lbaas.ID && existing.Port == pool.Port {
+				return fakeBadRequest("port: pool for port %d already exists", pool.Port)
+			}
+		}
+		f.lbaasPools[pool.ID] = pool
+		f.touch(lbaas.ID)
+		return fakeCreated(f.lbaasPoolJSON(pool))
+	}
+
+	pool, ok := f.lbaasPools[req.parts[3]]
+	if !ok || pool.Lbaas != lbaas.ID || len(req.parts) != 4 {
+		return fakeNotFound()
+	}
+	switch req.method {
+	case http.MethodGet:
+		return f.withLock(pool.ID, f.lbaasPoolJSON(pool))
+	case http.MethodPut:
+		if status, resp := f.applyLbaasPool(pool, req); resp != nil {
+			return status, resp
+		}
+		f.touch(lbaas.ID)
+		return fakeOK(f.lbaasPoolJSON(pool))
+	case http.MethodDelete:
+		delete(f.lbaasPools, pool.ID)
+		f.touch(lbaas.ID)
+		return fakeNoContent()
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) dnsJSON(dns *fakeDns) map[string]interface{} {
+	var project interface{}
+	if p, ok := f.projects[dns.Project]; ok {
+		project = f.projectJSON(p)
+	}
+	return map[string]interface{}{
+		"id":      dns.ID,
+		"name":    dns.Name,
+		"project": project,
+		"tags":    fakeTags(dns.Tags),
+	}
+}
+
+func (f *fakeBcc) serveDns(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{}
+		for _, dns := range fakeSorted(f.dnses) {
+			if project := req.query.Get("project"); project != "" && project != dns.Project {
+				continue
+			}
+			items = append(items, f.dnsJSON(dns))
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 1):
+		if _, ok := f.projects[req.str("project")]; !ok {
+			return fakeBadRequest("project: unknown project %q", req.str("project"))
+		}
+		if !strings.HasSuffix(req.str("name"), ".") {
+			return fakeBadRequest("name: must end with a dot")
+		}
+		dns := &fakeDns{fakeBase: f.newBase(), Name: req.str("name"), Project: req.str("project"), Tags: req.strings("tags")}
+		f.dnses[dns.ID] = dns
+		return fakeCreated(f.dnsJSON(dns))
+	}
+
+	dns, ok := f.dnses[req.parts[1]]
+	if !ok {
+		return fakeNotFound()
+	}
+	switch {
+	case req.is(http.MethodGet, 2):
+		return f.withLock(dns.ID, f.dnsJSON(dns))
+	case req.is(http.MethodPut, 2):
+		dns.Tags = req.strings("tags")
+		f.touch(dns.ID)
+		return fakeOK(f.dnsJSON(dns))
+	case req.is(http.MethodDelete, 2):
+		for _, record := range f.dnsRecords {
+			if record.Dns == dns.ID {
+				delete(f.dnsRecords, record.ID)
+			}
+		}
+		delete(f.dnses, dns.ID)
+		return fakeNoContent()
+	case req.is(http.MethodGet, 3) && req.parts[2] == "dns_record":
+		items := []interface{}{}
+		for _, record := range fakeSorted(f.dnsRecords) {
+			if record.Dns == dns.ID {
+				items = append(items, f.dnsRecordJSON(record))
+			}
+		}
+		return fakePage(items, req.query)
+	case len(req.parts) >= 3 && req.parts[2] == "record":
+		return f.serveDnsRecord(req, dns)
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) dnsRecordJSON(record *fakeDnsRecord) map[string]interface{} {
+	return map[string]interface{}{
+		"id":       record.ID,
+		"data":     record.Data,
+		"flag":     record.Flag,
+		"host":     record.Host,
+		"port":     record.Port,
+		"priority": record.Priority,
+		"tag":      record.Tag,
+		"ttl":      record.Ttl,
+		"type":     record.Type,
+		"weight":   record.Weight,
+	}
+}
+
+func (f *fakeBcc) applyDnsRecord(record *fakeDnsRecord, dns *fakeDns, req *fakeRequest) (int, interface{}) {
+	switch req.str("type") {
+	case "A", "AAAA", "CAA", "CNAME", "MX", "NS", "SRV", "TXT":
+	default:
+		return fakeBadRequest("type: %q is not a valid choice", req.str("type"))
+	}
+	if !strings.HasSuffix(req.str("host"), dns.Name) {
+		return fakeBadRequest("host: must belong to %s", dns.Name)
+	}
+	record.Data = req.str("data")
+	record.Flag = req.int("flag")
+	record.Host = req.str("host")
+	record.Port = req.int("port")
+	record.Priority = req.int("priority")
+	record.Tag = req.str("tag")
+	record.Ttl = req.int("ttl")
+	record.Type = req.str("type")
+	record.Weight = req.int("weight")
+	return 0, nil
+}
+
+func (f *fakeBcc) serveDnsRecord(req *fakeRequest, dns *fakeDns) (int, interface{}) {
+	if req.is(http.MethodPost, 3) {
+		record := &fakeDnsRecord{fakeBase: f.newBase(), Dns: dns.ID}
+		if status, resp := f.applyDnsRecord(record, dns, req); resp != nil {
+			return status, resp
+		}
+		f.dnsRecords[record.ID] = record
+		return fakeCreated(f.dnsRecordJSON(record))
+	}
+
+	record, ok := f.dnsRecords[req.parts[3]]
+	if !ok || record.Dns != dns.ID || len(req.parts) != 4 {
+		return fakeNotFound()
+	}
+	switch req.method {
+	case http.MethodGet:
+		return f.withLock(record.ID, f.dnsRecordJSON(record))
+	case http.MethodPut:
+		if status, resp := f.applyDnsRecord(record, dns, req); resp != nil {
+			return status, resp
+		}
+		f.touch(record.ID)
+		return fakeOK(f.dnsRecordJSON(record))
+	case http.MethodDelete:
+		delete(f.dnsRecords, record.ID)
+		return fakeNoContent()
+	}
+	return fakeNotFound()
+}
@@ -0,0 +1,1189 @@
+package bcc_terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Well-known identifiers of the objects every fake API is seeded with.
+const (
+	fakeToken                = "fake-token"
+	fakeClientID             = "00000000-0000-4000-8000-c11e00000001"
+	fakeAccountID            = "00000000-0000-4000-8000-acc000000001"
+	fakePubKeyID             = "00000000-0000-4000-8000-0be000000001"
+	fakePubKeyName           = "terraform"
+	fakeVmwareHypervisorID   = "00000000-0000-4000-8000-0e0000000001"
+	fakeKvmHypervisorID      = "00000000-0000-4000-8000-0e0000000002"
+	fakeStorageProfileID     = "00000000-0000-4000-8000-5b0000000001"
+	fakeStorageProfileName   = "ssd"
+	fakeSataStorageProfileID = "00000000-0000-4000-8000-5b0000000002"
+	fakeTemplateID           = "00000000-0000-4000-8000-7e0000000001"
+	fakeTemplateName         = "Ubuntu 22.04"
+	fakePlatformID           = "00000000-0000-4000-8000-9f0000000001"
+	fakePlatformName         = "Intel Cascade Lake"
+	fakeKubernetesTemplateID = "00000000-0000-4000-8000-8e0000000001"
+	fakeKubernetesTplName    = "Kubernetes 1.27"
+	fakePaasTemplateID       = 1
+	fakePaasTemplateName     = "PostgreSQL"
+	fakeFloatingPrefix       = "203.0.113."
+	fakeDefaultNetworkName   = "Сеть"
+	fakeDefaultRouterName    = "Маршрутизатор"
+	fakeDefaultNetworkCIDR   = "10.0.1.0/24"
+	fakeObjectLockedAlias    = "object_locked"
+)
+
+// fakeBcc is an in-process, stateful stand-in for the BCC API. It keeps every
+// object in memory and answers in the shapes bcc-go expects, so the provider
+// can be driven end to end without a real cloud behind it.
+type fakeBcc struct {
+	mu     sync.Mutex
+	server *httptest.Server
+	seq    int
+
+	// lockPolls is how many times an object answers `locked: true` after it
+	// was written. bcc-go polls WaitLock once per second, so tests leave it at
+	// zero unless they are about locking.
+	lockPolls int
+	locks     map[string]int
+
+	hypervisors        []*fakeHypervisor
+	storageProfiles    []*fakeStorageProfile
+	templates          []*fakeTemplate
+	platforms          []*fakePlatform
+	kubernetesTemplate []*fakeKubernetesTemplate
+
+	projects       map[string]*fakeProject
+	vdcs           map[string]*fakeVdc
+	networks       map[string]*fakeNetwork
+	subnets        map[string]*fakeSubnet
+	ports          map[string]*fakePort
+	firewalls      map[string]*fakeFirewall
+	firewallRules  map[string]*fakeFirewallRule
+	disks          map[string]*fakeDisk
+	vms            map[string]*fakeVm
+	routers        map[string]*fakeRouter
+	floatings      map[string]*fakeFloating
+	lbaases        map[string]*fakeLbaas
+	lbaasPools     map[string]*fakeLbaasPool
+	dnses          map[string]*fakeDns
+	dnsRecords     map[string]*fakeDnsRecord
+	s3Storages     map[string]*fakeS3Storage
+	s3Buckets      map[string]*fakeS3Bucket
+	kubernetes     map[string]*fakeKubernetes
+	affinityGroups map[string]*fakeAffinityGroup
+	paasServices   map[string]*fakePaasService
+
+	// failures answers the next requests to "METHOD path" with an error.
+	failures map[string]*fakeFailure
+
+	// requests counts handled requests by "METHOD path", for assertions.
+	requests map[string]int
+
+	// vmStates records the state changes requested for every vm, and
+	// ignoreShutdown makes the guests ignore the `shutdown` state.
+	vmStates       map[string][]string
+	ignoreShutdown bool
+}
+
+type fakeFailure struct {
+	status     int
+	times      int
+	retryAfter string
+}
+
+type fakeBase struct {
+	ID  string
+	seq int
+}
+
+func (b *fakeBase) base() *fakeBase { return b }
+
+type fakeRecord interface {
+	base() *fakeBase
+}
+
+type fakeHypervisor struct {
+	ID   string
+	Name string
+	Type string
+}
+
+type fakeStorageProfile struct {
+	ID          string
+	Name        string
+	MaxDiskSize int
+}
+
+type fakeTemplate struct {
+	ID     string
+	Name   string
+	MinCpu int
+	MinRam float64
+	MinHdd int
+}
+
+type fakePlatform struct {
+	ID         string
+	Name       string
+	Hypervisor string
+}
+
+type fakeKubernetesTemplate struct {
+	ID         string
+	Name       string
+	MinNodeCpu int
+	MinNodeRam int
+	MinNodeHdd int
+}
+
+type fakeFloating struct {
+	fakeBase
+	IP string
+}
+
+type fakeProject struct {
+	fakeBase
+	Name   string
+	Client string
+	Tags   []string
+}
+
+type fakeVdc struct {
+	fakeBase
+	Name       string
+	Project    string
+	Hypervisor string
+	Paas       string
+	Tags       []string
+}
+
+// newFakeBcc starts a fake API for the duration of the test.
+func newFakeBcc(t testing.TB) *fakeBcc {
+	f := &fakeBcc{
+		locks:          map[string]int{},
+		projects:       map[string]*fakeProject{},
+		vdcs:           map[string]*fakeVdc{},
+		networks:       map[string]*fakeNetwork{},
+		subnets:        map[string]*fakeSubnet{},
+		ports:          map[string]*fakePort{},
+		firewalls:      map[string]*fakeFirewall{},
+		firewallRules:  map[string]*fakeFirewallRule{},
+		disks:          map[string]*fakeDisk{},
+		vms:            map[string]*fakeVm{},
+		routers:        map[string]*fakeRouter{},
+		floatings:      map[string]*fakeFloating{},
+		lbaases:        map[string]*fakeLbaas{},
+		lbaasPools:     map[string]*fakeLbaasPool{},
+		dnses:          map[string]*fakeDns{},
+		dnsRecords:     map[string]*fakeDnsRecord{},
+		s3Storages:     map[string]*fakeS3Storage{},
+		s3Buckets:      map[string]*fakeS3Bucket{},
+		kubernetes:     map[string]*fakeKubernetes{},
+		affinityGroups: map[string]*fakeAffinityGroup{},
+		paasServices:   map[string]*fakePaasService{},
+		failures:       map[string]*fakeFailure{},
+		requests:       map[string]int{},
+		vmStates:       map[string][]string{},
+	}
+	f.seed()
+	f.server = httptest.NewServer(f)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeBcc) seed() {
+	f.hypervisors = []*fakeHypervisor{
+		{ID: fakeVmwareHypervisorID, Name: "VMware", Type: "Vmware"},
+		{ID: fakeKvmHypervisorID, Name: "KVM", Type: "kvm"},
+	}
+	f.storageProfiles = []*fakeStorageProfile{
+		{ID: fakeStorageProfileID, Name: fakeStorageProfileName, MaxDiskSize: 2048},
+		{ID: fakeSataStorageProfileID, Name: "sata", MaxDiskSize: 4096},
+	}
+	f.templates = []*fakeTemplate{
+		{ID: fakeTemplateID, Name: fakeTemplateName, MinCpu: 1, MinRam: 1, MinHdd: 10},
+		{ID: "00000000-0000-4000-8000-7e0000000002", Name: "Debian 12", MinCpu: 1, MinRam: 0.5, MinHdd: 8},
+	}
+	f.platforms = []*fakePlatform{
+		{ID: fakePlatformID, Name: fakePlatformName, Hypervisor: fakeVmwareHypervisorID},
+		{ID: "00000000-0000-4000-8000-9f0000000002", Name: "KVM default", Hypervisor: fakeKvmHypervisorID},
+	}
+	f.kubernetesTemplate = []*fakeKubernetesTemplate{
+		{ID: fakeKubernetesTemplateID, Name: fakeKubernetesTplName, MinNodeCpu: 2, MinNodeRam: 2, MinNodeHdd: 20},
+	}
+}
+
+// URL is the base URL to put into the provider `api_endpoint`.
+func (f *fakeBcc) URL() string {
+	return f.server.URL
+}
+
+// SetLockPolls makes every object written from now on answer `locked: true`
+// to the given number of requests before it unlocks.
+func (f *fakeBcc) SetLockPolls(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lockPolls = n
+}
+
+// FailNext answers the next `times` requests to "METHOD path" with the given
+// status and Retry-After header, an empty retryAfter leaves the header out.
+func (f *fakeBcc) FailNext(method, path string, status, times int, retryAfter string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[method+" "+path] = &fakeFailure{status: status, times: times, retryAfter: retryAfter}
+}
+
+// Requests returns how many times "METHOD path" was requested.
+func (f *fakeBcc) Requests(method, path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[method+" "+path]
+}
+
+// VmStates returns the state changes requested for the vm, in order.
+func (f *fakeBcc) VmStates(id string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.vmStates[id]...)
+}
+
+// SetIgnoreShutdown makes the guests ignore the `shutdown` state, so only a
+// forced power-off stops them.
+func (f *fakeBcc) SetIgnoreShutdown(ignore bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ignoreShutdown = ignore
+}
+
+// DiskVm returns the id of the vm the disk is attached to, empty if none.
+func (f *fakeBcc) DiskVm(id string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if disk, ok := f.disks[id]; ok {
+		return disk.Vm
+	}
+	return ""
+}
+
+// PortDevice returns the id of the device the port is connected to.
+func (f *fakeBcc) PortDevice(id string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if port, ok := f.ports[id]; ok {
+		return port.Device
+	}
+	return ""
+}
+
+// DisconnectPort disconnects the port, as a change made outside of Terraform.
+func (f *fakeBcc) DisconnectPort(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if port, ok := f.ports[id]; ok {
+		f.disconnectPort(port)
+	}
+}
+
+// ConnectPort connects the port to the vm, as a change made outside of
+// Terraform, and returns the stamp of the connection.
+func (f *fakeBcc) ConnectPort(id string, vm string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	port := f.ports[id]
+	f.connectPort(port, vm, "vm_int")
+	return port.connected
+}
+
+// PortConnectedAt returns the stamp of the last connection of the port, it
+// changes whenever the port is connected again.
+func (f *fakeBcc) PortConnectedAt(id string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if port, ok := f.ports[id]; ok {
+		return port.connected
+	}
+	return 0
+}
+
+// PoolMembers returns the ids of the vms registered with the lbaas pool.
+func (f *fakeBcc) PoolMembers(id string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	vms := []string{}
+	if pool, ok := f.lbaasPools[id]; ok {
+		for _, member := range pool.Members {
+			vms = append(vms, member.Vm)
+		}
+	}
+	return vms
+}
+
+// RouterRoutes returns the routes of the router as "<destination> via <next hop>".
+func (f *fakeBcc) RouterRoutes(id string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	routes := []string{}
+	if router, ok := f.routers[id]; ok {
+		for _, route := range router.Routes {
+			routes = append(routes, route.Destination+" via "+route.NextHop)
+		}
+	}
+	return routes
+}
+
+// DefaultNetwork returns the name, the mtu and the dns servers of the first
+// subnet of the default network of the VDC.
+func (f *fakeBcc) DefaultNetwork(vdcId string) (name string, mtu *int, dns []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, network := range f.networks {
+		if network.Vdc == vdcId && network.IsDefault {
+			if subnets := f.networkSubnets(network.ID); len(subnets) > 0 {
+				dns = subnets[0].DNS
+			}
+			return network.Name, network.Mtu, dns
+		}
+	}
+	return "", nil, nil
+}
+
+// DefaultRouter returns the name of the default router of the VDC and whether
+// it has a floating ip.
+func (f *fakeBcc) DefaultRouter(vdcId string) (name string, floating bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, router := range f.routers {
+		if router.Vdc == vdcId && router.IsDefault {
+			return router.Name, router.Floating != ""
+		}
+	}
+	return "", false
+}
+
+// Exists reports whether an object of any kind with the given id is stored.
+func (f *fakeBcc) Exists(id string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return fakeHas(f.projects, id) || fakeHas(f.vdcs, id) || fakeHas(f.networks, id) ||
+		fakeHas(f.subnets, id) || fakeHas(f.ports, id) || fakeHas(f.firewalls, id) ||
+		fakeHas(f.firewallRules, id) || fakeHas(f.disks, id) || fakeHas(f.vms, id) ||
+		fakeHas(f.routers, id) || fakeHas(f.floatings, id) || fakeHas(f.lbaases, id) ||
+		fakeHas(f.lbaasPools, id) || fakeHas(f.dnses, id) || fakeHas(f.dnsRecords, id) ||
+		fakeHas(f.s3Storages, id) || fakeHas(f.s3Buckets, id) || fakeHas(f.kubernetes, id) ||
+		fakeHas(f.affinityGroups, id) || fakeHas(f.paasServices, id)
+}
+
+// AddVdc stores a project with a VMware VDC, for tests which need them to
+// exist before the provider is configured. It returns their ids.
+func (f *fakeBcc) AddVdc(name string) (projectId string, vdcId string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	project := &fakeProject{fakeBase: f.newBase(), Name: name, Client: fakeClientID}
+	f.projects[project.ID] = project
+	vdc := &fakeVdc{fakeBase: f.newBase(), Name: name, Project: project.ID, Hypervisor: fakeVmwareHypervisorID}
+	f.vdcs[vdc.ID] = vdc
+	f.createVdcDefaults(vdc)
+	return project.ID, vdc.ID
+}
+
+// AddTemplate adds a vm template with the given name and returns its id.
+func (f *fakeBcc) AddTemplate(name string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	template := &fakeTemplate{ID: f.newBase().ID, Name: name, MinCpu: 1, MinRam: 1, MinHdd: 10}
+	f.templates = append(f.templates, template)
+	return template.ID
+}
+
+func fakeHas[T any](objects map[string]T, id string) bool {
+	_, ok := objects[id]
+	return ok
+}
+
+func (f *fakeBcc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.Trim(r.URL.Path, "/")
+	f.requests[r.Method+" "+path]++
+
+	if failure := f.failures[r.Method+" "+path]; failure != nil && failure.times > 0 {
+		failure.times--
+		if failure.retryAfter != "" {
+			w.Header().Set("Retry-After", failure.retryAfter)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(failure.status)
+		json.NewEncoder(w).Encode(fakeErrorBody("fake_failure", http.StatusText(failure.status)))
+		return
+	}
+
+	status, resp := f.serve(r, path)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if resp != nil {
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (f *fakeBcc) serve(r *http.Request, path string) (int, interface{}) {
+	if r.Header.Get("Authorization") != "Bearer "+fakeToken {
+		return http.StatusUnauthorized, fakeErrorBody("not_authenticated", "Invalid token.")
+	}
+	if !strings.HasPrefix(path, "v1/") {
+		return fakeNotFound()
+	}
+	parts := strings.Split(strings.TrimPrefix(path, "v1/"), "/")
+
+	var body map[string]interface{}
+	if r.Body != nil {
+		data, _ := io.ReadAll(r.Body)
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &body); err != nil {
+				return fakeBadRequest("malformed json: %s", err)
+			}
+		}
+	}
+	if body == nil {
+		body = map[string]interface{}{}
+	}
+
+	// Writes to a locked object are refused the way the real API does it,
+	// bcc-go retries them until the lock is released.
+	if r.Method != http.MethodGet && len(parts) > 1 && f.isLocked(parts[1]) {
+		return http.StatusConflict, map[string]interface{}{
+			"error_alias":      []string{fakeObjectLockedAlias},
+			"non_field_errors": []string{"Object is locked"},
+			"details":          []interface{}{},
+		}
+	}
+
+	req := &fakeRequest{method: r.Method, parts: parts, query: r.URL.Query(), body: body}
+	switch parts[0] {
+	case "client":
+		return f.serveClient(req)
+	case "account":
+		return f.serveAccount(req)
+	case "project":
+		return f.serveProject(req)
+	case "vdc":
+		return f.serveVdc(req)
+	case "storage_profile":
+		return f.serveStorageProfile(req)
+	case "template":
+		return f.serveTemplate(req)
+	case "platform":
+		return f.servePlatform(req)
+	case "network":
+		return f.serveNetwork(req)
+	case "port":
+		return f.servePort(req)
+	case "firewall":
+		return f.serveFirewall(req)
+	case "router":
+		return f.serveRouter(req)
+	case "lbaas":
+		return f.serveLbaas(req)
+	case "dns":
+		return f.serveDns(req)
+	case "disk":
+		return f.serveDisk(req)
+	case "vm":
+		return f.serveVm(req)
+	case "kubernetes":
+		return f.serveKubernetes(req)
+	case "kubernetes_template":
+		return f.serveKubernetesTemplate(req)
+	case "affinity_groups":
+		return f.serveAffinityGroup(req)
+	case "s3_storage":
+		return f.serveS3Storage(req)
+	case "paas":
+		return f.servePaas(req)
+	case "paas_template":
+		return f.servePaasTemplate(req)
+	case "paas_service":
+		return f.servePaasService(req)
+	}
+	return fakeNotFound()
+}
+
+// fakeRequest is a decoded API call.
+type fakeRequest struct {
+	method string
+	parts  []string
+	query  url.Values
+	body   map[string]interface{}
+}
+
+// is matches the request method and the number of path segments.
+func (r *fakeRequest) is(method string, segments int) bool {
+	return r.method == method && len(r.parts) == segments
+}
+
+func (r *fakeRequest) str(key string) string {
+	if v, ok := r.body[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+func (r *fakeRequest) has(key string) bool {
+	_, ok := r.body[key]
+	return ok
+}
+
+func (r *fakeRequest) int(key string) int {
+	if v, ok := r.body[key].(float64); ok {
+		return int(v)
+	}
+	return 0
+}
+
+func (r *fakeRequest) intPtr(key string) *int {
+	if v, ok := r.body[key].(float64); ok {
+		i := int(v)
+		return &i
+	}
+	return nil
+}
+
+func (r *fakeRequest) float(key string) float64 {
+	if v, ok := r.body[key].(float64); ok {
+		return v
+	}
+	return 0
+}
+
+func (r *fakeRequest) bool(key string) bool {
+	v, _ := r.body[key].(bool)
+	return v
+}
+
+// ids reads a list of either plain ids or objects carrying an "id".
+func (r *fakeRequest) ids(key string) []string {
+	return fakeIds(r.body[key])
+}
+
+func (r *fakeRequest) strings(key string) []string {
+	list, _ := r.body[key].([]interface{})
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func fakeIds(v interface{}) []string {
+	list, _ := v.([]interface{})
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		switch item := item.(type) {
+		case string:
+			result = append(result, item)
+		case map[string]interface{}:
+			if id, ok := item["id"].(string); ok {
+				result = append(result, id)
+			}
+		}
+	}
+	return result
+}
+
+func fakeErrorBody(alias string, msg string) map[string]interface{} {
+	return map[string]interface{}{
+		"error_alias":      []string{alias},
+		"non_field_errors": []string{msg},
+	}
+}
+
+func fakeNotFound() (int, interface{}) {
+	return http.StatusNotFound, fakeErrorBody("not_found", "Not found.")
+}
+
+func fakeBadRequest(format string, args ...interface{}) (int, interface{}) {
+	return http.StatusBadRequest, fakeErrorBody("validation_error", fmt.Sprintf(format, args...))
+}
+
+func fakeOK(resp interface{}) (int, interface{}) {
+	return http.StatusOK, resp
+}
+
+func fakeCreated(resp interface{}) (int, interface{}) {
+	return http.StatusCreated, resp
+}
+
+func fakeNoContent() (int, interface{}) {
+	return http.StatusNoContent, nil
+}
+
+// fakePage wraps items into the paginated envelope GetItems walks through.
+func fakePage(items []interface{}, query url.Values) (int, interface{}) {
+	const limit = 20
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	start := (page - 1) * limit
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return fakeOK(map[string]interface{}{
+		"total": len(items),
+		"limit": limit,
+		"items": items[start:end],
+	})
+}
+
+func fakeSorted[T fakeRecord](objects map[string]T) []T {
+	list := make([]T, 0, len(objects))
+	for _, object := range objects {
+		list = append(list, object)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].base().seq < list[j].base().seq })
+	return list
+}
+
+func fakeNameMatches(query url.Values, name string) bool {
+	filter := query.Get("name")
+	return filter == "" || strings.Contains(strings.ToLower(name), strings.ToLower(filter))
+}
+
+func fakeTags(names []string) []interface{} {
+	tags := make([]interface{}, len(names))
+	for i, name := range names {
+		tags[i] = map[string]interface{}{"id": "tag-" + name, "name": name}
+	}
+	return tags
+}
+
+func (f *fakeBcc) newBase() fakeBase {
+	f.seq++
+	return fakeBase{
+		ID:  fmt.Sprintf("00000000-0000-4000-8000-%012d", f.seq),
+		seq: f.seq,
+	}
+}
+
+// touch marks the object as written, so it stays locked for lockPolls polls.
+func (f *fakeBcc) touch(id string) {
+	if f.lockPolls > 0 {
+		f.locks[id] = f.lockPolls
+	}
+}
+
+func (f *fakeBcc) isLocked(id string) bool {
+	if f.locks[id] > 0 {
+		f.locks[id]--
+		return true
+	}
+	return false
+}
+
+// withLock answers a direct GET of an object, consuming one lock poll.
+func (f *fakeBcc) withLock(id string, object map[string]interface{}) (int, interface{}) {
+	object["locked"] = f.isLocked(id)
+	return fakeOK(object)
+}
+
+// setFloating applies the `floating` field of a request to the current
+// floating ip: null releases it, RANDOM_FIP allocates one when missing.
+func (f *fakeBcc) setFloating(current string, req *fakeRequest) string {
+	if !req.has("floating") {
+		return current
+	}
+	requested := req.str("floating")
+	if requested == "" {
+		if current != "" {
+			delete(f.floatings, current)
+		}
+		return ""
+	}
+	if current != "" {
+		return current
+	}
+	if _, ok := f.floatings[requested]; ok {
+		return requested
+	}
+	base := f.newBase()
+	f.floatings[base.ID] = &fakeFloating{fakeBase: base, IP: fmt.Sprintf("%s%d", fakeFloatingPrefix, base.seq%250+2)}
+	return base.ID
+}
+
+func (f *fakeBcc) floatingJSON(id string) interface{} {
+	floating, ok := f.floatings[id]
+	if !ok {
+		return nil
+	}
+	return map[string]interface{}{"id": floating.ID, "ip_address": floating.IP}
+}
+
+func (f *fakeBcc) hypervisor(id string) *fakeHypervisor {
+	for _, hypervisor := range f.hypervisors {
+		if hypervisor.ID == id {
+			return hypervisor
+		}
+	}
+	return nil
+}
+
+func (f *fakeBcc) hypervisorJSON(id string) map[string]interface{} {
+	hypervisor := f.hypervisor(id)
+	if hypervisor == nil {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"id":               hypervisor.ID,
+		"name":             hypervisor.Name,
+		"type":             hypervisor.Type,
+		"cpu_per_vm":       32,
+		"ram_per_vm":       256,
+		"disks_per_vm":     16,
+		"ports_per_device": 10,
+	}
+}
+
+func (f *fakeBcc) serveClient(req *fakeRequest) (int, interface{}) {
+	client := map[string]interface{}{"id": fakeClientID, "name": "terraform", "payment_model": "prepay"}
+	switch {
+	case req.is(http.MethodGet, 1):
+		return fakePage([]interface{}{client}, req.query)
+	case req.is(http.MethodGet, 2) && req.parts[1] == fakeClientID:
+		return fakeOK(client)
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) serveAccount(req *fakeRequest) (int, interface{}) {
+	key := map[string]interface{}{
+		"id":          fakePubKeyID,
+		"name":        fakePubKeyName,
+		"fingerprint": "SHA256:fake",
+		"public_key":  "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIFakeKey terraform",
+	}
+	switch {
+	case req.is(http.MethodGet, 2) && req.parts[1] == "me":
+		return fakeOK(map[string]interface{}{"id": fakeAccountID, "email": "terraform@example.com", "username": "terraform"})
+	case req.is(http.MethodGet, 3) && req.parts[1] == fakeAccountID && req.parts[2] == "key":
+		return fakePage([]interface{}{key}, req.query)
+	case req.is(http.MethodGet, 4) && req.parts[1] == fakeAccountID && req.parts[3] == fakePubKeyID:
+		return fakeOK(key)
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) projectJSON(project *fakeProject) map[string]interface{} {
+	hypervisors := make([]interface{}, len(f.hypervisors))
+	for i, hypervisor := range f.hypervisors {
+		hypervisors[i] = f.hypervisorJSON(hypervisor.ID)
+	}
+	return map[string]interface{}{
+		"id":   project.ID,
+		"name": project.Name,
+		"client": map[string]interface{}{
+			"id":                  project.Client,
+			"allowed_hypervisors": hypervisors,
+		},
+		"locked": false,
+		"tags":   fakeTags(project.Tags),
+	}
+}
+
+func (f *fakeBcc) serveProject(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{}
+		for _, project := range fakeSorted(f.projects) {
+			if fakeNameMatches(req.query, project.Name) {
+				items = append(items, f.projectJSON(project))
+			}
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 1):
+		if req.str("name") == "" {
+			return fakeBadRequest("name: this field is required")
+		}
+		if req.str("client") != fakeClientID {
+			return fakeBadRequest("client: unknown client %q", req.str("client"))
+		}
+		project := &fakeProject{fakeBase: f.newBase(), Name: req.str("name"), Client: fakeClientID, Tags: req.strings("tags")}
+		f.projects[project.ID] = project
+		f.touch(project.ID)
+		return fakeCreated(f.projectJSON(project))
+	}
+
+	project, ok := f.projects[req.parts[1]]
+	if !ok {
+		return fakeNotFound()
+	}
+	switch {
+	case req.is(http.MethodGet, 2):
+		return f.withLock(project.ID, f.projectJSON(project))
+	case req.is(http.MethodPut, 2):
+		project.Name = req.str("name")
+		project.Tags = req.strings("tags")
+		f.touch(project.ID)
+		return fakeOK(f.projectJSON(project))
+	case req.is(http.MethodDelete, 2):
+		for _, vdc := range f.vdcs {
+			if vdc.Project == project.ID {
+				return fakeBadRequest("project has vdcs")
+			}
+		}
+		delete(f.projects, project.ID)
+		return fakeNoContent()
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) vdcJSON(vdc *fakeVdc) map[string]interface{} {
+	var paas interface{}
+	if vdc.Paas != "" {
+		paas = map[string]interface{}{"id": vdc.Paas, "locked": false}
+	}
+	projectName := ""
+	if project, ok := f.projects[vdc.Project]; ok {
+		projectName = project.Name
+	}
+	return map[string]interface{}{
+		"id":         vdc.ID,
+		"name":       vdc.Name,
+		"locked":     false,
+		"hypervisor": f.hypervisorJSON(vdc.Hypervisor),
+		"paas":       paas,
+		"project":    map[string]interface{}{"id": vdc.Project, "name": projectName},
+		"tags":       fakeTags(vdc.Tags),
+	}
+}
+
+func (f *fakeBcc) vdcRef(id string) map[string]interface{} {
+	vdc, ok := f.vdcs[id]
+	if !ok {
+		return nil
+	}
+	return f.vdcJSON(vdc)
+}
+
+func (f *fakeBcc) serveVdc(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{}
+		for _, vdc := range fakeSorted(f.vdcs) {
+			if project := req.query.Get("project"); project != "" && project != vdc.Project {
+				continue
+			}
+			if fakeNameMatches(req.query, vdc.Name) {
+				items = append(items, f.vdcJSON(vdc))
+			}
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodPost, 1):
+		if req.str("name") == "" {
+			return fakeBadRequest("name: this field is required")
+		}
+		if _, ok := f.projects[req.str("project")]; !ok {
+			return fakeBadRequest("project: unknown project %q", req.str("project"))
+		}
+		if f.hypervisor(req.str("hypervisor")) == nil {
+			return fakeBadRequest("hypervisor: unknown hypervisor %q", req.str("hypervisor"))
+		}
+		vdc := &fakeVdc{
+			fakeBase:   f.newBase(),
+			Name:       req.str("name"),
+			Project:    req.str("project"),
+			Hypervisor: req.str("hypervisor"),
+			Tags:       req.strings("tags"),
+		}
+		f.vdcs[vdc.ID] = vdc
+		f.createVdcDefaults(vdc)
+		f.touch(vdc.ID)
+		return fakeCreated(f.vdcJSON(vdc))
+	}
+
+	vdc, ok := f.vdcs[req.parts[1]]
+	if !ok {
+		return fakeNotFound()
+	}
+	switch {
+	case req.is(http.MethodGet, 2):
+		return f.withLock(vdc.ID, f.vdcJSON(vdc))
+	case req.is(http.MethodPut, 2):
+		vdc.Name = req.str("name")
+		vdc.Tags = req.strings("tags")
+		f.touch(vdc.ID)
+		return fakeOK(f.vdcJSON(vdc))
+	case req.is(http.MethodDelete, 2):
+		f.deleteVdc(vdc)
+		return fakeNoContent()
+	}
+	return fakeNotFound()
+}
+
+// createVdcDefaults creates the network and the router every new VDC gets.
+func (f *fakeBcc) createVdcDefaults(vdc *fakeVdc) {
+	network := &fakeNetwork{fakeBase: f.newBase(), Name: fakeDefaultNetworkName, Vdc: vdc.ID, IsDefault: true}
+	f.networks[network.ID] = network
+	subnet := &fakeSubnet{
+		fakeBase: f.newBase(),
+		Network:  network.ID,
+		CIDR:     fakeDefaultNetworkCIDR,
+		Gateway:  "10.0.1.1",
+		StartIp:  "10.0.1.2",
+		EndIp:    "10.0.1.254",
+		DHCP:     true,
+		DNS:      []string{"8.8.8.8", "8.8.4.4"},
+	}
+	f.subnets[subnet.ID] = subnet
+
+	router := &fakeRouter{fakeBase: f.newBase(), Name: fakeDefaultRouterName, Vdc: vdc.ID, IsDefault: true}
+	f.routers[router.ID] = router
+	router.Floating = f.setFloating("", &fakeRequest{body: map[string]interface{}{"floating": "RANDOM_FIP"}})
+	port := &fakePort{fakeBase: f.newBase(), Vdc: vdc.ID, Network: network.ID, IP: subnet.Gateway}
+	f.ports[port.ID] = port
+	f.connectPort(port, router.ID, "router")
+}
+
+// deleteVdc removes the VDC together with everything that still lives in it.
+func (f *fakeBcc) deleteVdc(vdc *fakeVdc) {
+	for _, k := range f.kubernetes {
+		if k.Vdc == vdc.ID {
+			f.deleteKubernetes(k)
+		}
+	}
+	for _, lbaas := range f.lbaases {
+		if lbaas.Vdc == vdc.ID {
+			f.deleteLbaas(lbaas)
+		}
+	}
+	for _, vm := range f.vms {
+		if vm.Vdc == vdc.ID {
+			f.deleteVm(vm)
+		}
+	}
+	for _, disk := range f.disks {
+		if disk.Vdc == vdc.ID {
+			delete(f.disks, disk.ID)
+		}
+	}
+	for _, router := range f.routers {
+		if router.Vdc == vdc.ID {
+			f.deleteRouter(router)
+		}
+	}
+	for _, port := range f.ports {
+		if port.Vdc == vdc.ID {
+			delete(f.ports, port.ID)
+		}
+	}
+	for _, network := range f.networks {
+		if network.Vdc == vdc.ID {
+			f.deleteNetwork(network)
+		}
+	}
+	for _, firewall := range f.firewalls {
+		if firewall.Vdc == vdc.ID {
+			f.deleteFirewall(firewall)
+		}
+	}
+	for _, group := range f.affinityGroups {
+		if group.Vdc == vdc.ID {
+			delete(f.affinityGroups, group.ID)
+		}
+	}
+	for _, service := range f.paasServices {
+		if service.Vdc == vdc.ID {
+			delete(f.paasServices, service.ID)
+		}
+	}
+	delete(f.vdcs, vdc.ID)
+}
+
+func (f *fakeBcc) storageProfile(id string) *fakeStorageProfile {
+	for _, profile := range f.storageProfiles {
+		if profile.ID == id {
+			return profile
+		}
+	}
+	return nil
+}
+
+func (f *fakeBcc) storageProfileJSON(id string) map[string]interface{} {
+	profile := f.storageProfile(id)
+	if profile == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"id":            profile.ID,
+		"name":          profile.Name,
+		"max_disk_size": profile.MaxDiskSize,
+		"enabled":       true,
+	}
+}
+
+func (f *fakeBcc) serveStorageProfile(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		items := []interface{}{}
+		for _, profile := range f.storageProfiles {
+			items = append(items, f.storageProfileJSON(profile.ID))
+		}
+		return fakePage(items, req.query)
+	case req.is(http.MethodGet, 2):
+		if profile := f.storageProfileJSON(req.parts[1]); profile != nil {
+			return fakeOK(profile)
+		}
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) template(id string) *fakeTemplate {
+	for _, template := range f.templates {
+		if template.ID == id {
+			return template
+		}
+	}
+	return nil
+}
+
+func (f *fakeBcc) templateJSON(id string) map[string]interface{} {
+	template := f.template(id)
+	if template == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"id":      template.ID,
+		"name":    template.Name,
+		"min_cpu": template.MinCpu,
+		"min_ram": template.MinRam,
+		"min_hdd": template.MinHdd,
+	}
+}
+
+func (f *fakeBcc) serveTemplate(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		// Templates are listed as a plain array, not as a page.
+		items := []interface{}{}
+		for _, template := range f.templates {
+			items = append(items, f.templateJSON(template.ID))
+		}
+		return fakeOK(items)
+	case req.is(http.MethodGet, 2):
+		if template := f.templateJSON(req.parts[1]); template != nil {
+			return fakeOK(template)
+		}
+	case req.is(http.MethodGet, 3) && req.parts[2] == "field":
+		if f.template(req.parts[1]) != nil {
+			return fakeOK([]interface{}{})
+		}
+	}
+	return fakeNotFound()
+}
+
+func (f *fakeBcc) platformJSON(id string) map[string]interface{} {
+	for _, platform := range f.platforms {
+		if platform.ID == id {
+			return map[string]interface{}{
+				"id":         platform.ID,
+				"name":       platform.Name,
+				"hypervisor": f.hypervisorJSON(platform.Hypervisor),
+			}
+		}
+	}
+	return nil
+}
+
+// defaultPlatform is the platform the API picks when none is requested.
+func (f *fakeBcc) defaultPlatform(vdcId string) string {
+	vdc, ok := f.vdcs[vdcId]
+	if !ok {
+		return ""
+	}
+	for _, platform := range f.platforms {
+		if platform.Hypervisor == vdc.Hypervisor {
+			return platform.ID
+		}
+	}
+	return ""
+}
+
+func (f *fakeBcc) servePlatform(req *fakeRequest) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		// Platforms are listed as a plain array, not as a page.
+		items := []interface{}{}
+		vdc := f.vdcs[req.query.Get("vdc")]
+		for _, platform := range f.platforms {
+			if vdc == nil || vdc.Hypervisor == platform.Hypervisor {
+				items = append(items, f.platformJSON(platform.ID))
+			}
+		}
+		return fakeOK(items)
+	case req.is(http.MethodGet, 2):
+		if platform := f.platformJSON(req.parts[1]); platform != nil {
+			return fakeOK(platform)
+		}
+	}
+	return fakeNotFound()
+}
+
+// allocateIp returns a free address of the network, or validates the
+// requested one.
+func (f *fakeBcc) allocateIp(networkId string, requested string, portId string) (string, error) {
+	used := map[string]bool{}
+	for _, port := range f.ports {
+		if port.Network == networkId && port.ID != portId {
+			used[port.IP] = true
+		}
+	}
+	subnets := f.networkSubnets(networkId)
+	if requested != "" && requested != "0.0.0.0" {
+		addr, err := netip.ParseAddr(requested)
+		if err != nil {
+			return "", fmt.Errorf("ip_address: %q is not a valid ip", requested)
+		}
+		if used[requested] {
+			return "", fmt.Errorf("ip_address: %s is already in use", requested)
+		}
+		for _, subnet := range subnets {
+			if prefix, err := netip.ParsePrefix(subnet.CIDR); err == nil && prefix.Contains(addr) {
+				return requested, nil
+			}
+		}
+		return "", fmt.Errorf("ip_address: %s is out of the network subnets", requested)
+	}
+	for _, subnet := range subnets {
+		start, err := netip.ParseAddr(subnet.StartIp)
+		if err != nil {
+			continue
+		}
+		end, err := netip.ParseAddr(subnet.EndIp)
+		if err != nil {
+			continue
+		}
+		for addr := start; addr.Compare(end) <= 0; addr = addr.Next() {
+			if !used[addr.String()] && addr.String() != subnet.Gateway {
+				return addr.String(), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("network %s has no free addresses", networkId)
+}
@@ -102,7 +102,8 @@ func (args *Arguments) injectContextResourceFirewallTemplate() {
 			Computed:    true,
 			Description: "number of rules in the firewall template",
 		},
-		"tags": newTagNamesResourceSchema("tags of the firewall template"),
+		"tags":     newTagNamesResourceSchema("tags of the firewall template"),
+		"tags_all": newTagNamesDataSchema("tags of the firewall template including the provider default tags"),
 	})
 }
 
@@ -106,7 +106,8 @@ func (args *Arguments) injectContextResourceK8s() {
 			Computed:    true,
 			Description: "Kubernetes dashboard url",
 		},
-		"tags": newTagNamesResourceSchema("tags of the Kubernetes"),
+		"tags":     newTagNamesResourceSchema("tags of the Kubernetes"),
+		"tags_all": newTagNamesDataSchema("tags of the Kubernetes including the provider default tags"),
 	})
 }
 
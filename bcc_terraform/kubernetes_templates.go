@@ -1,14 +1,25 @@
 package bcc_terraform
 
-import "github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
 
 func (args *Arguments) injectContextKubernetesTemplateById() {
 	args.merge(Arguments{
 		"template_id": {
-			Type:        schema.TypeString,
-			ForceNew:    true,
-			Required:    true,
-			Description: "id of the Kubernetes Template",
+			Type:         schema.TypeString,
+			ForceNew:     true,
+			Optional:     true,
+			Computed:     true,
+			ExactlyOneOf: []string{"template_id", "template_name"},
+			Description:  "id of the Kubernetes Template",
+		},
+		"template_name": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringIsNotWhiteSpace,
+			Description:  "name of the Kubernetes Template, looked up in the VDC instead of `template_id`",
 		},
 	})
 }
@@ -28,6 +39,7 @@ func (args *Arguments) injectContextGetKubernetesTemplate() {
 			Description: "Kubernetes Template identifier",
 		},
 	})
+	args.injectContextTemplateFilter()
 }
 
 func (args *Arguments) injectResultKubernetesTemplate() {
@@ -64,12 +64,18 @@ func (args *Arguments) injectContextResourceLbaasPool() {
 		},
 		"member": {
 			Type:     schema.TypeList,
-			Required: true,
+			Optional: true,
 			Elem: &schema.Resource{
 				Schema: poolMembers,
 			},
 			Description: "Lbaas members.",
 		},
+		"exclusive_members": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "whether `member` lists every member of the pool, disable it to register Vms with basis_vm_group",
+		},
 	})
 }
 
@@ -52,6 +52,10 @@ func (args *Arguments) injectLbaasPort() {
 			Optional:    true,
 			Default:     "",
 			Description: "ip_address of the Port",
+			ValidateFunc: validation.Any(
+				validation.StringIsEmpty,
+				validation.IsIPAddress,
+			),
 			DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 				return new == ""
 			},
@@ -115,7 +119,8 @@ func (args *Arguments) injectContextResourceLbaas() {
 			Computed:    true,
 			Description: "floating ip for the Lbaas. May be comitted",
 		},
-		"tags": newTagNamesResourceSchema("tags of the Lbaas"),
+		"tags":     newTagNamesResourceSchema("tags of the Lbaas"),
+		"tags_all": newTagNamesDataSchema("tags of the Lbaas including the provider default tags"),
 	})
 }
 
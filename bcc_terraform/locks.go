@@ -0,0 +1,87 @@
+package bcc_terraform
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// objectLocks serializes mutating API calls on the same parent object, such
+// as creating networks and disks in one VDC, while calls on different objects
+// still run concurrently.
+type objectLocks struct {
+	mu    sync.Mutex
+	locks map[string]*objectLock
+}
+
+type objectLock struct {
+	held chan struct{}
+	refs int
+}
+
+func newObjectLocks() *objectLocks {
+	return &objectLocks{locks: make(map[string]*objectLock)}
+}
+
+// lock waits until the key is free or ctx is done. The returned function
+// releases the key.
+func (l *objectLocks) lock(ctx context.Context, key string) (func(), error) {
+	l.mu.Lock()
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &objectLock{held: make(chan struct{}, 1)}
+		l.locks[key] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	select {
+	case lock.held <- struct{}{}:
+	case <-ctx.Done():
+		l.release(key, lock)
+		return nil, contextError(ctx)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-lock.held
+			l.release(key, lock)
+		})
+	}, nil
+}
+
+func (l *objectLocks) release(key string, lock *objectLock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lock.refs--
+	if lock.refs == 0 {
+		delete(l.locks, key)
+	}
+}
+
+// lockObject serializes the calls on the object of the given kind, e.g. "vdc",
+// "router" or "vm", with the other resources of this provider.
+func lockObject(ctx context.Context, meta interface{}, kind string, id string) (func(), error) {
+	config, ok := meta.(*CombinedConfig)
+	if !ok || config.locks == nil {
+		return func() {}, nil
+	}
+
+	ctx = subsystemLogContext(ctx, waitSubsystem)
+	fields := map[string]interface{}{"kind": kind, "id": id}
+	tflog.SubsystemTrace(ctx, waitSubsystem, "Waiting for object lock", fields)
+
+	start := time.Now()
+	unlock, err := config.locks.lock(ctx, kind+"/"+id)
+	fields["waited_ms"] = time.Since(start).Milliseconds()
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.SubsystemDebug(ctx, waitSubsystem, "Waiting for object lock failed", fields)
+		return nil, err
+	}
+	tflog.SubsystemDebug(ctx, waitSubsystem, "Acquired object lock", fields)
+	return unlock, nil
+}
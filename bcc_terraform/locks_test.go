@@ -0,0 +1,83 @@
+package bcc_terraform
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestObjectLocks_serializesSameKey(t *testing.T) {
+	locks := newObjectLocks()
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := locks.lock(context.Background(), "vdc/1")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer unlock()
+
+			current := atomic.AddInt32(&running, 1)
+			for {
+				seen := atomic.LoadInt32(&maxRunning)
+				if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+
+	if maxRunning != 1 {
+		t.Errorf("%d calls ran at once on the same object, want 1", maxRunning)
+	}
+	if len(locks.locks) != 0 {
+		t.Errorf("%d locks left after release, want 0", len(locks.locks))
+	}
+}
+
+func TestObjectLocks_otherKeysRunConcurrently(t *testing.T) {
+	locks := newObjectLocks()
+	unlock, err := locks.lock(context.Background(), "vdc/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	other, err := locks.lock(ctx, "vdc/2")
+	if err != nil {
+		t.Fatalf("lock on another object must not wait: %s", err)
+	}
+	other()
+}
+
+func TestObjectLocks_honorsContext(t *testing.T) {
+	locks := newObjectLocks()
+	unlock, err := locks.lock(context.Background(), "router/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := locks.lock(ctx, "router/1"); err == nil {
+		t.Fatal("lock must fail once the context is done")
+	}
+
+	unlock()
+	unlock()
+	if len(locks.locks) != 0 {
+		t.Errorf("%d locks left after release, want 0", len(locks.locks))
+	}
+}
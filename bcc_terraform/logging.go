@@ -0,0 +1,128 @@
+package bcc_terraform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Log subsystems of the provider. Their level follows TF_LOG_PROVIDER and can
+// be overridden with TF_LOG_PROVIDER_BCC_API and TF_LOG_PROVIDER_BCC_WAIT.
+const (
+	apiSubsystem  = "bcc_api"
+	waitSubsystem = "bcc_wait"
+)
+
+// redactedLogFields are never written to the logs, neither as log fields nor
+// inside request and response bodies.
+var redactedLogFields = []string{"token", "secret_key", "access_key", "user_data"}
+
+const redactedValue = "***"
+
+// subsystemLogContext returns ctx with the given log subsystem set up.
+func subsystemLogContext(ctx context.Context, subsystem string) context.Context {
+	ctx = tflog.NewSubsystem(ctx, subsystem, tflog.WithLevelFromEnv("TF_LOG_PROVIDER", subsystem))
+	return tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, subsystem, redactedLogFields...)
+}
+
+// redactBody returns the body for the logs with the values of redactedLogFields
+// replaced. Bodies which are not JSON are left out, they may be kubeconfigs.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Sprintf("(%d bytes, not JSON)", len(body))
+	}
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return fmt.Sprintf("(%d bytes)", len(body))
+	}
+	return string(redacted)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isRedactedLogField(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+func isRedactedLogField(key string) bool {
+	for _, field := range redactedLogFields {
+		if key == field {
+			return true
+		}
+	}
+	return false
+}
+
+// loggingTransport logs every API request with its status and latency to the
+// bcc_api subsystem, bodies are logged at the trace level.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := subsystemLogContext(req.Context(), apiSubsystem)
+	fields := map[string]interface{}{
+		"method": req.Method,
+		"path":   req.URL.Path,
+	}
+
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			content, _ := io.ReadAll(body)
+			body.Close()
+			tflog.SubsystemTrace(ctx, apiSubsystem, "API request body", map[string]interface{}{
+				"method": req.Method,
+				"path":   req.URL.Path,
+				"body":   redactBody(content),
+			})
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	fields["latency_ms"] = time.Since(start).Milliseconds()
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.SubsystemDebug(ctx, apiSubsystem, "API request failed", fields)
+		return resp, err
+	}
+	fields["status"] = resp.StatusCode
+	tflog.SubsystemDebug(ctx, apiSubsystem, "API request", fields)
+
+	content, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(content))
+	tflog.SubsystemTrace(ctx, apiSubsystem, "API response body", map[string]interface{}{
+		"method": req.Method,
+		"path":   req.URL.Path,
+		"status": resp.StatusCode,
+		"body":   redactBody(content),
+	})
+
+	return resp, nil
+}
@@ -0,0 +1,48 @@
+package bcc_terraform
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	cases := []struct {
+		body string
+		want string
+	}{
+		{``, ``},
+		{`{"name":"vm"}`, `{"name":"vm"}`},
+		{`{"name":"s3","secret_key":"s","access_key":"a"}`, `{"access_key":"***","name":"s3","secret_key":"***"}`},
+		{`{"results":[{"token":"t","id":"1"}]}`, `{"results":[{"id":"1","token":"***"}]}`},
+		{`{"metadata":{"user_data":"#cloud-config"}}`, `{"metadata":{"user_data":"***"}}`},
+		{`apiVersion: v1`, `(14 bytes, not JSON)`},
+	}
+	for _, c := range cases {
+		if got := redactBody([]byte(c.body)); got != c.want {
+			t.Errorf("redactBody(%s) = %s, want %s", c.body, got, c.want)
+		}
+	}
+}
+
+func TestLoggingTransport_keepsBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &loggingTransport{next: http.DefaultTransport}}
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"secret_key":"s"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != `{"secret_key":"s"}` {
+		t.Errorf("body = %s, the transport must not change it", body)
+	}
+}
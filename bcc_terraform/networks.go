@@ -1,11 +1,12 @@
 package bcc_terraform
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"sort"
 
 	"github.com/basis-cloud/bcc-go/bcc"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
@@ -28,6 +29,12 @@ func (args *Arguments) injectContextGetNetwork() {
 }
 
 func (args *Arguments) injectContextResourceNetwork() {
+	subnet := subnetArguments()
+	subnet["id"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "id of the Subnet",
+	}
 
 	args.merge(Arguments{
 		"name": {
@@ -41,68 +48,17 @@ func (args *Arguments) injectContextResourceNetwork() {
 		},
 		"subnets": {
 			Type:     schema.TypeList,
-			Required: true,
-			MinItems: 1,
-			MaxItems: 1, //  doesn't support several subnets
+			Optional: true,
 			Elem: &schema.Resource{
-				Schema: map[string]*schema.Schema{
-					"id": {
-						Type:        schema.TypeString,
-						Computed:    true,
-						Description: "id of the Subnet",
-					},
-					"cidr": {
-						Type:        schema.TypeString,
-						ForceNew:    true,
-						Required:    true,
-						Description: "cidr of the Subnet",
-						ValidateFunc: validation.All(
-							validation.NoZeroValues,
-							validation.StringLenBetween(1, 100),
-						),
-					},
-					"gateway": {
-						Type:        schema.TypeString,
-						Required:    true,
-						Description: "gateway of the Subnet",
-						ValidateFunc: validation.All(
-							validation.NoZeroValues,
-							validation.StringLenBetween(1, 100),
-						),
-					},
-					"start_ip": {
-						Type:        schema.TypeString,
-						Required:    true,
-						Description: "pool start ip of the Subnet",
-						ValidateFunc: validation.All(
-							validation.NoZeroValues,
-							validation.StringLenBetween(1, 100),
-						),
-					},
-					"end_ip": {
-						Type:        schema.TypeString,
-						Required:    true,
-						Description: "pool end ip of the Subnet",
-						ValidateFunc: validation.All(
-							validation.NoZeroValues,
-							validation.StringLenBetween(1, 100),
-						),
-					},
-					"dhcp": {
-						Type:        schema.TypeBool,
-						Required:    true,
-						Description: "enable dhcp service of the Subnet",
-					},
-					"dns": {
-						Type:     schema.TypeList,
-						Optional: true,
-						Elem: &schema.Schema{
-							Type: schema.TypeString,
-						},
-						Description: "dns servers list",
-					},
-				},
+				Schema: subnet,
 			},
+			Description: "Subnets of the Network, a changed cidr replaces only that Subnet",
+		},
+		"exclusive_subnets": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "whether `subnets` lists every subnet of the Network, disable it to add subnets with basis_subnet",
 		},
 		"mtu": {
 			Type:     schema.TypeInt,
@@ -114,10 +70,124 @@ func (args *Arguments) injectContextResourceNetwork() {
 			Computed:    true,
 			Description: "whether the network is external",
 		},
-		"tags": newTagNamesResourceSchema("tags of the Network"),
+		"tags":     newTagNamesResourceSchema("tags of the Network"),
+		"tags_all": newTagNamesDataSchema("tags of the Network including the provider default tags"),
+	})
+}
+
+func (args *Arguments) injectContextResourceDefaultNetwork() {
+	data := Defaults()
+	data.injectContextDataNetwork()
+
+	args.merge(Arguments{
+		"name": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+			ValidateFunc: validation.All(
+				validation.NoZeroValues,
+				validation.StringLenBetween(1, 100),
+			),
+			Description: "name of the Network",
+		},
+		"mtu": {
+			Type:        schema.TypeInt,
+			Optional:    true,
+			Computed:    true,
+			Description: "maximum transmission unit (MTU) of packets in the network",
+		},
+		"dns": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Computed: true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.IsIPAddress,
+			},
+			Description: "dns servers list of the subnets of the Network",
+		},
+		"subnets": data["subnets"],
+		"initial_name": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "name of the Network when it was adopted, restored on destroy",
+		},
+		"initial_mtu": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "mtu of the Network when it was adopted, restored on destroy",
+		},
+		"initial_dns": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "dns servers of the Network when it was adopted, restored on destroy",
+		},
+	})
+}
+
+func (args *Arguments) injectContextResourceSubnet() {
+	subnet := subnetArguments()
+	subnet["cidr"].ForceNew = true
+	subnet["gateway"].ForceNew = true
+
+	args.merge(subnet)
+	args.merge(Arguments{
+		"network_id": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.IsUUID,
+			Description:  "id of the Network",
+		},
 	})
 }
 
+// subnetArguments are the settings of a Subnet, shared by the `subnets`
+// blocks of basis_network and by basis_subnet.
+func subnetArguments() Arguments {
+	return Arguments{
+		"cidr": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "cidr of the Subnet",
+			ValidateFunc: validateCidr,
+		},
+		"gateway": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "gateway of the Subnet, inside the cidr and outside of the pool",
+			ValidateFunc: validation.IsIPAddress,
+		},
+		"start_ip": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "pool start ip of the Subnet",
+			ValidateFunc: validation.IsIPAddress,
+		},
+		"end_ip": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "pool end ip of the Subnet",
+			ValidateFunc: validation.IsIPAddress,
+		},
+		"dhcp": {
+			Type:        schema.TypeBool,
+			Required:    true,
+			Description: "enable dhcp service of the Subnet",
+		},
+		"dns": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.IsIPAddress,
+			},
+			Description: "dns servers list",
+		},
+	}
+}
+
 func (args *Arguments) injectContextDataNetwork() {
 	args.merge(Arguments{
 		"id": {
@@ -204,122 +274,209 @@ func (args *Arguments) injectContextDataNetworkList() {
 	})
 }
 
-func createSubnet(d *schema.ResourceData, manager *bcc.Manager, network *bcc.Network) (err error) {
-	subnets := d.Get("subnets").([]interface{})
-	log.Printf("[DEBUG] subnets: %#v", subnets)
-
-	for _, subnetInfo := range subnets {
-		log.Printf("[DEBUG] subnetInfo: %#v", subnetInfo)
-		subnetInfo2 := subnetInfo.(map[string]interface{})
-
-		// Create subnet
-		subnet := bcc.NewSubnet(subnetInfo2["cidr"].(string), subnetInfo2["gateway"].(string),
-			subnetInfo2["start_ip"].(string), subnetInfo2["end_ip"].(string), subnetInfo2["dhcp"].(bool))
-		if err = network.CreateSubnet(&subnet); err != nil {
-			return fmt.Errorf("[ERROR-009]: %s", err)
+// createSubnets creates the subnets listed in `subnets`.
+func createSubnets(ctx context.Context, d *schema.ResourceData, network *bcc.Network) error {
+	for _, item := range d.Get("subnets").([]interface{}) {
+		if _, err := createSubnet(ctx, network, item.(map[string]interface{})); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		dnsServersList := subnetInfo2["dns"].([]interface{})
-		if len(dnsServersList) > 0 {
-			dnsServers := make([]*bcc.SubnetDNSServer, len(dnsServersList))
-			for i, dns := range dnsServersList {
-				s1 := bcc.NewSubnetDNSServer(dns.(string))
-				dnsServers[i] = &s1
-			}
+func createSubnet(ctx context.Context, network *bcc.Network, args map[string]interface{}) (*bcc.Subnet, error) {
+	tflog.Debug(ctx, "Creating subnet", map[string]interface{}{"network_id": network.ID, "cidr": args["cidr"]})
 
-			if err = subnet.UpdateDNSServers(dnsServers); err != nil {
-				return fmt.Errorf("[ERROR-009]: %s", err)
-			}
+	subnet := bcc.NewSubnet(args["cidr"].(string), args["gateway"].(string),
+		args["start_ip"].(string), args["end_ip"].(string), args["dhcp"].(bool))
+	if err := network.CreateSubnet(&subnet); err != nil {
+		return nil, fmt.Errorf("crash via creating subnet %s: %s", subnet.CIDR, err)
+	}
+
+	dnsServers := expandSubnetDnsServers(args["dns"].([]interface{}))
+	if len(dnsServers) > 0 {
+		if err := subnet.UpdateDNSServers(dnsServers); err != nil {
+			return nil, fmt.Errorf("crash via setting dns of subnet %s: %s", subnet.CIDR, err)
 		}
+	}
+	return &subnet, nil
+}
 
+// updateSubnet applies the pool, dhcp and dns settings to the subnet if they
+// differ. The cidr and the gateway can't be changed.
+func updateSubnet(ctx context.Context, subnet *bcc.Subnet, args map[string]interface{}) error {
+	if subnet.Gateway != args["gateway"].(string) {
+		return fmt.Errorf("subnet %s: the gateway can't be changed from %s to %s", subnet.CIDR, subnet.Gateway, args["gateway"])
 	}
 
-	return
+	dnsServers := expandSubnetDnsServers(args["dns"].([]interface{}))
+	shouldUpdate := subnet.StartIp != args["start_ip"].(string) || subnet.EndIp != args["end_ip"].(string) ||
+		subnet.IsDHCP != args["dhcp"].(bool) || len(subnet.DnsServers) != len(dnsServers)
+	for i := 0; !shouldUpdate && i < len(dnsServers); i++ {
+		shouldUpdate = subnet.DnsServers[i].DNSServer != dnsServers[i].DNSServer
+	}
+	if !shouldUpdate {
+		return nil
+	}
+
+	tflog.Debug(ctx, "Updating subnet", map[string]interface{}{"id": subnet.ID, "cidr": subnet.CIDR})
+	subnet.StartIp = args["start_ip"].(string)
+	subnet.EndIp = args["end_ip"].(string)
+	subnet.IsDHCP = args["dhcp"].(bool)
+	if err := subnet.UpdateDNSServers(dnsServers); err != nil {
+		return fmt.Errorf("crash via updating subnet %s: %s", subnet.CIDR, err)
+	}
+	return nil
 }
 
-func updateSubnet(d *schema.ResourceData, manager *bcc.Manager) (diagErr diag.Diagnostics) {
-	network, err := manager.GetNetwork(d.Id())
+// updateSubnets brings the subnets of the network in line with `subnets`. The
+// subnets are matched by cidr, so a changed cidr replaces only that subnet.
+// Obsolete subnets are deleted first, so the new cidr may overlap the old one.
+// Without exclusive_subnets only the subnets of the previous state are deleted.
+func updateSubnets(ctx context.Context, d *schema.ResourceData, network *bcc.Network) error {
+	current, err := network.GetSubnets()
 	if err != nil {
-		return diag.Errorf("[ERROR-009]: %s", err)
+		return fmt.Errorf("crash via getting subnets: %s", err)
 	}
 
-	subnetsRaw, err := network.GetSubnets()
-	if err != nil {
-		return diag.Errorf("[ERROR-009]: %s", err)
+	oldSubnets, newSubnets := d.GetChange("subnets")
+	owned := make(map[string]bool)
+	for _, item := range oldSubnets.([]interface{}) {
+		owned[item.(map[string]interface{})["cidr"].(string)] = true
 	}
+	configured := make(map[string]map[string]interface{})
+	for _, item := range newSubnets.([]interface{}) {
+		args := item.(map[string]interface{})
+		configured[args["cidr"].(string)] = args
+	}
+	exclusive := d.Get("exclusive_subnets").(bool)
 
-	subnets := d.Get("subnets").([]interface{})
-	for _, subnetInfo := range subnets {
-		subnetInfo2 := subnetInfo.(map[string]interface{})
-		var subnet *bcc.Subnet
-		for _, currentSubnet := range subnetsRaw {
-			if currentSubnet.CIDR == subnetInfo2["cidr"] {
-				subnet = currentSubnet
-				break
-			}
+	existing := make(map[string]*bcc.Subnet)
+	for _, subnet := range current {
+		if _, ok := configured[subnet.CIDR]; ok {
+			existing[subnet.CIDR] = subnet
+			continue
 		}
-		dnsServersList := subnetInfo2["dns"].([]interface{})
-		newDnsServers := make([]*bcc.SubnetDNSServer, len(dnsServersList))
-		for i, dns := range dnsServersList {
-			s1 := bcc.NewSubnetDNSServer(dns.(string))
-			newDnsServers[i] = &s1
+		if !exclusive && !owned[subnet.CIDR] {
+			continue
 		}
-		if subnet == nil {
-			// create new subnet
-			newSubnet := bcc.NewSubnet(subnetInfo2["cidr"].(string), subnetInfo2["gateway"].(string),
-				subnetInfo2["start_ip"].(string), subnetInfo2["end_ip"].(string), subnetInfo2["dhcp"].(bool))
-			if err = network.CreateSubnet(&newSubnet); err != nil {
-				return diag.Errorf("[ERROR-009]: %s", err)
-			}
-			if err = newSubnet.UpdateDNSServers(newDnsServers); err != nil {
-				return diag.Errorf("[ERROR-009]: %s", err)
-			}
+		tflog.Debug(ctx, "Deleting subnet", map[string]interface{}{"id": subnet.ID, "cidr": subnet.CIDR})
+		if err = callUnlocked(ctx, subnet.Delete, network); err != nil {
+			return fmt.Errorf("crash via deleting subnet %s: %s", subnet.CIDR, err)
+		}
+	}
+
+	for _, item := range newSubnets.([]interface{}) {
+		args := item.(map[string]interface{})
+		if subnet, ok := existing[args["cidr"].(string)]; ok {
+			err = updateSubnet(ctx, subnet, args)
 		} else {
-			// update preserved subnet
-			shouldUpdate := false
-			if subnet.Gateway != subnetInfo2["gateway"] {
-				return diag.Errorf("[ERROR-009]: You cannot change gateway")
-			}
-			if subnet.StartIp != subnetInfo2["start_ip"] || subnet.EndIp != subnetInfo2["end_ip"] || subnet.IsDHCP != subnetInfo2["dhcp"] {
-				subnet.EndIp = subnetInfo2["end_ip"].(string)
-				subnet.StartIp = subnetInfo2["start_ip"].(string)
-				subnet.IsDHCP = subnetInfo2["dhcp"].(bool)
-				shouldUpdate = true
-			}
-			if len(subnet.DnsServers) != len(newDnsServers) {
-				subnet.DnsServers = newDnsServers
-				shouldUpdate = true
-			} else {
-				for i, oldDns := range subnet.DnsServers {
-					if oldDns.DNSServer != newDnsServers[i].DNSServer {
-						subnet.DnsServers = newDnsServers
-						shouldUpdate = true
-						break
-					}
-				}
-			}
-			if shouldUpdate {
-				if err = subnet.UpdateDNSServers(subnet.DnsServers); err != nil {
-					return diag.Errorf("[ERROR-009]: %s", err)
-				}
+			if err = waitLock(ctx, network); err != nil {
+				return err
 			}
+			_, err = createSubnet(ctx, network, args)
+		}
+		if err != nil {
+			return err
 		}
 	}
-	for _, subnet := range subnetsRaw {
-		var subnetInfo2 map[string]interface{}
-		for _, subnetInfo := range subnets {
-			subnetInfo2 = subnetInfo.(map[string]interface{})
-			if subnet.CIDR == subnetInfo2["cidr"] {
-				break
-			}
+
+	return nil
+}
+
+func expandSubnetDnsServers(items []interface{}) []*bcc.SubnetDNSServer {
+	dnsServers := make([]*bcc.SubnetDNSServer, len(items))
+	for i, dns := range items {
+		server := bcc.NewSubnetDNSServer(dns.(string))
+		dnsServers[i] = &server
+	}
+	return dnsServers
+}
+
+func flattenSubnet(subnet *bcc.Subnet) map[string]interface{} {
+	dnsStrings := make([]string, len(subnet.DnsServers))
+	for i, dns := range subnet.DnsServers {
+		dnsStrings[i] = dns.DNSServer
+	}
+	return map[string]interface{}{
+		"id":       subnet.ID,
+		"cidr":     subnet.CIDR,
+		"dhcp":     subnet.IsDHCP,
+		"gateway":  subnet.Gateway,
+		"start_ip": subnet.StartIp,
+		"end_ip":   subnet.EndIp,
+		"dns":      dnsStrings,
+	}
+}
+
+// flattenNetworkSubnets lists the subnets in the order of `subnets`, new ones
+// last. Without exclusive_subnets only the subnets of `subnets` are listed,
+// the others belong to basis_subnet or are managed outside of Terraform.
+func flattenNetworkSubnets(d *schema.ResourceData, subnets []*bcc.Subnet) []map[string]interface{} {
+	order := make(map[string]int)
+	for i, item := range d.Get("subnets").([]interface{}) {
+		order[item.(map[string]interface{})["cidr"].(string)] = i
+	}
+	exclusive := d.Get("exclusive_subnets").(bool)
+
+	sorted := make([]*bcc.Subnet, 0, len(subnets))
+	for _, subnet := range subnets {
+		if _, ok := order[subnet.CIDR]; ok || exclusive {
+			sorted = append(sorted, subnet)
 		}
-		if subnetInfo2 == nil {
-			// delete obsolete subnet
-			if err := subnet.Delete(); err != nil {
-				return diag.Errorf("[ERROR-009]: %s", err)
-			}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		oi, ok := order[sorted[i].CIDR]
+		if !ok {
+			oi = len(order)
+		}
+		oj, ok := order[sorted[j].CIDR]
+		if !ok {
+			oj = len(order)
 		}
+		return oi < oj
+	})
+
+	result := make([]map[string]interface{}, len(sorted))
+	for i, subnet := range sorted {
+		result[i] = flattenSubnet(subnet)
 	}
+	return result
+}
 
-	return
+// updateNetworkDnsServers sets the dns servers of every subnet of the network
+// which has other ones.
+func updateNetworkDnsServers(ctx context.Context, network *bcc.Network, dns []interface{}) error {
+	subnets, err := network.GetSubnets()
+	if err != nil {
+		return fmt.Errorf("crash via getting subnets: %s", err)
+	}
+	for _, subnet := range subnets {
+		same := len(subnet.DnsServers) == len(dns)
+		for i := 0; same && i < len(dns); i++ {
+			same = subnet.DnsServers[i].DNSServer == dns[i].(string)
+		}
+		if same {
+			continue
+		}
+		tflog.Debug(ctx, "Updating dns of subnet", map[string]interface{}{"id": subnet.ID, "cidr": subnet.CIDR})
+		if err = subnet.UpdateDNSServers(expandSubnetDnsServers(dns)); err != nil {
+			return fmt.Errorf("crash via setting dns of subnet %s: %s", subnet.CIDR, err)
+		}
+	}
+	return nil
+}
+
+// getSubnet returns the subnet of the network with the given id.
+func getSubnet(network *bcc.Network, id string) (*bcc.Subnet, error) {
+	subnets, err := network.GetSubnets()
+	if err != nil {
+		return nil, err
+	}
+	for _, subnet := range subnets {
+		if subnet.ID == id {
+			return subnet, nil
+		}
+	}
+	return nil, nil
 }
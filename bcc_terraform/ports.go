@@ -29,6 +29,7 @@ func (args *Arguments) injectContextResourcePort() {
 			ValidateFunc: validation.All(
 				validation.StringIsNotEmpty,
 				validation.StringDoesNotMatch(regexp.MustCompile(`^0\.0\.0\.0`), "remove ip_address to choose random IP"),
+				validation.IsIPAddress,
 			),
 		},
 		"firewall_templates": {
@@ -38,7 +39,8 @@ func (args *Arguments) injectContextResourcePort() {
 			Description: "list of firewall templates ids of the Port",
 			Elem:        &schema.Schema{Type: schema.TypeString},
 		},
-		"tags": newTagNamesResourceSchema("tags of the Port"),
+		"tags":     newTagNamesResourceSchema("tags of the Port"),
+		"tags_all": newTagNamesDataSchema("tags of the Port including the provider default tags"),
 	})
 }
 
@@ -94,3 +96,54 @@ func (args *Arguments) injectContextDataPortList() {
 		},
 	})
 }
+
+func (args *Arguments) injectContextResourceVmNetworkInterface() {
+	args.merge(Arguments{
+		"vm_id": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.StringIsNotEmpty,
+			Description:  "id of the Vm",
+		},
+		"network_id": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			ForceNew:     true,
+			ExactlyOneOf: []string{"network_id", "port_id"},
+			Description:  "id of the Network, a new Port of the network is connected to the Vm",
+		},
+		"port_id": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			ForceNew:     true,
+			ExactlyOneOf: []string{"network_id", "port_id"},
+			Description:  "id of an existing Port connected to the Vm, the Port is kept when the interface is destroyed",
+		},
+		"created_port": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "whether the interface created its Port, only such a Port is deleted with the interface",
+		},
+		"ip_address": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "ip_address of the Port",
+			ValidateFunc: validation.All(
+				validation.StringIsNotEmpty,
+				validation.StringDoesNotMatch(regexp.MustCompile(`^0\.0\.0\.0`), "remove ip_address to choose random IP"),
+				validation.IsIPAddress,
+			),
+		},
+		"firewall_templates": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Computed:    true,
+			Description: "list of firewall templates ids of the Port",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+	})
+}
@@ -22,12 +22,31 @@ func (args *Arguments) injectContextGetProject() {
 	})
 }
 
+// defaultProjectId returns the `project_id` of the provider.
+func defaultProjectId(meta interface{}) string {
+	if config, ok := meta.(*CombinedConfig); ok {
+		return config.projectID
+	}
+	return ""
+}
+
+// customizeDiffDefaultProject plans the provider `project_id` for resources
+// which do not set it.
+var customizeDiffDefaultProject = customizeDiffProviderDefault("project_id", defaultProjectId)
+
+// setDefaultProjectId sets the provider `project_id` for data sources which do
+// not set it.
+func setDefaultProjectId(d *schema.ResourceData, meta interface{}) error {
+	return setProviderDefault(d, "project_id", defaultProjectId(meta))
+}
+
 func (args *Arguments) injectContextRequiredProject() {
 	args.merge(Arguments{
 		"project_id": {
 			Type:        schema.TypeString,
-			Description: "Project identifier",
-			Required:    true,
+			Description: "Project identifier, the provider `project_id` by default",
+			Optional:    true,
+			Computed:    true,
 			ForceNew:    true,
 		},
 	})
@@ -54,7 +73,8 @@ func (args *Arguments) injectContextResourceProject() {
 			),
 			Description: "name of the Project",
 		},
-		"tags": newTagNamesResourceSchema("tags of the Project"),
+		"tags":     newTagNamesResourceSchema("tags of the Project"),
+		"tags_all": newTagNamesDataSchema("tags of the Project including the provider default tags"),
 	})
 }
 
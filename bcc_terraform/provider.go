@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func Provider() *schema.Provider {
@@ -41,6 +42,49 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("BASIS_TOKEN", nil),
 				Description: "The token key for API operations.",
 			},
+			"token_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("BASIS_TOKEN_FILE", ""),
+				ConflictsWith: []string{"token", "credential_process"},
+				Description:   "The path of a file with the token key.",
+			},
+			"credential_process": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"token", "token_file"},
+				Description:   "A command which prints the token as JSON, it is run again when the token expires.",
+			},
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("BASIS_PROFILE", ""),
+				Description: "The profile of the credentials file to use.",
+			},
+			"shared_credentials_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("BASIS_SHARED_CREDENTIALS_FILE", ""),
+				Description: "The path of the credentials file, `~/.basis/credentials` by default.",
+			},
+			"ca_cert_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"ca_cert"},
+				Description:   "The path of the root CA certificate.",
+			},
+			"cert_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"cert"},
+				Description:   "The path of the client certificate.",
+			},
+			"cert_key_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"cert_key"},
+				Description:   "The path of the RSA key for the client certificate.",
+			},
 			"api_endpoint": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -65,6 +109,96 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("BASIS_CLIENT_ID", nil),
 				Description: "The client id to use for managing instances.",
 			},
+			"project_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("BASIS_PROJECT_ID", ""),
+				Description: "The project used by resources and data sources which do not set `project_id`.",
+			},
+			"vdc_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("BASIS_VDC_ID", ""),
+				Description: "The VDC used by resources and data sources which do not set `vdc_id`.",
+			},
+			"max_requests_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      0.0,
+				ValidateFunc: validation.FloatAtLeast(0),
+				Description:  "The upper bound of API requests sent per second, 0 means no limit.",
+			},
+			"max_concurrent_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The upper bound of API requests sent at once, 0 means no limit.",
+			},
+			"lookup_cache_ttl": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "1m",
+				ValidateFunc: validateDuration,
+				Description:  "How long the results of lookups are reused, 0s turns the lookup cache off.",
+			},
+			"default_tags": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Tags added to every resource which supports them.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tags": newTagNamesResourceSchema("tags added to every resource"),
+					},
+				},
+			},
+			"retry": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "The retry policy for failed API requests.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"max_attempts": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      5,
+							ValidateFunc: validation.IntAtLeast(1),
+							Description:  "How many times a request is sent before giving up.",
+						},
+						"min_backoff": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "1s",
+							ValidateFunc: validateDuration,
+							Description:  "The delay before the first retry, it doubles with every attempt.",
+						},
+						"max_backoff": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "30s",
+							ValidateFunc: validateDuration,
+							Description:  "The upper bound of the delay between retries.",
+						},
+						"jitter": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Randomize the delays, so parallel requests do not retry in lockstep.",
+						},
+						"retryable_codes": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type:         schema.TypeInt,
+								ValidateFunc: validation.IntBetween(400, 599),
+							},
+							Description: "HTTP status codes to retry, 409, 423, 429, 500, 502, 503 and 504 by default.",
+						},
+					},
+				},
+			},
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			"basis_account": dataSourceAccount(),
@@ -107,27 +241,36 @@ func Provider() *schema.Provider {
 			"basis_paas_template":        dataSourcePaasTemplate(),        // 041-data-get-paas-template +
 			"basis_affinity_group":       dataSourceAffinityGroup(),       // 055-data-get-affinity-group +
 			"basis_affinity_groups":      dataSourceAffinityGroups(),      // 056-data-get-affinity-groups +
+			"basis_cloudinit_config":     dataSourceCloudinitConfig(),     // 059-data-get-cloudinit-config
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"basis_project":                resourceProject(),          // 001-resource-create-project +
-			"basis_vdc":                    resourceVdc(),              // 006-resource-create-vdc +
-			"basis_network":                resourceNetwork(),          // 009-resource-create-network +
-			"basis_disk":                   resourceDisk(),             // 014-resource-create-disk +
-			"basis_vm":                     resourceVm(),               // 021-resource-create-vm +
-			"basis_affinity_group":         resourceAffinityGroup(),    // 042-resource-create-affinity-group
-			"basis_firewall_template":      resourceFirewallTemplate(), // 043-resource-create-firewall-template +
-			"basis_router":                 resourceRouter(),           // 044-resource-create-router +
-			"basis_port":                   resourcePort(),             // 045-resource-create-port +
-			"basis_dns":                    resourceDns(),              // 046-resource-create-dns +
-			"basis_dns_record":             resourceDnsRecord(),        // 047-resource-create-dns-record +
-			"basis_firewall_template_rule": resourceFirewallRule(),     // 048-resource-create-firewall-rule +
-			"basis_lbaas":                  resourceLbaas(),            // 049-resource-create-lbaas +
-			"basis_lbaas_pool":             resourceLbaasPool(),        // 050-resource-create-lbaas-pool +
-			"basis_s3_storage":             resourceS3Storage(),        // 051-resource-create-s3-storage +
-			"basis_s3_storage_bucket":      resourceS3StorageBucket(),  // 052-resource-create-s3-storage-bucket +
-			"basis_kubernetes":             resourceKubernetes(),       // 053-resource-create-basis-kubernetes +
-			"basis_paas_service":           resourcePaasService(),      // 054-resource-create-paas-service
+			"basis_project":                resourceProject(),            // 001-resource-create-project +
+			"basis_vdc":                    resourceVdc(),                // 006-resource-create-vdc +
+			"basis_network":                resourceNetwork(),            // 009-resource-create-network +
+			"basis_disk":                   resourceDisk(),               // 014-resource-create-disk +
+			"basis_vm":                     resourceVm(),                 // 021-resource-create-vm +
+			"basis_affinity_group":         resourceAffinityGroup(),      // 042-resource-create-affinity-group
+			"basis_firewall_template":      resourceFirewallTemplate(),   // 043-resource-create-firewall-template +
+			"basis_router":                 resourceRouter(),             // 044-resource-create-router +
+			"basis_port":                   resourcePort(),               // 045-resource-create-port +
+			"basis_dns":                    resourceDns(),                // 046-resource-create-dns +
+			"basis_dns_record":             resourceDnsRecord(),          // 047-resource-create-dns-record +
+			"basis_firewall_template_rule": resourceFirewallRule(),       // 048-resource-create-firewall-rule +
+			"basis_lbaas":                  resourceLbaas(),              // 049-resource-create-lbaas +
+			"basis_lbaas_pool":             resourceLbaasPool(),          // 050-resource-create-lbaas-pool +
+			"basis_s3_storage":             resourceS3Storage(),          // 051-resource-create-s3-storage +
+			"basis_s3_storage_bucket":      resourceS3StorageBucket(),    // 052-resource-create-s3-storage-bucket +
+			"basis_kubernetes":             resourceKubernetes(),         // 053-resource-create-basis-kubernetes +
+			"basis_paas_service":           resourcePaasService(),        // 054-resource-create-paas-service
+			"basis_vm_disk_attachment":     resourceVmDiskAttachment(),   // 057-resource-create-vm-disk-attachment
+			"basis_vm_network_interface":   resourceVmNetworkInterface(), // 058-resource-create-vm-network-interface
+			"basis_vm_group":               resourceVmGroup(),            // 060-resource-create-vm-group
+			"basis_subnet":                 resourceSubnet(),             // 061-resource-create-subnet
+			"basis_router_route":           resourceRouterRoute(),        // 062-resource-create-router-route
+			"basis_router_interface":       resourceRouterInterface(),    // 063-resource-create-router-interface
+			"basis_default_network":        resourceDefaultNetwork(),     // 064-resource-create-default-network
+			"basis_default_router":         resourceDefaultRouter(),      // 065-resource-create-default-router
 		},
 	}
 
@@ -153,19 +296,53 @@ func providerConfigure(d *schema.ResourceData, terraformVersion string) (interfa
 	if err != nil {
 		return nil, diag.FromErr(err)
 	}
+	cacheTTL, err := time.ParseDuration(d.Get("lookup_cache_ttl").(string))
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	retry, err := expandRetryPolicy(d)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
 
 	config := Config{
-		Token:              d.Get("token").(string),
-		CaCert:             d.Get("ca_cert").(string),
-		Cert:               d.Get("cert").(string),
-		CertKey:            d.Get("cert_key").(string),
-		Insecure:           d.Get("insecure").(bool),
-		APIEndpoint:        d.Get("api_endpoint").(string),
-		APIRequestTimeout:  timeout,
-		APIRequestInterval: interval,
-		ClientID:           d.Get("client_id").(string),
-		TerraformVersion:   terraformVersion,
+		Token:                 d.Get("token").(string),
+		TokenFile:             d.Get("token_file").(string),
+		CredentialProcess:     d.Get("credential_process").(string),
+		Profile:               d.Get("profile").(string),
+		CredentialsFile:       d.Get("shared_credentials_file").(string),
+		CaCert:                d.Get("ca_cert").(string),
+		CaCertFile:            d.Get("ca_cert_file").(string),
+		Cert:                  d.Get("cert").(string),
+		CertFile:              d.Get("cert_file").(string),
+		CertKey:               d.Get("cert_key").(string),
+		CertKeyFile:           d.Get("cert_key_file").(string),
+		Insecure:              d.Get("insecure").(bool),
+		APIEndpoint:           d.Get("api_endpoint").(string),
+		APIRequestTimeout:     timeout,
+		APIRequestInterval:    interval,
+		ClientID:              d.Get("client_id").(string),
+		Retry:                 retry,
+		MaxRequestsPerSecond:  d.Get("max_requests_per_second").(float64),
+		MaxConcurrentRequests: d.Get("max_concurrent_requests").(int),
+		LookupCacheTTL:        cacheTTL,
+		DefaultTags:           expandDefaultTags(d),
+		ProjectID:             d.Get("project_id").(string),
+		VdcID:                 d.Get("vdc_id").(string),
+		TerraformVersion:      terraformVersion,
 	}
 
 	return config.Client()
 }
+
+func expandDefaultTags(d *schema.ResourceData) []string {
+	tags, ok := d.GetOk("default_tags.0.tags")
+	if !ok {
+		return nil
+	}
+	defaultTags := make([]string, 0, tags.(*schema.Set).Len())
+	for _, tag := range tags.(*schema.Set).List() {
+		defaultTags = append(defaultTags, tag.(string))
+	}
+	return defaultTags
+}
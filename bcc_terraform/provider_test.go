@@ -0,0 +1,273 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+var testAccProviderFactories = map[string]func() (*schema.Provider, error){
+	"basis": func() (*schema.Provider, error) {
+		return Provider(), nil
+	},
+}
+
+func TestProvider(t *testing.T) {
+	if err := Provider().InternalValidate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func testAccPreCheck(t *testing.T) {
+	if os.Getenv("TF_ACC") == "" {
+		t.Skip("acceptance tests are skipped unless TF_ACC is set")
+	}
+}
+
+// testAccProviderConfig points the provider to the fake API.
+func testAccProviderConfig(fake *fakeBcc) string {
+	return fmt.Sprintf(`
+provider "basis" {
+  api_endpoint         = %q
+  token                = %q
+  api_request_interval = "100ms"
+}
+`, fake.URL(), fakeToken)
+}
+
+// testAccVdcConfig adds a project with a VMware VDC, `basis_vdc.test`.
+func testAccVdcConfig(fake *fakeBcc) string {
+	return testAccProviderConfig(fake) + fmt.Sprintf(`
+resource "basis_project" "test" {
+  name = "tf-acc-project"
+}
+
+resource "basis_vdc" "test" {
+  name          = "tf-acc-vdc"
+  project_id    = basis_project.test.id
+  hypervisor_id = %q
+}
+`, fakeVmwareHypervisorID)
+}
+
+// testAccNetworkConfig adds a network, `basis_network.test`, to the VDC.
+func testAccNetworkConfig(fake *fakeBcc) string {
+	return testAccVdcConfig(fake) + `
+resource "basis_network" "test" {
+  name   = "tf-acc-network"
+  vdc_id = basis_vdc.test.id
+
+  subnets {
+    cidr     = "10.0.2.0/24"
+    dhcp     = true
+    gateway  = "10.0.2.1"
+    start_ip = "10.0.2.10"
+    end_ip   = "10.0.2.100"
+    dns      = ["8.8.8.8"]
+  }
+}
+`
+}
+
+// testAccVmConfig adds a port and a vm connected to it, `basis_vm.test`.
+func testAccVmConfig(fake *fakeBcc, name string) string {
+	return testAccNetworkConfig(fake) + fmt.Sprintf(`
+resource "basis_port" "vm" {
+  vdc_id     = basis_vdc.test.id
+  network_id = basis_network.test.id
+}
+
+resource "basis_vm" "test" {
+  vdc_id      = basis_vdc.test.id
+  name        = %q
+  cpu         = 1
+  ram         = 1
+  template_id = %q
+  user_data   = ""
+
+  system_disk {
+    size               = 10
+    storage_profile_id = %q
+  }
+
+  networks {
+    id = basis_port.vm.id
+  }
+}
+`, name, fakeTemplateID, fakeStorageProfileID)
+}
+
+// testAccCheckDestroyed makes sure every resource of the given type is gone
+// from the fake API.
+func testAccCheckDestroyed(fake *fakeBcc, resourceType string) func(*terraform.State) error {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != resourceType {
+				continue
+			}
+			if fake.Exists(rs.Primary.ID) {
+				return fmt.Errorf("%s %s still exists", resourceType, rs.Primary.ID)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccCheckExists makes sure the resource is stored by the fake API.
+func testAccCheckExists(fake *fakeBcc, name string) func(*terraform.State) error {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("not found: %s", name)
+		}
+		if !fake.Exists(rs.Primary.ID) {
+			return fmt.Errorf("%s %s does not exist", name, rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func TestAccProvider_retry(t *testing.T) {
+	fake := newFakeBcc(t)
+	fake.FailNext("POST", "v1/project", http.StatusServiceUnavailable, 2, "")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_project"),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "basis" {
+  api_endpoint         = %q
+  token                = %q
+  api_request_interval = "100ms"
+
+  retry {
+    max_attempts    = 3
+    min_backoff     = "10ms"
+    max_backoff     = "100ms"
+    retryable_codes = [503]
+  }
+}
+
+resource "basis_project" "test" {
+  name = "tf-acc-project"
+}
+`, fake.URL(), fakeToken),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_project.test"),
+					func(*terraform.State) error {
+						if got := fake.Requests("POST", "v1/project"); got != 3 {
+							return fmt.Errorf("expected 3 attempts, got %d", got)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccProvider_retryFailFast(t *testing.T) {
+	fake := newFakeBcc(t)
+	fake.FailNext("POST", "v1/project", http.StatusBadRequest, 1, "")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(fake) + `
+resource "basis_project" "test" {
+  name = "tf-acc-project"
+}
+`,
+				ExpectError: regexp.MustCompile(`400`),
+			},
+		},
+	})
+}
+
+func testAccProviderDefaultsConfig(fake *fakeBcc, projectId, vdcId string) string {
+	return fmt.Sprintf(`
+provider "basis" {
+  api_endpoint         = %q
+  token                = %q
+  api_request_interval = "100ms"
+  project_id           = %q
+  vdc_id               = %q
+}
+
+resource "basis_disk" "test" {
+  name               = "tf-acc-disk"
+  size               = 10
+  storage_profile_id = %q
+}
+
+resource "basis_dns" "test" {
+  name = "example.com."
+}
+
+data "basis_storage_profiles" "test" {}
+`, fake.URL(), fakeToken, projectId, vdcId, fakeStorageProfileID)
+}
+
+func TestAccProviderDefaults_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+	projectId, vdcId := fake.AddVdc("tf-acc-defaults")
+	otherProjectId, otherVdcId := fake.AddVdc("tf-acc-defaults-other")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_disk"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderDefaultsConfig(fake, projectId, vdcId),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_disk.test"),
+					resource.TestCheckResourceAttr("basis_disk.test", "vdc_id", vdcId),
+					resource.TestCheckResourceAttr("basis_dns.test", "project_id", projectId),
+					resource.TestCheckResourceAttr("data.basis_storage_profiles.test", "vdc_id", vdcId),
+				),
+			},
+			{
+				// a change of the defaults moves the resources
+				Config: testAccProviderDefaultsConfig(fake, otherProjectId, otherVdcId),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_disk.test"),
+					resource.TestCheckResourceAttr("basis_disk.test", "vdc_id", otherVdcId),
+					resource.TestCheckResourceAttr("basis_dns.test", "project_id", otherProjectId),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProviderDefaults_missing(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(fake) + fmt.Sprintf(`
+resource "basis_disk" "test" {
+  name               = "tf-acc-disk"
+  size               = 10
+  storage_profile_id = %q
+}
+`, fakeStorageProfileID),
+				ExpectError: regexp.MustCompile("`vdc_id` must be set on the resource or on the provider"),
+			},
+		},
+	})
+}
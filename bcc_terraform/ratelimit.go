@@ -0,0 +1,110 @@
+package bcc_terraform
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// requestLimiter bounds the rate and the number of concurrent API requests of
+// the provider. A zero limit means no limit.
+type requestLimiter struct {
+	interval time.Duration
+	slots    chan struct{}
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newRequestLimiter(perSecond float64, concurrent int) *requestLimiter {
+	limiter := &requestLimiter{}
+	if perSecond > 0 {
+		limiter.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	if concurrent > 0 {
+		limiter.slots = make(chan struct{}, concurrent)
+	}
+	return limiter
+}
+
+// wait blocks until the request may be sent or ctx is done. The returned
+// function must be called once the request is over.
+func (l *requestLimiter) wait(ctx context.Context) (func(), error) {
+	release := func() {}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+			release = func() { <-l.slots }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if l.interval > 0 {
+		l.mu.Lock()
+		now := time.Now()
+		at := l.next
+		if at.Before(now) {
+			at = now
+		}
+		l.next = at.Add(l.interval)
+		l.mu.Unlock()
+
+		if err := bcc.SleepWithContext(ctx, at.Sub(now)); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	return release, nil
+}
+
+// limitTransport sends every API request through the limiter, the retries and
+// the polling of locked objects included.
+type limitTransport struct {
+	next    http.RoundTripper
+	limiter *requestLimiter
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	release, err := t.limiter.wait(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	if waited := time.Since(start); waited >= time.Millisecond {
+		tflog.SubsystemDebug(subsystemLogContext(req.Context(), apiSubsystem), apiSubsystem, "API request delayed by the rate limit", map[string]interface{}{
+			"method":    req.Method,
+			"path":      req.URL.Path,
+			"waited_ms": waited.Milliseconds(),
+		})
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	// The request is over once its response is read, large lists included.
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releaseOnClose frees the slot of the request when its body is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
@@ -0,0 +1,125 @@
+package bcc_terraform
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestLimiter_rate(t *testing.T) {
+	limiter := newRequestLimiter(50, 0)
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		release, err := limiter.wait(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	// The first request goes out right away, the next five 20ms apart.
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatalf("6 requests at 50/s took %s, expected at least 100ms", elapsed)
+	}
+}
+
+func TestRequestLimiter_concurrency(t *testing.T) {
+	limiter := newRequestLimiter(0, 2)
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := limiter.wait(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer release()
+
+			current := atomic.AddInt32(&running, 1)
+			for {
+				seen := atomic.LoadInt32(&maxRunning)
+				if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+
+	if maxRunning > 2 {
+		t.Errorf("%d requests ran at once, expected at most 2", maxRunning)
+	}
+}
+
+func TestRequestLimiter_honorsContext(t *testing.T) {
+	limiter := newRequestLimiter(0, 1)
+	release, err := limiter.wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.wait(ctx); err == nil {
+		t.Fatal("wait must fail once the context is done")
+	}
+}
+
+func TestRequestLimiter_unlimited(t *testing.T) {
+	limiter := newRequestLimiter(0, 0)
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		release, err := limiter.wait(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("unlimited requests took %s", elapsed)
+	}
+}
+
+type stubTransport func(req *http.Request) (*http.Response, error)
+
+func (f stubTransport) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestLimitTransport_holdsSlotUntilBodyClosed(t *testing.T) {
+	limiter := newRequestLimiter(0, 1)
+	transport := &limitTransport{
+		limiter: limiter,
+		next: stubTransport(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("[]"))}, nil
+		}),
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://bcc.test/v1/vm", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.wait(ctx); err == nil {
+		t.Fatal("the slot must be held while the body is read")
+	}
+
+	resp.Body.Close()
+	resp.Body.Close()
+	release, err := limiter.wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+}
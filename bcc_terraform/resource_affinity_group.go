@@ -3,10 +3,10 @@ package bcc_terraform
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -28,15 +28,17 @@ func resourceAffinityGroup() *schema.Resource {
 
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
 
-		Schema: args,
+		CustomizeDiff: customizeDiffDefaultVdc,
+		Schema:        args,
 	}
 }
 
 func resourceAffinityGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	vdc, err := GetVdcById(d, manager)
 	if err != nil {
@@ -58,18 +60,18 @@ func resourceAffinityGroupCreate(ctx context.Context, d *schema.ResourceData, me
 	if err := vdc.CreateAffinityGroup(&affGroup); err != nil {
 		return diag.Errorf("[ERROR-042]: crash via creating AffinityGroup: %s", err)
 	}
-	if err = affGroup.WaitLock(); err != nil {
+	if err = waitLock(ctx, affGroup); err != nil {
 		return diag.Errorf("[ERROR-042]: %s", err)
 	}
 
 	d.SetId(affGroup.ID)
-	log.Printf("[INFO] AffGroup created, ID: %s", d.Id())
+	tflog.Info(ctx, "AffGroup created", map[string]interface{}{"id": d.Id()})
 
 	return resourceAffinityGroupRead(ctx, d, meta)
 }
 
 func resourceAffinityGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	needUpdate := false
 
 	affGroup, err := manager.GetAffinityGroup(d.Id())
@@ -88,7 +90,7 @@ func resourceAffinityGroupUpdate(ctx context.Context, d *schema.ResourceData, me
 	}
 
 	if needUpdate {
-		if err := repeatOnError(affGroup.Update, affGroup); err != nil {
+		if err := callUnlocked(ctx, affGroup.Update, affGroup); err != nil {
 			return diag.Errorf("[ERROR-042]: crash via updating AffinityGroup: %s", err)
 		}
 	}
@@ -96,8 +98,8 @@ func resourceAffinityGroupUpdate(ctx context.Context, d *schema.ResourceData, me
 	return resourceAffinityGroupRead(ctx, d, meta)
 }
 
-func resourceAffinityGroupRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceAffinityGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	affGroup, err := manager.GetAffinityGroup(d.Id())
 	if err != nil {
@@ -124,8 +126,8 @@ func resourceAffinityGroupRead(_ context.Context, d *schema.ResourceData, meta i
 	return nil
 }
 
-func resourceAffinityGroupDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceAffinityGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	affGroup, err := manager.GetAffinityGroup(d.Id())
 	if err != nil {
@@ -135,13 +137,15 @@ func resourceAffinityGroupDelete(_ context.Context, d *schema.ResourceData, meta
 	if err = affGroup.Delete(); err != nil {
 		return diag.Errorf("[ERROR-042]: crash via deleting vm: %s", err)
 	}
-	affGroup.WaitLock()
+	if err := waitLock(ctx, affGroup); err != nil && !isNotFound(err) {
+		return diag.Errorf("[ERROR-042]: crash via deleting vm: %s", err)
+	}
 
 	return nil
 }
 
-func resourceAffinityGroupImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceAffinityGroupImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	affGroup, err := manager.GetAffinityGroup(d.Id())
 	if err != nil {
@@ -0,0 +1,52 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccAffinityGroup_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_affinity_group"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVdcConfig(fake) + `
+resource "basis_affinity_group" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-group"
+  policy = "soft-anti-affinity"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_affinity_group.test"),
+					resource.TestCheckResourceAttr("basis_affinity_group.test", "policy", "soft-anti-affinity"),
+					resource.TestCheckResourceAttr("basis_affinity_group.test", "vms.#", "0"),
+				),
+			},
+			{
+				Config: testAccVdcConfig(fake) + `
+resource "basis_affinity_group" "test" {
+  vdc_id      = basis_vdc.test.id
+  name        = "tf-acc-group-renamed"
+  description = "managed by terraform"
+  policy      = "soft-anti-affinity"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_affinity_group.test", "name", "tf-acc-group-renamed"),
+					resource.TestCheckResourceAttr("basis_affinity_group.test", "description", "managed by terraform"),
+				),
+			},
+			{
+				ResourceName:      "basis_affinity_group.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
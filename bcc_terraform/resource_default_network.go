@@ -0,0 +1,229 @@
+package bcc_terraform
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceDefaultNetwork() *schema.Resource {
+	args := Defaults()
+	args.injectContextRequiredVdc()
+	args.injectContextResourceDefaultNetwork()
+
+	return &schema.Resource{
+		CreateContext: resourceDefaultNetworkCreate,
+		UpdateContext: resourceDefaultNetworkUpdate,
+		ReadContext:   resourceDefaultNetworkRead,
+		DeleteContext: resourceDefaultNetworkDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceDefaultNetworkImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		CustomizeDiff: customizeDiffDefaultVdc,
+		Schema:        args,
+	}
+}
+
+// resourceDefaultNetworkCreate adopts the network created together with the
+// VDC, its settings are kept to restore them on destroy.
+func resourceDefaultNetworkCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	vdc, err := GetVdcById(d, manager)
+	if err != nil {
+		return diag.Errorf("[ERROR-064]: %s", err)
+	}
+	network, err := GetServiseNetworkByVdc(vdc)
+	if err != nil {
+		return diag.Errorf("[ERROR-064]: crash via getting the default network of vdc %s: %s", vdc.Name, err)
+	}
+
+	unlock, err := lockObject(ctx, meta, "network", network.ID)
+	if err != nil {
+		return diag.Errorf("[ERROR-064]: %s", err)
+	}
+	defer unlock()
+
+	if err = setDefaultNetworkInitial(d, network); err != nil {
+		return diag.Errorf("[ERROR-064]: %s", err)
+	}
+	d.SetId(network.ID)
+
+	if err = applyDefaultNetwork(ctx, d, network); err != nil {
+		return diag.Errorf("[ERROR-064]: %s", err)
+	}
+	tflog.Info(ctx, "Default network adopted", map[string]interface{}{"id": d.Id(), "vdc_id": vdc.ID})
+
+	return resourceDefaultNetworkRead(ctx, d, meta)
+}
+
+func resourceDefaultNetworkUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+
+	unlock, err := lockObject(ctx, meta, "network", d.Id())
+	if err != nil {
+		return diag.Errorf("[ERROR-064]: %s", err)
+	}
+	defer unlock()
+
+	network, err := manager.GetNetwork(d.Id())
+	if err != nil {
+		return diag.Errorf("[ERROR-064]: crash via getting network-%s: %s", d.Id(), err)
+	}
+	if err = applyDefaultNetwork(ctx, d, network); err != nil {
+		return diag.Errorf("[ERROR-064]: %s", err)
+	}
+
+	return resourceDefaultNetworkRead(ctx, d, meta)
+}
+
+// applyDefaultNetwork changes the settings of the network which are set in
+// the configuration, the others keep their current values.
+func applyDefaultNetwork(ctx context.Context, d *schema.ResourceData, network *bcc.Network) error {
+	config := d.GetRawConfig()
+
+	needUpdate := false
+	if name := d.Get("name").(string); !config.GetAttr("name").IsNull() && name != network.Name {
+		network.Name = name
+		needUpdate = true
+	}
+	if mtu := d.Get("mtu").(int); !config.GetAttr("mtu").IsNull() && (network.Mtu == nil || *network.Mtu != mtu) {
+		network.Mtu = &mtu
+		needUpdate = true
+	}
+	if needUpdate {
+		if err := network.Update(); err != nil {
+			return fmt.Errorf("crash via updating network: %s", err)
+		}
+	}
+
+	if !config.GetAttr("dns").IsNull() {
+		if err := updateNetworkDnsServers(ctx, network, d.Get("dns").([]interface{})); err != nil {
+			return err
+		}
+	}
+	if err := waitLock(ctx, network); err != nil {
+		return fmt.Errorf("crash via wait lock %s", err)
+	}
+	return nil
+}
+
+func resourceDefaultNetworkRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	network, err := manager.GetNetwork(d.Id())
+	if err != nil {
+		return resourceReadCheck(d, err, "[ERROR-064]:")
+	}
+
+	subnets, err := network.GetSubnets()
+	if err != nil {
+		return diag.Errorf("[ERROR-064]: %s", err)
+	}
+	flattenedSubnets := make([]map[string]interface{}, len(subnets))
+	for i, subnet := range subnets {
+		flattenedSubnets[i] = flattenSubnet(subnet)
+	}
+
+	fields := map[string]interface{}{
+		"name":    network.Name,
+		"mtu":     network.Mtu,
+		"dns":     defaultNetworkDns(subnets),
+		"subnets": flattenedSubnets,
+		"vdc_id":  network.Vdc.Id,
+	}
+
+	if err = setResourceDataFromMap(d, fields); err != nil {
+		return diag.Errorf("[ERROR-064]: %s", err)
+	}
+
+	return nil
+}
+
+// resourceDefaultNetworkDelete restores the settings the network had when it
+// was adopted, the default network itself can't be deleted.
+func resourceDefaultNetworkDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+
+	unlock, err := lockObject(ctx, meta, "network", d.Id())
+	if err != nil {
+		return diag.Errorf("[ERROR-064]: %s", err)
+	}
+	defer unlock()
+
+	network, err := manager.GetNetwork(d.Id())
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return diag.Errorf("[ERROR-064]: crash via getting network-%s: %s", d.Id(), err)
+	}
+
+	network.Name = d.Get("initial_name").(string)
+	network.Mtu = nil
+	if mtu := d.Get("initial_mtu").(int); mtu != 0 {
+		network.Mtu = &mtu
+	}
+	if err = network.Update(); err != nil {
+		return diag.Errorf("[ERROR-064]: crash via restoring network-%s: %s", d.Id(), err)
+	}
+	if err = updateNetworkDnsServers(ctx, network, d.Get("initial_dns").([]interface{})); err != nil {
+		return diag.Errorf("[ERROR-064]: %s", err)
+	}
+	if err = waitLock(ctx, network); err != nil {
+		return diag.Errorf("[ERROR-064]: crash via restoring network-%s: %s", d.Id(), err)
+	}
+	tflog.Info(ctx, "Default network restored", map[string]interface{}{"id": d.Id()})
+
+	return nil
+}
+
+// resourceDefaultNetworkImport adopts the network by its id, the current
+// settings are the ones restored on destroy.
+func resourceDefaultNetworkImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	network, err := manager.GetNetwork(d.Id())
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR-064]: crash via getting network-%s: %s", d.Id(), err)
+	}
+	if !network.IsDefault {
+		return nil, fmt.Errorf("[ERROR-064]: network %s is not the default network of its vdc, import it as basis_network", network.Name)
+	}
+
+	d.SetId(network.ID)
+	if err = setDefaultNetworkInitial(d, network); err != nil {
+		return nil, fmt.Errorf("[ERROR-064]: %s", err)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// setDefaultNetworkInitial keeps the current settings of the network.
+func setDefaultNetworkInitial(d *schema.ResourceData, network *bcc.Network) error {
+	subnets, err := network.GetSubnets()
+	if err != nil {
+		return fmt.Errorf("crash via getting subnets: %s", err)
+	}
+	fields := map[string]interface{}{
+		"initial_name": network.Name,
+		"initial_mtu":  network.Mtu,
+		"initial_dns":  defaultNetworkDns(subnets),
+	}
+	return setResourceDataFromMap(d, fields)
+}
+
+// defaultNetworkDns returns the dns servers of the first subnet, the default
+// network has a single one.
+func defaultNetworkDns(subnets []*bcc.Subnet) []string {
+	if len(subnets) == 0 {
+		return []string{}
+	}
+	return flattenSubnet(subnets[0])["dns"].([]string)
+}
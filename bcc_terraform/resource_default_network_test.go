@@ -0,0 +1,75 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccDefaultNetwork_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_vdc"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVdcConfig(fake) + `
+resource "basis_default_network" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-default-network"
+  mtu    = 1400
+  dns    = ["1.1.1.1"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_default_network.test", "name", "tf-acc-default-network"),
+					resource.TestCheckResourceAttr("basis_default_network.test", "mtu", "1400"),
+					resource.TestCheckResourceAttr("basis_default_network.test", "dns.#", "1"),
+					resource.TestCheckResourceAttr("basis_default_network.test", "subnets.#", "1"),
+					resource.TestCheckResourceAttr("basis_default_network.test", "subnets.0.cidr", fakeDefaultNetworkCIDR),
+					resource.TestCheckResourceAttr("basis_default_network.test", "subnets.0.dns.0", "1.1.1.1"),
+					resource.TestCheckResourceAttr("basis_default_network.test", "initial_name", fakeDefaultNetworkName),
+					resource.TestCheckResourceAttr("basis_default_network.test", "initial_dns.#", "2"),
+				),
+			},
+			{
+				// Arguments left out keep their values.
+				Config: testAccVdcConfig(fake) + `
+resource "basis_default_network" "test" {
+  vdc_id = basis_vdc.test.id
+  dns    = ["1.1.1.1", "9.9.9.9"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_default_network.test", "name", "tf-acc-default-network"),
+					resource.TestCheckResourceAttr("basis_default_network.test", "mtu", "1400"),
+					resource.TestCheckResourceAttr("basis_default_network.test", "dns.#", "2"),
+					resource.TestCheckResourceAttr("basis_default_network.test", "dns.1", "9.9.9.9"),
+				),
+			},
+			{
+				ResourceName:            "basis_default_network.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"initial_name", "initial_mtu", "initial_dns"},
+			},
+			{
+				// The network is kept with the settings it was adopted with.
+				Config: testAccVdcConfig(fake),
+				Check: func(s *terraform.State) error {
+					vdc := s.RootModule().Resources["basis_vdc.test"].Primary.ID
+					name, mtu, dns := fake.DefaultNetwork(vdc)
+					if name != fakeDefaultNetworkName || mtu != nil || strings.Join(dns, ",") != "8.8.8.8,8.8.4.4" {
+						return fmt.Errorf("default network not restored: name %q, mtu %v, dns %v", name, mtu, dns)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,207 @@
+package bcc_terraform
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceDefaultRouter() *schema.Resource {
+	args := Defaults()
+	args.injectContextRequiredVdc()
+	args.injectContextResourceDefaultRouter()
+
+	return &schema.Resource{
+		CreateContext: resourceDefaultRouterCreate,
+		UpdateContext: resourceDefaultRouterUpdate,
+		ReadContext:   resourceDefaultRouterRead,
+		DeleteContext: resourceDefaultRouterDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceDefaultRouterImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		CustomizeDiff: customdiff.All(customizeDiffDefaultVdc, resourceRouterCustomizeDiff),
+		Schema:        args,
+	}
+}
+
+// resourceDefaultRouterCreate adopts the router created together with the
+// VDC, its settings are kept to restore them on destroy.
+func resourceDefaultRouterCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	vdc, err := GetVdcById(d, manager)
+	if err != nil {
+		return diag.Errorf("[ERROR-065] crash via getting vdc: %s", err)
+	}
+	router, err := getVdcDefaultRouter(vdc)
+	if err != nil {
+		return diag.Errorf("[ERROR-065] crash via getting the default router: %s", err)
+	}
+
+	unlock, err := lockObject(ctx, meta, "router", router.ID)
+	if err != nil {
+		return diag.Errorf("[ERROR-065] crash via router lock: %s", err)
+	}
+	defer unlock()
+
+	if err = setDefaultRouterInitial(d, router); err != nil {
+		return diag.Errorf("[ERROR-065] crash via set attrs: %s", err)
+	}
+	d.SetId(router.ID)
+
+	if err = applyDefaultRouter(ctx, d, router); err != nil {
+		return diag.Errorf("[ERROR-065] %s", err)
+	}
+	tflog.Info(ctx, "Default router adopted", map[string]interface{}{"id": d.Id(), "vdc_id": vdc.ID})
+
+	return resourceDefaultRouterRead(ctx, d, meta)
+}
+
+func resourceDefaultRouterUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+
+	unlock, err := lockObject(ctx, meta, "router", d.Id())
+	if err != nil {
+		return diag.Errorf("[ERROR-065] crash via router lock: %s", err)
+	}
+	defer unlock()
+
+	router, err := manager.GetRouter(d.Id())
+	if err != nil {
+		return diag.Errorf("[ERROR-065] crash via getting Router: %s", err)
+	}
+	if err = applyDefaultRouter(ctx, d, router); err != nil {
+		return diag.Errorf("[ERROR-065] %s", err)
+	}
+
+	return resourceDefaultRouterRead(ctx, d, meta)
+}
+
+// applyDefaultRouter changes the settings of the router which are set in the
+// configuration, the others keep their current values.
+func applyDefaultRouter(ctx context.Context, d *schema.ResourceData, router *bcc.Router) error {
+	config := d.GetRawConfig()
+
+	shouldUpdate := false
+	if name := d.Get("name").(string); !config.GetAttr("name").IsNull() && name != router.Name {
+		router.Name = name
+		shouldUpdate = true
+	}
+	if floating := d.Get("floating").(bool); !config.GetAttr("floating").IsNull() && floating != (router.Floating != nil) {
+		setRouterFloating(router, floating)
+		shouldUpdate = true
+	}
+	if !shouldUpdate {
+		return nil
+	}
+
+	if err := callUnlocked(ctx, router.Update, router); err != nil {
+		return fmt.Errorf("crash via router's update %s", err)
+	}
+	if err := waitLock(ctx, router); err != nil {
+		return fmt.Errorf("crash via router's update %s", err)
+	}
+	return nil
+}
+
+func resourceDefaultRouterRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	router, err := manager.GetRouter(d.Id())
+	if err != nil {
+		return resourceReadCheck(d, err, "[ERROR-065]")
+	}
+
+	ports := make([]*string, len(router.Ports))
+	for i, port := range router.Ports {
+		ports[i] = &port.ID
+	}
+
+	fields := map[string]interface{}{
+		"name":        router.Name,
+		"ports":       ports,
+		"vdc_id":      router.Vdc.ID,
+		"floating":    false,
+		"floating_id": "",
+	}
+	if router.Floating != nil {
+		fields["floating"] = true
+		fields["floating_id"] = router.Floating.ID
+	}
+
+	if err = setResourceDataFromMap(d, fields); err != nil {
+		return diag.Errorf("[ERROR-065] crash via set attrs: %s", err)
+	}
+
+	return nil
+}
+
+// resourceDefaultRouterDelete restores the settings the router had when it
+// was adopted, the default router itself can't be deleted. A released
+// floating ip is restored as a new random one.
+func resourceDefaultRouterDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+
+	unlock, err := lockObject(ctx, meta, "router", d.Id())
+	if err != nil {
+		return diag.Errorf("[ERROR-065] crash via router lock: %s", err)
+	}
+	defer unlock()
+
+	router, err := manager.GetRouter(d.Id())
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return diag.Errorf("[ERROR-065] crash via getting Router: %s", err)
+	}
+
+	router.Name = d.Get("initial_name").(string)
+	setRouterFloating(router, d.Get("initial_floating").(bool))
+	if err = callUnlocked(ctx, router.Update, router); err != nil {
+		return diag.Errorf("[ERROR-065] Can't return router to default state: %s", err)
+	}
+	if err = waitLock(ctx, router); err != nil {
+		return diag.Errorf("[ERROR-065] Can't return router to default state: %s", err)
+	}
+	tflog.Info(ctx, "Default router restored", map[string]interface{}{"id": d.Id()})
+
+	return nil
+}
+
+// resourceDefaultRouterImport adopts the router by its id, the current
+// settings are the ones restored on destroy.
+func resourceDefaultRouterImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	router, err := manager.GetRouter(d.Id())
+	if err != nil {
+		return nil, err
+	}
+	if !router.IsDefault {
+		return nil, fmt.Errorf("[ERROR-065] router %s is not the default router of its vdc, import it as basis_router", router.Name)
+	}
+
+	d.SetId(router.ID)
+	if err = setDefaultRouterInitial(d, router); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// setDefaultRouterInitial keeps the current settings of the router.
+func setDefaultRouterInitial(d *schema.ResourceData, router *bcc.Router) error {
+	fields := map[string]interface{}{
+		"initial_name":     router.Name,
+		"initial_floating": router.Floating != nil,
+	}
+	return setResourceDataFromMap(d, fields)
+}
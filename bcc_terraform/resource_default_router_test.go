@@ -0,0 +1,71 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testAccDefaultRouterConfig(fake *fakeBcc, name string, floating bool) string {
+	return testAccVdcConfig(fake) + fmt.Sprintf(`
+resource "basis_default_router" "test" {
+  vdc_id   = basis_vdc.test.id
+  name     = %q
+  floating = %t
+}
+`, name, floating)
+}
+
+func TestAccDefaultRouter_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_vdc"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDefaultRouterConfig(fake, "tf-acc-default-router", false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_default_router.test", "name", "tf-acc-default-router"),
+					resource.TestCheckResourceAttr("basis_default_router.test", "floating", "false"),
+					resource.TestCheckResourceAttr("basis_default_router.test", "floating_id", ""),
+					resource.TestCheckResourceAttr("basis_default_router.test", "ports.#", "1"),
+					resource.TestCheckResourceAttr("basis_default_router.test", "initial_name", fakeDefaultRouterName),
+					resource.TestCheckResourceAttr("basis_default_router.test", "initial_floating", "true"),
+				),
+			},
+			{
+				Config: testAccDefaultRouterConfig(fake, "tf-acc-default-router-renamed", true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_default_router.test", "name", "tf-acc-default-router-renamed"),
+					resource.TestCheckResourceAttr("basis_default_router.test", "floating", "true"),
+					resource.TestCheckResourceAttrSet("basis_default_router.test", "floating_id"),
+				),
+			},
+			{
+				Config: testAccDefaultRouterConfig(fake, "tf-acc-default-router-renamed", false),
+				Check:  resource.TestCheckResourceAttr("basis_default_router.test", "floating", "false"),
+			},
+			{
+				ResourceName:            "basis_default_router.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"initial_name", "initial_floating"},
+			},
+			{
+				// The router is kept with the settings it was adopted with.
+				Config: testAccVdcConfig(fake),
+				Check: func(s *terraform.State) error {
+					vdc := s.RootModule().Resources["basis_vdc.test"].Primary.ID
+					if name, floating := fake.DefaultRouter(vdc); name != fakeDefaultRouterName || !floating {
+						return fmt.Errorf("default router not restored: name %q, floating %t", name, floating)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
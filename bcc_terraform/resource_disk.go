@@ -3,11 +3,12 @@ package bcc_terraform
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -26,14 +27,16 @@ func resourceDisk() *schema.Resource {
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
-		Schema: args,
+		CustomizeDiff: customdiff.All(customizeDiffDefaultVdc, customizeDiffTagsAll),
+		Schema:        args,
 	}
 }
 
 func resourceDiskCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	config := struct {
 		name             string
@@ -56,26 +59,32 @@ func resourceDiskCreate(ctx context.Context, d *schema.ResourceData, meta interf
 	}
 
 	disk := bcc.NewDisk(config.name, config.size, storageProfile)
-	disk.Tags = unmarshalTagNames(d.Get("tags"))
+	disk.Tags = expandTags(d, meta)
 
-	if err = vdc.WaitLock(); err != nil {
+	unlock, err := lockObject(ctx, meta, "vdc", vdc.ID)
+	if err != nil {
+		return diag.Errorf("[ERROR-014] crash via vdc lock: %s", err)
+	}
+	defer unlock()
+
+	if err = waitLock(ctx, vdc); err != nil {
 		return diag.Errorf("[ERROR-014] crash via vdc waitlock: %s", err)
 	}
 	if err = vdc.CreateDisk(&disk); err != nil {
 		return diag.Errorf("[ERROR-014] crash via disk create: %s", err)
 	}
-	if err = disk.WaitLock(); err != nil {
+	if err = waitLock(ctx, disk); err != nil {
 		return diag.Errorf("[ERROR-014] crash via disk waitlock: %s", err)
 	}
 
 	d.SetId(disk.ID)
-	log.Printf("[INFO] Disk created, ID: %s", d.Id())
+	tflog.Info(ctx, "Disk created", map[string]interface{}{"id": d.Id()})
 
 	return resourceDiskRead(ctx, d, meta)
 }
 
 func resourceDiskUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	disk, err := manager.GetDisk(d.Id())
 	if err != nil {
@@ -87,14 +96,14 @@ func resourceDiskUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 		disk.Name = d.Get("name").(string)
 		needUpdate = true
 	}
-	if d.HasChange("tags") {
-		disk.Tags = unmarshalTagNames(d.Get("tags"))
+	if d.HasChanges("tags", "tags_all") {
+		disk.Tags = expandTags(d, meta)
 		needUpdate = true
 	}
 	if d.HasChange("size") {
 		disk.Size = d.Get("size").(int)
 		if disk.Locked {
-			if err = disk.WaitLock(); err != nil {
+			if err = waitLock(ctx, disk); err != nil {
 				return diag.Errorf("[ERROR-014] crash via waitlock: %s", err)
 			}
 		}
@@ -115,7 +124,7 @@ func resourceDiskUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 			return diag.Errorf("[ERROR-014] crash via get storage profile: %s", err)
 		}
 		if disk.Locked {
-			if err = disk.WaitLock(); err != nil {
+			if err = waitLock(ctx, disk); err != nil {
 				return diag.Errorf("[ERROR-014] crash via waitlock: %s", err)
 			}
 		}
@@ -127,7 +136,7 @@ func resourceDiskUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 	}
 	if needUpdate {
 		if disk.Locked {
-			if err = disk.WaitLock(); err != nil {
+			if err = waitLock(ctx, disk); err != nil {
 				return diag.Errorf("[ERROR-014] crash via disk waitlock: %s", err)
 			}
 		}
@@ -139,8 +148,8 @@ func resourceDiskUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 	return resourceDiskRead(ctx, d, meta)
 }
 
-func resourceDiskRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceDiskRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	disk, err := manager.GetDisk(d.Id())
 	if err != nil {
@@ -153,7 +162,8 @@ func resourceDiskRead(_ context.Context, d *schema.ResourceData, meta interface{
 		"name":               disk.Name,
 		"storage_profile_id": disk.StorageProfile.ID,
 		"external_id":        disk.ExternalID,
-		"tags":               marshalTagNames(disk.Tags),
+		"tags":               flattenTags(d, meta, disk.Tags),
+		"tags_all":           marshalTagNames(disk.Tags),
 	}
 
 	if err := setResourceDataFromMap(d, fields); err != nil {
@@ -163,10 +173,14 @@ func resourceDiskRead(_ context.Context, d *schema.ResourceData, meta interface{
 	return nil
 }
 
-func resourceDiskDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceDiskDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	disk, err := manager.GetDisk(d.Id())
 	if err != nil {
+		// The disk may be deleted with its vm, see on_destroy of basis_vm.
+		if isNotFound(err) {
+			return nil
+		}
 		return diag.Errorf("[ERROR-014] crash via get disk: %s", err)
 	}
 
@@ -184,13 +198,15 @@ func resourceDiskDelete(_ context.Context, d *schema.ResourceData, meta interfac
 	if err = disk.Delete(); err != nil {
 		return diag.Errorf("[ERROR-014] crash via delete disk: %s", err)
 	}
-	disk.WaitLock()
+	if err := waitLock(ctx, disk); err != nil && !isNotFound(err) {
+		return diag.Errorf("[ERROR-014] crash via delete disk: %s", err)
+	}
 
 	return nil
 }
 
-func resourceDiskImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceDiskImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	disk, err := manager.GetDisk(d.Id())
 	if err != nil {
@@ -0,0 +1,52 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func testAccDiskConfig(fake *fakeBcc, name string, size int) string {
+	return testAccVdcConfig(fake) + fmt.Sprintf(`
+resource "basis_disk" "test" {
+  vdc_id             = basis_vdc.test.id
+  name               = %q
+  size               = %d
+  storage_profile_id = %q
+}
+`, name, size, fakeStorageProfileID)
+}
+
+func TestAccDisk_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_disk"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDiskConfig(fake, "tf-acc-disk", 10),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_disk.test"),
+					resource.TestCheckResourceAttr("basis_disk.test", "size", "10"),
+					resource.TestCheckResourceAttr("basis_disk.test", "storage_profile_id", fakeStorageProfileID),
+					resource.TestCheckResourceAttrSet("basis_disk.test", "external_id"),
+				),
+			},
+			{
+				Config: testAccDiskConfig(fake, "tf-acc-disk-renamed", 20),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_disk.test", "name", "tf-acc-disk-renamed"),
+					resource.TestCheckResourceAttr("basis_disk.test", "size", "20"),
+				),
+			},
+			{
+				ResourceName:      "basis_disk.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
@@ -3,12 +3,13 @@ package bcc_terraform
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -27,14 +28,16 @@ func resourceDns() *schema.Resource {
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
-		Schema: args,
+		CustomizeDiff: customdiff.All(customizeDiffDefaultProject, customizeDiffTagsAll),
+		Schema:        args,
 	}
 }
 
 func resourceDnsCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	project, err := GetProjectById(d, manager)
 	if err != nil {
@@ -47,7 +50,7 @@ func resourceDnsCreate(ctx context.Context, d *schema.ResourceData, meta interfa
 	}
 
 	dns := bcc.NewDns(name)
-	dns.Tags = unmarshalTagNames(d.Get("tags"))
+	dns.Tags = expandTags(d, meta)
 
 	err = project.CreateDns(&dns)
 	if err != nil {
@@ -55,22 +58,22 @@ func resourceDnsCreate(ctx context.Context, d *schema.ResourceData, meta interfa
 	}
 
 	d.SetId(dns.ID)
-	log.Printf("[INFO]: Dns created, ID: %s", d.Id())
+	tflog.Info(ctx, "Dns created", map[string]interface{}{"id": d.Id()})
 
 	return resourceDnsRead(ctx, d, meta)
 }
 
 func resourceDnsUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	needUpdate := false
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	dns, err := manager.GetDns(d.Id())
 	if err != nil {
 		return diag.Errorf("[ERROR-046]: crash via get Dns: %s", err)
 	}
 
-	if d.HasChange("tags") {
-		dns.Tags = unmarshalTagNames(d.Get("tags"))
+	if d.HasChanges("tags", "tags_all") {
+		dns.Tags = expandTags(d, meta)
 		needUpdate = true
 	}
 	if needUpdate {
@@ -81,18 +84,19 @@ func resourceDnsUpdate(ctx context.Context, d *schema.ResourceData, meta interfa
 	return resourceDnsRead(ctx, d, meta)
 }
 
-func resourceDnsRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceDnsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	dns, err := manager.GetDns(d.Id())
 	if err != nil {
-		resourceReadCheck(d, err, "[ERROR-046]:")
+		return resourceReadCheck(d, err, "[ERROR-046]:")
 	}
 
 	fields := map[string]interface{}{
 		"name":       dns.Name,
 		"project_id": dns.Project.ID,
-		"tags":       marshalTagNames(dns.Tags),
+		"tags":       flattenTags(d, meta, dns.Tags),
+		"tags_all":   marshalTagNames(dns.Tags),
 	}
 
 	if err := setResourceDataFromMap(d, fields); err != nil {
@@ -102,8 +106,8 @@ func resourceDnsRead(_ context.Context, d *schema.ResourceData, meta interface{}
 	return nil
 }
 
-func resourceDnsDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceDnsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	dns, err := manager.GetDns(d.Id())
 	if err != nil {
 		return diag.Errorf("[ERROR-046]: crash via get Dns: %s", err)
@@ -118,7 +122,7 @@ func resourceDnsDelete(_ context.Context, d *schema.ResourceData, meta interface
 }
 
 func resourceDnsImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	dns, err := manager.GetDns(d.Id())
 	if err != nil {
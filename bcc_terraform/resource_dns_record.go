@@ -3,11 +3,11 @@ package bcc_terraform
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -27,6 +27,7 @@ func resourceDnsRecord() *schema.Resource {
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
 		Schema: args,
@@ -34,7 +35,7 @@ func resourceDnsRecord() *schema.Resource {
 }
 
 func resourceDnsRecordCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	fields := struct {
 		DnsId    string
@@ -80,13 +81,13 @@ func resourceDnsRecordCreate(ctx context.Context, d *schema.ResourceData, meta i
 	}
 
 	d.SetId(newDnsRecord.ID)
-	log.Printf("[INFO] Dns record created, ID: %s", d.Id())
+	tflog.Info(ctx, "Dns record created", map[string]interface{}{"id": d.Id()})
 
 	return resourceDnsRecordRead(ctx, d, meta)
 }
 
 func resourceDnsRecordUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	fields := struct {
 		ID       string
@@ -159,7 +160,7 @@ func resourceDnsRecordUpdate(ctx context.Context, d *schema.ResourceData, meta i
 }
 
 func resourceDnsRecordRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	dnsId := d.Get("dns_id").(string)
 	dns, err := manager.GetDns(dnsId)
@@ -203,7 +204,7 @@ func resourceDnsRecordRead(ctx context.Context, d *schema.ResourceData, meta int
 }
 
 func resourceDnsRecordDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	dnsId := d.Get("dns_id").(string)
 	dns, err := manager.GetDns(dnsId)
 	if err != nil {
@@ -223,7 +224,7 @@ func resourceDnsRecordDelete(ctx context.Context, d *schema.ResourceData, meta i
 }
 
 func resourceDnsRecordImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	id := d.Id()
 	ids := strings.Split(id, ",")
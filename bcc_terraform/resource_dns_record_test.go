@@ -0,0 +1,63 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testAccDnsRecordConfig(fake *fakeBcc, data string) string {
+	return testAccProviderConfig(fake) + fmt.Sprintf(`
+resource "basis_project" "test" {
+  name = "tf-acc-project"
+}
+
+resource "basis_dns" "test" {
+  project_id = basis_project.test.id
+  name       = "example.com."
+}
+
+resource "basis_dns_record" "test" {
+  dns_id = basis_dns.test.id
+  type   = "A"
+  host   = "www.example.com."
+  data   = %q
+  ttl    = 300
+}
+`, data)
+}
+
+func TestAccDnsRecord_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_dns_record"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDnsRecordConfig(fake, "192.0.2.10"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_dns_record.test"),
+					resource.TestCheckResourceAttr("basis_dns_record.test", "data", "192.0.2.10"),
+					resource.TestCheckResourceAttr("basis_dns_record.test", "ttl", "300"),
+				),
+			},
+			{
+				Config: testAccDnsRecordConfig(fake, "192.0.2.20"),
+				Check:  resource.TestCheckResourceAttr("basis_dns_record.test", "data", "192.0.2.20"),
+			},
+			{
+				ResourceName:      "basis_dns_record.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs := s.RootModule().Resources["basis_dns_record.test"]
+					return rs.Primary.Attributes["dns_id"] + "," + rs.Primary.ID, nil
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,54 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDns_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_dns"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(fake) + `
+resource "basis_project" "test" {
+  name = "tf-acc-project"
+}
+
+resource "basis_dns" "test" {
+  project_id = basis_project.test.id
+  name       = "example.com."
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_dns.test"),
+					resource.TestCheckResourceAttr("basis_dns.test", "name", "example.com."),
+				),
+			},
+			{
+				Config: testAccProviderConfig(fake) + `
+resource "basis_project" "test" {
+  name = "tf-acc-project"
+}
+
+resource "basis_dns" "test" {
+  project_id = basis_project.test.id
+  name       = "example.com."
+  tags       = ["dns"]
+}
+`,
+				Check: resource.TestCheckResourceAttr("basis_dns.test", "tags.#", "1"),
+			},
+			{
+				ResourceName:      "basis_dns.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
@@ -3,11 +3,12 @@ package bcc_terraform
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -26,21 +27,23 @@ func resourceFirewallTemplate() *schema.Resource {
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
-		Schema: args,
+		CustomizeDiff: customdiff.All(customizeDiffDefaultVdc, customizeDiffTagsAll),
+		Schema:        args,
 	}
 }
 
 func resourceFirewallTemplateCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	targetVdc, err := GetVdcById(d, manager)
 	if err != nil {
 		return diag.Errorf("[ERROR-043]: crash via getting VDC by id: %s", err)
 	}
 
 	newFirewallTemplate := bcc.NewFirewallTemplate(d.Get("name").(string))
-	newFirewallTemplate.Tags = unmarshalTagNames(d.Get("tags"))
+	newFirewallTemplate.Tags = expandTags(d, meta)
 	newFirewallTemplate.Description = d.Get("description").(string)
 
 	err = targetVdc.CreateFirewallTemplate(&newFirewallTemplate)
@@ -49,13 +52,13 @@ func resourceFirewallTemplateCreate(ctx context.Context, d *schema.ResourceData,
 	}
 
 	d.SetId(newFirewallTemplate.ID)
-	log.Printf("[INFO]: firewallTemplate created, ID: %s", d.Id())
+	tflog.Info(ctx, "FirewallTemplate created", map[string]interface{}{"id": d.Id()})
 
 	return resourceFirewallTemplateRead(ctx, d, meta)
 }
 
 func resourceFirewallTemplateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	firewallTemplate, err := manager.GetFirewallTemplate(d.Id())
 	if err != nil {
@@ -65,8 +68,8 @@ func resourceFirewallTemplateUpdate(ctx context.Context, d *schema.ResourceData,
 	if d.HasChange("name") {
 		firewallTemplate.Name = d.Get("name").(string)
 	}
-	if d.HasChange("tags") {
-		firewallTemplate.Tags = unmarshalTagNames(d.Get("tags"))
+	if d.HasChanges("tags", "tags_all") {
+		firewallTemplate.Tags = expandTags(d, meta)
 	}
 	if d.HasChange("description") {
 		firewallTemplate.Description = d.Get("description").(string)
@@ -78,8 +81,8 @@ func resourceFirewallTemplateUpdate(ctx context.Context, d *schema.ResourceData,
 	return resourceFirewallTemplateRead(ctx, d, meta)
 }
 
-func resourceFirewallTemplateRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceFirewallTemplateRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	firewallTemplate, err := manager.GetFirewallTemplate(d.Id())
 	if err != nil {
 		return resourceReadCheck(d, err, "[ERROR-043]:")
@@ -87,7 +90,8 @@ func resourceFirewallTemplateRead(_ context.Context, d *schema.ResourceData, met
 
 	fields := map[string]interface{}{
 		"name":        firewallTemplate.Name,
-		"tags":        marshalTagNames(firewallTemplate.Tags),
+		"tags":        flattenTags(d, meta, firewallTemplate.Tags),
+		"tags_all":    marshalTagNames(firewallTemplate.Tags),
 		"description": firewallTemplate.Description,
 		"rules_count": firewallTemplate.RulesCount,
 		"vdc_id":      firewallTemplate.Vdc.ID,
@@ -100,8 +104,8 @@ func resourceFirewallTemplateRead(_ context.Context, d *schema.ResourceData, met
 	return nil
 }
 
-func resourceFirewallTemplateDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceFirewallTemplateDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	FirewallTemplate, err := manager.GetFirewallTemplate(d.Id())
 	if err != nil {
 		return diag.Errorf("[ERROR-043]: crash via getting FirewallTemplate: %s", err)
@@ -115,8 +119,8 @@ func resourceFirewallTemplateDelete(_ context.Context, d *schema.ResourceData, m
 	return nil
 }
 
-func resourceFirewallTemplateImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceFirewallTemplateImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	firewallTemplate, err := manager.GetFirewallTemplate(d.Id())
 	if err != nil {
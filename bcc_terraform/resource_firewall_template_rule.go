@@ -3,11 +3,11 @@ package bcc_terraform
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -27,6 +27,7 @@ func resourceFirewallRule() *schema.Resource {
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
 		Schema: args,
@@ -34,7 +35,7 @@ func resourceFirewallRule() *schema.Resource {
 }
 
 func resourceFirewallRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	firewallId := d.Get("firewall_id").(string)
 	firewall, err := manager.GetFirewallTemplate(firewallId)
@@ -60,13 +61,13 @@ func resourceFirewallRuleCreate(ctx context.Context, d *schema.ResourceData, met
 	}
 
 	d.SetId(newFirewallRule.ID)
-	log.Printf("[INFO]: firewall Rule created, ID: %s", d.Id())
+	tflog.Info(ctx, "Firewall Rule created", map[string]interface{}{"id": d.Id()})
 
 	return resourceFirewallRuleRead(ctx, d, meta)
 }
 
 func resourceFirewallRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	firewallId := d.Get("firewall_id").(string)
 	firewall, err := manager.GetFirewallTemplate(firewallId)
@@ -96,8 +97,8 @@ func resourceFirewallRuleUpdate(ctx context.Context, d *schema.ResourceData, met
 	return resourceFirewallRuleRead(ctx, d, meta)
 }
 
-func resourceFirewallRuleRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceFirewallRuleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	firewallId := d.Get("firewall_id").(string)
 	firewall, err := manager.GetFirewallTemplate(firewallId)
@@ -142,8 +143,8 @@ func resourceFirewallRuleRead(_ context.Context, d *schema.ResourceData, meta in
 	return nil
 }
 
-func resourceFirewallRuleDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceFirewallRuleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	firewallId := d.Get("firewall_id").(string)
 	firewall, err := manager.GetFirewallTemplate(firewallId)
@@ -165,8 +166,8 @@ func resourceFirewallRuleDelete(_ context.Context, d *schema.ResourceData, meta
 	return nil
 }
 
-func resourceFirewallImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceFirewallImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	id := d.Id()
 	ids := strings.Split(id, ",")
@@ -0,0 +1,61 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testAccFirewallRuleConfig(fake *fakeBcc, portRange string) string {
+	return testAccVdcConfig(fake) + fmt.Sprintf(`
+resource "basis_firewall_template" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-firewall"
+}
+
+resource "basis_firewall_template_rule" "test" {
+  firewall_id    = basis_firewall_template.test.id
+  name           = "http"
+  direction      = "ingress"
+  protocol       = "tcp"
+  port_range     = %q
+  destination_ip = "0.0.0.0/0"
+}
+`, portRange)
+}
+
+func TestAccFirewallRule_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_firewall_template_rule"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFirewallRuleConfig(fake, "80"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_firewall_template_rule.test"),
+					resource.TestCheckResourceAttr("basis_firewall_template_rule.test", "port_range", "80"),
+				),
+			},
+			{
+				Config: testAccFirewallRuleConfig(fake, "8000:8080"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_firewall_template_rule.test", "port_range", "8000:8080"),
+				),
+			},
+			{
+				ResourceName:      "basis_firewall_template_rule.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs := s.RootModule().Resources["basis_firewall_template_rule.test"]
+					return rs.Primary.Attributes["firewall_id"] + "," + rs.Primary.ID, nil
+				},
+			},
+		},
+	})
+}
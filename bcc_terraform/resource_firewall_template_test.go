@@ -0,0 +1,51 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccFirewallTemplate_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_firewall_template"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVdcConfig(fake) + `
+resource "basis_firewall_template" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-firewall"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_firewall_template.test"),
+					resource.TestCheckResourceAttr("basis_firewall_template.test", "name", "tf-acc-firewall"),
+					resource.TestCheckResourceAttr("basis_firewall_template.test", "rules_count", "0"),
+				),
+			},
+			{
+				Config: testAccVdcConfig(fake) + `
+resource "basis_firewall_template" "test" {
+  vdc_id      = basis_vdc.test.id
+  name        = "tf-acc-firewall-renamed"
+  description = "managed by terraform"
+  tags        = ["firewall"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_firewall_template.test", "name", "tf-acc-firewall-renamed"),
+					resource.TestCheckResourceAttr("basis_firewall_template.test", "description", "managed by terraform"),
+				),
+			},
+			{
+				ResourceName:      "basis_firewall_template.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
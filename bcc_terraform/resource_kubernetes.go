@@ -3,12 +3,13 @@ package bcc_terraform
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -23,19 +24,33 @@ func resourceKubernetes() *schema.Resource {
 		UpdateContext: resourceKubernetesUpdate,
 		ReadContext:   resourceKubernetesRead,
 		DeleteContext: resourceKubernetesDelete,
+		CustomizeDiff: customdiff.All(
+			customizeDiffDefaultVdc,
+			customizeDiffTemplateName(findKubernetesTemplateIdByName),
+			resourceKubernetesCustomizeDiff,
+			customizeDiffTagsAll,
+		),
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceKubernetesImport,
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
 		Schema: args,
 	}
 }
 
+func resourceKubernetesCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() != "" && d.HasChange("nodes_count") {
+		d.SetNewComputed("vms")
+	}
+	return nil
+}
+
 func resourceKubernetesCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	fields := struct {
 		Name             string `json:"name"`
 		NodeCpu          int    `json:"node_cpu"`
@@ -84,7 +99,12 @@ func resourceKubernetesCreate(ctx context.Context, d *schema.ResourceData, meta
 		return diag.Errorf("[ERROR-053]: user public key %s not found", fields.UserPublicKeyId)
 	}
 
-	log.Printf(fields.Name, fields.NodeCpu, fields.NodeRam, template.Name)
+	tflog.Debug(ctx, "Kubernetes create request", map[string]interface{}{
+		"name":     fields.Name,
+		"node_cpu": fields.NodeCpu,
+		"node_ram": fields.NodeRam,
+		"template": template.Name,
+	})
 
 	newKubernetes := bcc.NewKubernetes(
 		fields.Name, fields.NodeCpu, fields.NodeRam, fields.NodesCount, fields.NodeDiskSize,
@@ -103,25 +123,31 @@ func resourceKubernetesCreate(ctx context.Context, d *schema.ResourceData, meta
 		newKubernetes.Floating = &bcc.Port{IpAddress: &_floating}
 	}
 
-	newKubernetes.Tags = unmarshalTagNames(d.Get("tags"))
+	newKubernetes.Tags = expandTags(d, meta)
 
-	if err = vdc.CreateKubernetes(&newKubernetes); err != nil {
-		return diag.Errorf("[ERROR-053]: crash via creating Kubernetes: %s", err)
+	// Only the creation is serialized with the other resources of the vdc.
+	unlock, err := lockObject(ctx, meta, "vdc", vdc.ID)
+	if err != nil {
+		return diag.Errorf("[ERROR-053]: crash via vdc lock: %s", err)
 	}
-
-	if err = newKubernetes.WaitLock(); err != nil {
-		return diag.Errorf("[ERROR-053]: crash via wait lock")
+	err = vdc.CreateKubernetes(&newKubernetes)
+	unlock()
+	if err != nil {
+		return diag.Errorf("[ERROR-053]: crash via creating Kubernetes: %s", err)
 	}
 
 	d.SetId(newKubernetes.ID)
-	d.Set("user_public_key_id", pubKey.PublicKey)
-	log.Printf("[INFO] Kubernetes created, ID: %s", d.Id())
+	d.Set("user_public_key_id", pubKey.ID)
+	if err = waitLock(ctx, newKubernetes); err != nil {
+		return diag.Errorf("[ERROR-053]: crash via wait lock: %s", err)
+	}
+	tflog.Info(ctx, "Kubernetes created", map[string]interface{}{"id": d.Id()})
 
 	return resourceKubernetesRead(ctx, d, meta)
 }
 
 func resourceKubernetesUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	vdc, err := GetVdcById(d, manager)
 	if err != nil {
 		return diag.Errorf("[ERROR-053]: crash via getting VDC: %s", err)
@@ -139,9 +165,9 @@ func resourceKubernetesUpdate(ctx context.Context, d *schema.ResourceData, meta
 		needUpdate = true
 		kubernetes.Name = d.Get("name").(string)
 	}
-	if d.HasChange("tags") {
+	if d.HasChanges("tags", "tags_all") {
 		needUpdate = true
-		kubernetes.Tags = unmarshalTagNames(d.Get("tags"))
+		kubernetes.Tags = expandTags(d, meta)
 	}
 
 	spId := d.Get("node_storage_profile_id").(string)
@@ -167,6 +193,9 @@ func resourceKubernetesUpdate(ctx context.Context, d *schema.ResourceData, meta
 	if ncOld.(int) > ncNew.(int) {
 		return diag.Errorf("[ERROR-053]: cannot down scale Kubernetes 'nodes_count'")
 	}
+	if d.HasChanges("node_ram", "node_cpu", "node_disk_size", "nodes_count", "node_storage_profile_id", "user_public_key_id") {
+		needUpdate = true
+	}
 
 	if d.HasChange("floating") {
 		needUpdate = true
@@ -178,7 +207,7 @@ func resourceKubernetesUpdate(ctx context.Context, d *schema.ResourceData, meta
 	}
 
 	if needUpdate {
-		if err := repeatOnError(kubernetes.Update, kubernetes); err != nil {
+		if err := callUnlocked(ctx, kubernetes.Update, kubernetes); err != nil {
 			return diag.Errorf("[ERROR-053]: err with updating Kubernetes: %s", err)
 		}
 	}
@@ -187,7 +216,7 @@ func resourceKubernetesUpdate(ctx context.Context, d *schema.ResourceData, meta
 }
 
 func resourceKubernetesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	k8s, err := manager.GetKubernetes(d.Id())
 	if err != nil {
 		return resourceReadCheck(d, err, "[ERROR-053]:")
@@ -218,7 +247,8 @@ func resourceKubernetesRead(ctx context.Context, d *schema.ResourceData, meta in
 		"platform":                k8s.NodePlatform.ID,
 		"template_id":             k8s.Template.ID,
 		"node_storage_profile_id": k8s.NodeStorageProfile.ID,
-		"tags":                    marshalTagNames(k8s.Tags),
+		"tags":                    flattenTags(d, meta, k8s.Tags),
+		"tags_all":                marshalTagNames(k8s.Tags),
 		"vms":                     vms,
 		"floating":                false,
 		"floating_ip":             "",
@@ -238,7 +268,7 @@ func resourceKubernetesRead(ctx context.Context, d *schema.ResourceData, meta in
 }
 
 func resourceKubernetesDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	kubernetes, err := manager.GetKubernetes(d.Id())
 	if err != nil {
 		return diag.Errorf("[ERROR-053]: err with getting kubernetes 'id'=%s: %s", d.Id(), err)
@@ -248,13 +278,15 @@ func resourceKubernetesDelete(ctx context.Context, d *schema.ResourceData, meta
 	if err != nil {
 		return diag.Errorf("[ERROR-053]: crash via deleting Kubernetes: %s", err)
 	}
-	kubernetes.WaitLock()
+	if err := waitLock(ctx, kubernetes); err != nil && !isNotFound(err) {
+		return diag.Errorf("[ERROR-053]: crash via deleting Kubernetes: %s", err)
+	}
 
 	return nil
 }
 
 func resourceKubernetesImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	k8s, err := manager.GetKubernetes(d.Id())
 	if err != nil {
 		if err.(*bcc.ApiError).Code() == 404 {
@@ -0,0 +1,60 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func testAccKubernetesConfig(fake *fakeBcc, nodesCount int, floating bool) string {
+	return testAccVdcConfig(fake) + fmt.Sprintf(`
+resource "basis_kubernetes" "test" {
+  vdc_id                  = basis_vdc.test.id
+  name                    = "tf-acc-k8s"
+  template_id             = %q
+  node_cpu                = 2
+  node_ram                = 2
+  node_disk_size          = 20
+  nodes_count             = %d
+  node_storage_profile_id = %q
+  user_public_key_id      = %q
+  platform                = %q
+  floating                = %t
+}
+`, fakeKubernetesTemplateID, nodesCount, fakeStorageProfileID, fakePubKeyID, fakePlatformID, floating)
+}
+
+func TestAccKubernetes_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_kubernetes"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccKubernetesConfig(fake, 1, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_kubernetes.test"),
+					resource.TestCheckResourceAttr("basis_kubernetes.test", "vms.#", "1"),
+					resource.TestCheckResourceAttrSet("basis_kubernetes.test", "dashboard_url"),
+				),
+			},
+			{
+				Config: testAccKubernetesConfig(fake, 2, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_kubernetes.test", "nodes_count", "2"),
+					resource.TestCheckResourceAttr("basis_kubernetes.test", "vms.#", "2"),
+					resource.TestCheckResourceAttr("basis_kubernetes.test", "floating", "true"),
+					resource.TestCheckResourceAttrSet("basis_kubernetes.test", "floating_ip"),
+				),
+			},
+			{
+				ResourceName:      "basis_kubernetes.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
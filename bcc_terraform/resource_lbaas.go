@@ -3,10 +3,12 @@ package bcc_terraform
 import (
 	"context"
 	"fmt"
-	"log"
+	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -23,12 +25,18 @@ func resourceLbaas() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceLbaasImport,
 		},
-		Schema: args,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		CustomizeDiff: customdiff.All(customizeDiffDefaultVdc, customizeDiffTagsAll),
+		Schema:        args,
 	}
 }
 
 func resourceLbaasCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	portPrefix := "port.0"
 	fields := struct {
 		name         string
@@ -59,30 +67,36 @@ func resourceLbaasCreate(ctx context.Context, d *schema.ResourceData, meta inter
 	if err != nil {
 		return diag.Errorf("[ERROR-049]: crash via getting network by id=%s: %s", fields.port["network_id"].(string), err)
 	}
-	if err = network.WaitLock(); err != nil {
+	if err = waitLock(ctx, network); err != nil {
 		diag.Errorf("[ERROR-049]: crash via wait lock for network")
 	}
 
 	firewalls := make([]*bcc.FirewallTemplate, 0)
 	_port := bcc.NewPort(network, firewalls, fields.ipAddressStr)
 	lbaas := bcc.NewLoadBalancer(fields.name, vdc, &_port, fields.floatingIp)
-	lbaas.Tags = unmarshalTagNames(d.Get("tags"))
+	lbaas.Tags = expandTags(d, meta)
+
+	unlock, err := lockObject(ctx, meta, "vdc", vdc.ID)
+	if err != nil {
+		return diag.Errorf("[ERROR-049]: crash via vdc lock: %s", err)
+	}
+	defer unlock()
 
 	if err = vdc.CreateLoadBalancer(&lbaas); err != nil {
 		return diag.Errorf("[ERROR-049]: crash via creating Lbaas: %s", err)
 	}
-	if err = lbaas.WaitLock(); err != nil {
+	if err = waitLock(ctx, lbaas); err != nil {
 		diag.Errorf("[ERROR-049]: crash via wait lock for lbaas")
 	}
 
 	d.SetId(lbaas.ID)
-	log.Printf("[INFO] Lbaas created, ID: %s", d.Id())
+	tflog.Info(ctx, "Lbaas created", map[string]interface{}{"id": d.Id()})
 
 	return resourceLbaasRead(ctx, d, meta)
 }
 
 func resourceLbaasUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	lbaas, err := manager.GetLoadBalancer(d.Id())
 	if err != nil {
 		return diag.Errorf("[ERROR-049]: crash via getting Lbaas by 'id'=%s: %s", d.Id(), err)
@@ -98,31 +112,31 @@ func resourceLbaasUpdate(ctx context.Context, d *schema.ResourceData, meta inter
 			lbaas.Floating = &bcc.Port{ID: "RANDOM_FIP"}
 		}
 	}
-	if d.HasChange("tags") {
-		lbaas.Tags = unmarshalTagNames(d.Get("tags"))
+	if d.HasChanges("tags", "tags_all") {
+		lbaas.Tags = expandTags(d, meta)
 	}
 	lbaasPort := d.Get("port.0").(map[string]interface{})
 
-	if ipAddress := lbaasPort["ipAddress"]; ipAddress != nil {
-		if val, ok := ipAddress.(string); ok {
+	if ipAddress := lbaasPort["ip_address"]; ipAddress != nil {
+		if val, ok := ipAddress.(string); ok && val != "" {
 			if lbaas.Port.IpAddress == nil || *lbaas.Port.IpAddress != val {
 				lbaas.Port.IpAddress = &val
 			}
 		}
 	}
 
-	if err := repeatOnError(lbaas.Update, lbaas); err != nil {
+	if err := callUnlocked(ctx, lbaas.Update, lbaas); err != nil {
 		return diag.Errorf("[ERROR-049]: crash via updating lbaas: %s", err)
 	}
-	if err = lbaas.WaitLock(); err != nil {
+	if err = waitLock(ctx, lbaas); err != nil {
 		diag.Errorf("[ERROR-049]: crash via wait lock for lbaas")
 	}
 
 	return resourceLbaasRead(ctx, d, meta)
 }
 
-func resourceLbaasRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceLbaasRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	lbaas, err := manager.GetLoadBalancer(d.Id())
 	if err != nil {
 		return resourceReadCheck(d, err, "[ERROR-049]:")
@@ -140,7 +154,8 @@ func resourceLbaasRead(_ context.Context, d *schema.ResourceData, meta interface
 		"floating_ip": "",
 		"port":        lbaasPort,
 		"vdc_id":      lbaas.Vdc.ID,
-		"tags":        marshalTagNames(lbaas.Tags),
+		"tags":        flattenTags(d, meta, lbaas.Tags),
+		"tags_all":    marshalTagNames(lbaas.Tags),
 	}
 	if lbaas.Floating != nil {
 		fields["floating"] = true
@@ -154,8 +169,8 @@ func resourceLbaasRead(_ context.Context, d *schema.ResourceData, meta interface
 	return nil
 }
 
-func resourceLbaasDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceLbaasDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	lbaasId := d.Id()
 	lbaas, err := manager.GetLoadBalancer(lbaasId)
@@ -166,13 +181,15 @@ func resourceLbaasDelete(_ context.Context, d *schema.ResourceData, meta interfa
 	if err = lbaas.Delete(); err != nil {
 		return diag.Errorf("[ERROR-049]: crash via deleting lbaas: %s", err)
 	}
-	lbaas.WaitLock()
+	if err := waitLock(ctx, lbaas); err != nil && !isNotFound(err) {
+		return diag.Errorf("[ERROR-049]: crash via deleting lbaas: %s", err)
+	}
 
 	return nil
 }
 
-func resourceLbaasImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceLbaasImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	lbaas, err := manager.GetLoadBalancer(d.Id())
 	if err != nil {
 		return nil, fmt.Errorf("[ERROR-049]: crash via getting Lbaas by 'id'=%s: %s", d.Id(), err)
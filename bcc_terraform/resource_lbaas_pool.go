@@ -3,10 +3,11 @@ package bcc_terraform
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
+	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -24,12 +25,17 @@ func resourceLbaasPool() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceLbaasPoolImport,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
 		Schema: args,
 	}
 }
 
 func resourceLbaasPoolCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	type member struct {
 		Id     string `json:"id"`
@@ -87,18 +93,24 @@ func resourceLbaasPoolCreate(ctx context.Context, d *schema.ResourceData, meta i
 	if err = lbaas.CreatePool(&newPool); err != nil {
 		return diag.Errorf("[ERROR-050]: crash via creating Lbaas pool: %s", err)
 	}
-	if err = lbaas.WaitLock(); err != nil {
+	if err = waitLock(ctx, lbaas); err != nil {
 		return diag.Errorf("[ERROR-050]: %s", err)
 	}
 
 	d.SetId(newPool.ID)
-	log.Printf("[INFO] Lbaas Pool created, ID: %s", d.Id())
+	tflog.Info(ctx, "Lbaas Pool created", map[string]interface{}{"id": d.Id()})
 
 	return resourceLbaasPoolRead(ctx, d, meta)
 }
 
 func resourceLbaasPoolUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+
+	unlock, err := lockObject(ctx, meta, "lbaas", d.Get("lbaas_id").(string))
+	if err != nil {
+		return diag.Errorf("[ERROR-050]: %s", err)
+	}
+	defer unlock()
 
 	lbaas, err := manager.GetLoadBalancer(d.Get("lbaas_id").(string))
 	if err != nil {
@@ -134,6 +146,11 @@ func resourceLbaasPoolUpdate(ctx context.Context, d *schema.ResourceData, meta i
 	if d.HasChange("member") {
 		membersCount := d.Get("member.#").(int)
 		members := make([]*bcc.PoolMember, membersCount)
+		if !d.Get("exclusive_members").(bool) {
+			oldMembers, _ := d.GetChange("member")
+			members = append(filterPoolMembers(oldMembers.([]interface{}), lbaasPool.Members, false), members...)
+		}
+		offset := len(members) - membersCount
 		for i := 0; i < membersCount; i++ {
 			memberPrefix := fmt.Sprint("member.", i)
 			member := d.Get(memberPrefix).(map[string]interface{})
@@ -150,7 +167,7 @@ func resourceLbaasPoolUpdate(ctx context.Context, d *schema.ResourceData, meta i
 			}
 
 			newMember := bcc.NewLoadBalancerPoolMember(port, weight, &tmpVm)
-			members[i] = &newMember
+			members[offset+i] = &newMember
 		}
 		lbaasPool.Members = members
 	}
@@ -158,7 +175,7 @@ func resourceLbaasPoolUpdate(ctx context.Context, d *schema.ResourceData, meta i
 	if err != nil {
 		return diag.Errorf("[ERROR-050]: crash via updating Lbaas lbaasPool: %s", err)
 	}
-	if err = lbaas.WaitLock(); err != nil {
+	if err = waitLock(ctx, lbaas); err != nil {
 		return diag.Errorf("[ERROR-050]: %s", err)
 	}
 
@@ -166,7 +183,7 @@ func resourceLbaasPoolUpdate(ctx context.Context, d *schema.ResourceData, meta i
 }
 
 func resourceLbaasPoolRead(ctx context.Context, d *schema.ResourceData, meta interface{}) (diagErr diag.Diagnostics) {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	lbaasId := d.Get("lbaas_id").(string)
 
 	lbaas, err := manager.GetLoadBalancer(lbaasId)
@@ -176,11 +193,15 @@ func resourceLbaasPoolRead(ctx context.Context, d *schema.ResourceData, meta int
 
 	lbaasPool, err := lbaas.GetLoadBalancerPool(d.Id())
 	if err != nil {
-		return resourceLbaasPoolDelete(ctx, d, "[ERROR-050]:")
+		return resourceReadCheck(d, err, "[ERROR-050]:")
 	}
 
-	poolMembers := make([]map[string]interface{}, len(lbaasPool.Members))
-	for i, member := range lbaasPool.Members {
+	members := lbaasPool.Members
+	if !d.Get("exclusive_members").(bool) {
+		members = filterPoolMembers(d.Get("member").([]interface{}), members, true)
+	}
+	poolMembers := make([]map[string]interface{}, len(members))
+	for i, member := range members {
 		poolMembers[i] = map[string]interface{}{
 			"port":   member.Port,
 			"weight": member.Weight,
@@ -206,8 +227,8 @@ func resourceLbaasPoolRead(ctx context.Context, d *schema.ResourceData, meta int
 	return
 }
 
-func resourceLbaasPoolDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceLbaasPoolDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	lbaas, err := manager.GetLoadBalancer(d.Get("lbaas_id").(string))
 	if err != nil {
@@ -222,13 +243,15 @@ func resourceLbaasPoolDelete(_ context.Context, d *schema.ResourceData, meta int
 	if err := lbaas.DeletePool(d.Id()); err != nil {
 		return diag.Errorf("[ERROR-050] crash via deletting LbaasPool: %s", err)
 	}
-	lbaas.WaitLock()
+	if err := waitLock(ctx, lbaas); err != nil {
+		return diag.Errorf("[ERROR-050] crash via deletting LbaasPool: %s", err)
+	}
 
 	return nil
 }
 
-func resourceLbaasPoolImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceLbaasPoolImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	id := d.Id()
 	ids := strings.Split(id, ",")
@@ -247,6 +270,26 @@ func resourceLbaasPoolImport(_ context.Context, d *schema.ResourceData, meta int
 	if err := d.Set("lbaas_id", lbaas.ID); err != nil {
 		return nil, fmt.Errorf("[ERROR-050]: crasg via setting lbaas_id: %s", err)
 	}
+	if err := d.Set("exclusive_members", true); err != nil {
+		return nil, fmt.Errorf("[ERROR-050]: crash via setting exclusive_members: %s", err)
+	}
 
 	return []*schema.ResourceData{d}, nil
 }
+
+// filterPoolMembers keeps the members of the Vms listed in `member`, or with
+// owned unset the other members, which are registered by basis_vm_group or
+// outside of Terraform.
+func filterPoolMembers(configured []interface{}, members []*bcc.PoolMember, owned bool) []*bcc.PoolMember {
+	listed := make(map[string]bool, len(configured))
+	for _, item := range configured {
+		listed[item.(map[string]interface{})["vm_id"].(string)] = true
+	}
+	filtered := make([]*bcc.PoolMember, 0, len(members))
+	for _, member := range members {
+		if (member.Vm != nil && listed[member.Vm.ID]) == owned {
+			filtered = append(filtered, member)
+		}
+	}
+	return filtered
+}
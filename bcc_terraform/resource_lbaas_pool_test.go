@@ -0,0 +1,73 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testAccLbaasPoolConfig(fake *fakeBcc, port int, weight int) string {
+	return testAccVmConfig(fake, "tf-acc-vm") + fmt.Sprintf(`
+resource "basis_lbaas" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-lbaas"
+
+  port {
+    network_id = basis_network.test.id
+    ip_address = "10.0.2.60"
+  }
+}
+
+resource "basis_lbaas_pool" "test" {
+  lbaas_id = basis_lbaas.test.id
+  port     = %d
+  method   = "ROUND_ROBIN"
+  protocol = "TCP"
+
+  member {
+    vm_id  = basis_vm.test.id
+    port   = 8080
+    weight = %d
+  }
+}
+`, port, weight)
+}
+
+func TestAccLbaasPool_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_lbaas_pool"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLbaasPoolConfig(fake, 80, 1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_lbaas_pool.test"),
+					resource.TestCheckResourceAttr("basis_lbaas_pool.test", "port", "80"),
+					resource.TestCheckResourceAttr("basis_lbaas_pool.test", "member.#", "1"),
+					resource.TestCheckResourceAttrPair("basis_lbaas_pool.test", "member.0.vm_id", "basis_vm.test", "id"),
+				),
+			},
+			{
+				Config: testAccLbaasPoolConfig(fake, 443, 5),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_lbaas_pool.test", "port", "443"),
+					resource.TestCheckResourceAttr("basis_lbaas_pool.test", "member.0.weight", "5"),
+				),
+			},
+			{
+				ResourceName:      "basis_lbaas_pool.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs := s.RootModule().Resources["basis_lbaas_pool.test"]
+					return rs.Primary.Attributes["lbaas_id"] + "," + rs.Primary.ID, nil
+				},
+			},
+		},
+	})
+}
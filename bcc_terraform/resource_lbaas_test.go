@@ -0,0 +1,56 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func testAccLbaasConfig(fake *fakeBcc, name string, floating bool) string {
+	return testAccNetworkConfig(fake) + fmt.Sprintf(`
+resource "basis_lbaas" "test" {
+  vdc_id   = basis_vdc.test.id
+  name     = %q
+  floating = %t
+
+  port {
+    network_id = basis_network.test.id
+    ip_address = "10.0.2.60"
+  }
+}
+`, name, floating)
+}
+
+func TestAccLbaas_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_lbaas"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLbaasConfig(fake, "tf-acc-lbaas", false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_lbaas.test"),
+					resource.TestCheckResourceAttr("basis_lbaas.test", "port.0.ip_address", "10.0.2.60"),
+					resource.TestCheckResourceAttr("basis_lbaas.test", "floating", "false"),
+				),
+			},
+			{
+				Config: testAccLbaasConfig(fake, "tf-acc-lbaas-renamed", true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_lbaas.test", "name", "tf-acc-lbaas-renamed"),
+					resource.TestCheckResourceAttr("basis_lbaas.test", "floating", "true"),
+					resource.TestCheckResourceAttrSet("basis_lbaas.test", "floating_ip"),
+				),
+			},
+			{
+				ResourceName:      "basis_lbaas.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
@@ -3,10 +3,12 @@ package bcc_terraform
 import (
 	"context"
 	"fmt"
-	"log"
+	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -23,30 +25,25 @@ func resourceNetwork() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceNetworkImport,
 		},
-		Schema: args,
-		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
-			if d.Id() != "" {
-				if d.HasChange("subnets.0.cidr") {
-					oldNet, newNet := d.GetChange("subnets.0.cidr")
-					if oldNet.(string) != newNet.(string) {
-						return fmt.Errorf("[ERROR-009]: changing 'cidr' attribute is not supported")
-					}
-				}
-			}
-			return nil
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
+		Schema:        args,
+		CustomizeDiff: customdiff.All(customizeDiffDefaultVdc, customizeDiffNetworkSubnets, customizeDiffTagsAll),
 	}
 }
 
 func resourceNetworkCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	vdc, err := GetVdcById(d, manager)
 	if err != nil {
 		return diag.Errorf("[ERROR-009]: %s", err)
 	}
 
 	network := bcc.NewNetwork(d.Get("name").(string))
-	network.Tags = unmarshalTagNames(d.Get("tags"))
+	network.Tags = expandTags(d, meta)
 
 	if mtu, ok := d.GetOk("mtu"); ok {
 		mtuValue := mtu.(int)
@@ -55,32 +52,38 @@ func resourceNetworkCreate(ctx context.Context, d *schema.ResourceData, meta int
 		network.Mtu = nil
 	}
 
-	if err = vdc.WaitLock(); err != nil {
+	unlock, err := lockObject(ctx, meta, "vdc", vdc.ID)
+	if err != nil {
+		return diag.Errorf("[ERROR-009]: %s", err)
+	}
+	defer unlock()
+
+	if err = waitLock(ctx, vdc); err != nil {
 		return diag.Errorf("[ERROR-009]: crash via wait lock %s", err)
 	}
 	if err = vdc.CreateNetwork(&network); err != nil {
 		return diag.Errorf("[ERROR-009]: crash via creating %s", err)
 	}
-	if err = vdc.WaitLock(); err != nil {
+	if err = waitLock(ctx, vdc); err != nil {
 		return diag.Errorf("[ERROR-009]: crash via wait lock %s", err)
 	}
 
-	if err = createSubnet(d, manager, &network); err != nil {
-		return diag.Errorf("[ERROR-009]: crash via creating gsub nets%s", err)
+	if err = createSubnets(ctx, d, &network); err != nil {
+		return diag.Errorf("[ERROR-009]: %s", err)
 	}
-	if err = network.WaitLock(); err != nil {
+	if err = waitLock(ctx, network); err != nil {
 		return diag.Errorf("[ERROR-009]: crash via waitlock %s", err)
 	}
 
 	d.SetId(network.ID)
-	log.Printf("[INFO] Network created, ID: %s", d.Id())
+	tflog.Info(ctx, "Network created", map[string]interface{}{"id": d.Id()})
 
 	return resourceNetworkRead(ctx, d, meta)
 }
 
 func resourceNetworkUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	network, err := manager.GetNetwork(d.Id())
 	if err != nil {
@@ -88,8 +91,8 @@ func resourceNetworkUpdate(ctx context.Context, d *schema.ResourceData, meta int
 	}
 	needUpdate := false
 
-	if d.HasChange("tags") {
-		network.Tags = unmarshalTagNames(d.Get("tags"))
+	if d.HasChanges("tags", "tags_all") {
+		network.Tags = expandTags(d, meta)
 		needUpdate = true
 	}
 
@@ -113,21 +116,26 @@ func resourceNetworkUpdate(ctx context.Context, d *schema.ResourceData, meta int
 		}
 	}
 
-	if d.HasChange("subnets") {
-		diagErr := updateSubnet(d, manager)
-		if diagErr != nil {
-			return diagErr
+	if d.HasChanges("subnets", "exclusive_subnets") {
+		unlock, err := lockObject(ctx, meta, "network", network.ID)
+		if err != nil {
+			return diag.Errorf("[ERROR-009]: %s", err)
+		}
+		defer unlock()
+
+		if err = updateSubnets(ctx, d, network); err != nil {
+			return diag.Errorf("[ERROR-009]: %s", err)
 		}
 	}
-	if err = network.WaitLock(); err != nil {
+	if err = waitLock(ctx, network); err != nil {
 		return diag.Errorf("[ERROR-009]: %s", err)
 	}
 
 	return resourceNetworkRead(ctx, d, meta)
 }
 
-func resourceNetworkRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceNetworkRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	network, err := manager.GetNetwork(d.Id())
 	if err != nil {
 		return resourceReadCheck(d, err, "[ERROR-009]:")
@@ -138,28 +146,12 @@ func resourceNetworkRead(_ context.Context, d *schema.ResourceData, meta interfa
 		return diag.Errorf("[ERROR-009]: %s", err)
 	}
 
-	subnetsMap := make([]map[string]interface{}, len(subnets))
-	for i, subnet := range subnets {
-		dnsStrings := make([]string, len(subnet.DnsServers))
-		for i2, dns := range subnet.DnsServers {
-			dnsStrings[i2] = dns.DNSServer
-		}
-		subnetsMap[i] = map[string]interface{}{
-			"id":       subnet.ID,
-			"cidr":     subnet.CIDR,
-			"dhcp":     subnet.IsDHCP,
-			"gateway":  subnet.Gateway,
-			"start_ip": subnet.StartIp,
-			"end_ip":   subnet.EndIp,
-			"dns":      dnsStrings,
-		}
-	}
-
 	fields := map[string]interface{}{
 		"name":     network.Name,
-		"tags":     marshalTagNames(network.Tags),
+		"tags":     flattenTags(d, meta, network.Tags),
+		"tags_all": marshalTagNames(network.Tags),
 		"mtu":      network.Mtu,
-		"subnets":  subnetsMap,
+		"subnets":  flattenNetworkSubnets(d, subnets),
 		"vdc_id":   network.Vdc.Id,
 		"external": network.External,
 	}
@@ -171,28 +163,33 @@ func resourceNetworkRead(_ context.Context, d *schema.ResourceData, meta interfa
 	return nil
 }
 
-func resourceNetworkDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceNetworkDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	network, err := manager.GetNetwork(d.Id())
 	if err != nil {
 		return diag.Errorf("[ERROR-009]: %s", err)
 	}
 
-	if err = repeatOnError(network.Delete, network); err != nil {
+	if err = callUnlocked(ctx, network.Delete, network); err != nil {
+		return diag.Errorf("[ERROR-009]: crash via deleting network-%s: %s", d.Id(), err)
+	}
+	if err := waitLock(ctx, network); err != nil && !isNotFound(err) {
 		return diag.Errorf("[ERROR-009]: crash via deleting network-%s: %s", d.Id(), err)
 	}
-	network.WaitLock()
 
 	return nil
 }
 
-func resourceNetworkImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceNetworkImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	network, err := manager.GetNetwork(d.Id())
 	if err != nil {
 		return nil, fmt.Errorf("[ERROR-009]: crash via getting network-%s: %s", d.Id(), err)
 	}
 
 	d.SetId(network.ID)
+	if err = d.Set("exclusive_subnets", true); err != nil {
+		return nil, fmt.Errorf("[ERROR-009]: crash via set attrs: %s", err)
+	}
 	return []*schema.ResourceData{d}, nil
 }
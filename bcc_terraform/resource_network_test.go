@@ -0,0 +1,153 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccNetwork_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_network"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNetworkConfig(fake),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_network.test"),
+					resource.TestCheckResourceAttr("basis_network.test", "name", "tf-acc-network"),
+					resource.TestCheckResourceAttr("basis_network.test", "subnets.#", "1"),
+					resource.TestCheckResourceAttr("basis_network.test", "subnets.0.cidr", "10.0.2.0/24"),
+					resource.TestCheckResourceAttr("basis_network.test", "subnets.0.dns.0", "8.8.8.8"),
+				),
+			},
+			{
+				Config: testAccVdcConfig(fake) + `
+resource "basis_network" "test" {
+  name   = "tf-acc-network-renamed"
+  vdc_id = basis_vdc.test.id
+
+  subnets {
+    cidr     = "10.0.2.0/24"
+    dhcp     = false
+    gateway  = "10.0.2.1"
+    start_ip = "10.0.2.20"
+    end_ip   = "10.0.2.200"
+    dns      = ["1.1.1.1", "8.8.8.8"]
+  }
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_network.test", "name", "tf-acc-network-renamed"),
+					resource.TestCheckResourceAttr("basis_network.test", "subnets.0.dhcp", "false"),
+					resource.TestCheckResourceAttr("basis_network.test", "subnets.0.start_ip", "10.0.2.20"),
+					resource.TestCheckResourceAttr("basis_network.test", "subnets.0.dns.#", "2"),
+				),
+			},
+			{
+				ResourceName:      "basis_network.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccNetworkSubnetsConfig(fake *fakeBcc, secondCidr string) string {
+	prefix := strings.TrimSuffix(secondCidr, "0/24")
+	return testAccVdcConfig(fake) + fmt.Sprintf(`
+resource "basis_network" "test" {
+  name   = "tf-acc-network"
+  vdc_id = basis_vdc.test.id
+
+  subnets {
+    cidr     = "10.0.2.0/24"
+    dhcp     = true
+    gateway  = "10.0.2.1"
+    start_ip = "10.0.2.2"
+    end_ip   = "10.0.2.254"
+  }
+
+  subnets {
+    cidr     = %[1]q
+    dhcp     = true
+    gateway  = "%[2]s1"
+    start_ip = "%[2]s2"
+    end_ip   = "%[2]s254"
+  }
+}
+`, secondCidr, prefix)
+}
+
+func TestAccNetwork_subnets(t *testing.T) {
+	fake := newFakeBcc(t)
+	var firstSubnetId string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_network"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNetworkSubnetsConfig(fake, "10.0.3.0/24"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_network.test", "subnets.#", "2"),
+					resource.TestCheckResourceAttr("basis_network.test", "subnets.1.cidr", "10.0.3.0/24"),
+					resource.TestCheckResourceAttrWith("basis_network.test", "subnets.0.id", func(value string) error {
+						firstSubnetId = value
+						return nil
+					}),
+				),
+			},
+			{
+				Config: testAccNetworkSubnetsConfig(fake, "10.0.4.0/24"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_network.test", "subnets.#", "2"),
+					resource.TestCheckResourceAttr("basis_network.test", "subnets.1.cidr", "10.0.4.0/24"),
+					resource.TestCheckResourceAttr("basis_network.test", "subnets.1.gateway", "10.0.4.1"),
+					resource.TestCheckResourceAttrWith("basis_network.test", "subnets.0.id", func(value string) error {
+						if value != firstSubnetId {
+							return fmt.Errorf("the subnet 10.0.2.0/24 was replaced: %s -> %s", firstSubnetId, value)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccNetwork_validation(t *testing.T) {
+	fake := newFakeBcc(t)
+	config := testAccNetworkSubnetsConfig(fake, "10.0.3.0/24")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_network"),
+		Steps: []resource.TestStep{
+			{
+				Config:      strings.Replace(config, `gateway  = "10.0.2.1"`, `gateway  = "10.0.2.100"`, 1),
+				ExpectError: regexp.MustCompile(`subnets.0.gateway: 10.0.2.100 is inside the pool 10.0.2.2 - 10.0.2.254`),
+			},
+			{
+				Config:      testAccNetworkSubnetsConfig(fake, "10.0.2.0/24"),
+				ExpectError: regexp.MustCompile(`subnets.1.cidr: 10.0.2.0/24 overlaps the cidr 10.0.2.0/24 of subnets.0`),
+			},
+			{
+				Config:      testAccNetworkSubnetsConfig(fake, "10.0.1.0/24"),
+				ExpectError: regexp.MustCompile(`subnets.1.cidr: 10.0.1.0/24 overlaps the subnet ` + fakeDefaultNetworkCIDR),
+			},
+			{
+				Config:      strings.Replace(config, `cidr     = "10.0.2.0/24"`, `cidr     = "10.0.2.1/24"`, 1),
+				ExpectError: regexp.MustCompile(`did you mean 10.0.2.0/24\?`),
+			},
+		},
+	})
+}
@@ -20,8 +20,10 @@ func resourcePaasService() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourcePaasServiceImport,
 		},
+		CustomizeDiff: customizeDiffDefaultVdc,
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
 		Schema: map[string]*schema.Schema{
@@ -32,9 +34,10 @@ func resourcePaasService() *schema.Resource {
 			},
 			"vdc_id": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
 				ForceNew:    true,
-				Description: "id of Vdc",
+				Description: "id of Vdc, the provider `vdc_id` by default",
 			},
 			"paas_service_id": {
 				Type:        schema.TypeInt,
@@ -52,10 +55,9 @@ func resourcePaasService() *schema.Resource {
 }
 
 func resourcePaasServiceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
-	manager = manager.WithContext(ctx)
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
-	err := ensureLocationCreated(d.Get("vdc_id").(string), manager)
+	err := ensureLocationCreated(ctx, d.Get("vdc_id").(string), manager)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -79,7 +81,9 @@ func resourcePaasServiceCreate(ctx context.Context, d *schema.ResourceData, meta
 	if err := manager.CreatePaasService(service); err != nil {
 		return diag.Errorf("Error creating Paas Service: %s", err)
 	}
-	service.WaitLock()
+	if err := waitLock(ctx, service); err != nil {
+		return diag.Errorf("Error creating Paas Service: %s", err)
+	}
 
 	d.SetId(service.ID)
 
@@ -87,9 +91,8 @@ func resourcePaasServiceCreate(ctx context.Context, d *schema.ResourceData, meta
 }
 
 func resourcePaasServiceUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
-	manager = manager.WithContext(ctx)
-	err := ensureLocationCreated(d.Get("vdc_id").(string), manager)
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	err := ensureLocationCreated(ctx, d.Get("vdc_id").(string), manager)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -107,16 +110,17 @@ func resourcePaasServiceUpdate(ctx context.Context, d *schema.ResourceData, meta
 	if err := service.Update(); err != nil {
 		return diag.Errorf("Error updating Paas Service: %s", err)
 	}
-	service.WaitLock()
+	if err := waitLock(ctx, service); err != nil {
+		return diag.Errorf("Error updating Paas Service: %s", err)
+	}
 
 	return resourcePaasServiceRead(ctx, d, meta)
 }
 
 func resourcePaasServiceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) (diagErr diag.Diagnostics) {
-	manager := meta.(*CombinedConfig).Manager()
-	manager = manager.WithContext(ctx)
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
-	err := ensureLocationCreated(d.Get("vdc_id").(string), manager)
+	err := ensureLocationCreated(ctx, d.Get("vdc_id").(string), manager)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -151,33 +155,33 @@ func resourcePaasServiceRead(ctx context.Context, d *schema.ResourceData, meta i
 }
 
 func resourcePaasServiceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
-	manager = manager.WithContext(ctx)
-	err := ensureLocationCreated(d.Get("vdc_id").(string), manager)
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	err := ensureLocationCreated(ctx, d.Get("vdc_id").(string), manager)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	service, err := manager.GetPaasService(d.Get("id").(string))
+	service, err := manager.GetPaasService(d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	err = manager.DeletePaasService(d.Get("id").(string))
+	err = manager.DeletePaasService(d.Id())
 	if err != nil {
 		return diag.Errorf("Error deleting Paas Service: %s", err)
 	}
-	service.WaitLock()
+	if err := waitLock(ctx, service); err != nil && !isNotFound(err) {
+		return diag.Errorf("Error deleting Paas Service: %s", err)
+	}
 	return nil
 }
 
 func resourcePaasServiceImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
-	manager = manager.WithContext(ctx)
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	service, err := manager.GetPaasService(d.Id())
 	if err != nil {
 		return nil, fmt.Errorf("error getting Paas Service: %s", err)
 	}
-	if err := ensureLocationCreated(service.Vdc.ID, manager); err != nil {
+	if err := ensureLocationCreated(ctx, service.Vdc.ID, manager); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,54 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func testAccPaasServiceConfig(fake *fakeBcc, name string, dbName string) string {
+	return testAccVdcConfig(fake) + fmt.Sprintf(`
+resource "basis_paas_service" "test" {
+  vdc_id          = basis_vdc.test.id
+  name            = %q
+  paas_service_id = %d
+
+  paas_service_inputs = jsonencode({
+    db_name = %q
+    db_size = 10
+  })
+}
+`, name, fakePaasTemplateID, dbName)
+}
+
+func TestAccPaasService_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_paas_service"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPaasServiceConfig(fake, "tf-acc-paas", "app"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_paas_service.test"),
+					resource.TestCheckResourceAttr("basis_paas_service.test", "paas_service_id", "1"),
+				),
+			},
+			{
+				Config: testAccPaasServiceConfig(fake, "tf-acc-paas-renamed", "billing"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_paas_service.test", "name", "tf-acc-paas-renamed"),
+					resource.TestCheckResourceAttr("basis_paas_service.test", "paas_service_inputs", `{"db_name":"billing","db_size":10}`),
+				),
+			},
+			{
+				ResourceName:      "basis_paas_service.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
@@ -2,11 +2,12 @@ package bcc_terraform
 
 import (
 	"context"
-	"log"
 	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -24,14 +25,16 @@ func resourcePort() *schema.Resource {
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
-		Schema: args,
+		CustomizeDiff: customdiff.All(customizeDiffPortIp, customizeDiffTagsAll),
+		Schema:        args,
 	}
 }
 
 func resourcePortCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	fields := struct {
 		ipAddressStr          string
 		vdcId                 string
@@ -78,26 +81,32 @@ func resourcePortCreate(ctx context.Context, d *schema.ResourceData, meta interf
 	if vdcOk {
 		port.Vdc = vdc
 	}
-	port.Tags = unmarshalTagNames(d.Get("tags"))
+	port.Tags = expandTags(d, meta)
 
-	if err = vdc.WaitLock(); err != nil {
+	unlock, err := lockObject(ctx, meta, "vdc", vdc.ID)
+	if err != nil {
+		return diag.Errorf("[ERROR-045] crash via vdc lock: %s", err)
+	}
+	defer unlock()
+
+	if err = waitLock(ctx, vdc); err != nil {
 		return diag.Errorf("[ERROR-045] crash via wait lock for vdc: %s", err)
 	}
 	if err = vdc.CreateEmptyPort(&port); err != nil {
 		return diag.Errorf("[ERROR-045] crash via creating empty port: %s", err)
 	}
-	if err = port.WaitLock(); err != nil {
+	if err = waitLock(ctx, port); err != nil {
 		return diag.Errorf("[ERROR-045] crash via wait lock for port: %s", err)
 	}
 
 	d.SetId(port.ID)
-	log.Printf("[INFO] Port created, ID: %s", d.Id())
+	tflog.Info(ctx, "Port created", map[string]interface{}{"id": d.Id()})
 
 	return resourcePortRead(ctx, d, meta)
 }
 
 func resourcePortUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	portId := d.Id()
 	port, err := manager.GetPort(portId)
@@ -105,8 +114,8 @@ func resourcePortUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 		return diag.Errorf("[ERROR-045] crash via get port: %s", err)
 	}
 
-	if d.HasChange("tags") {
-		port.Tags = unmarshalTagNames(d.Get("tags"))
+	if d.HasChanges("tags", "tags_all") {
+		port.Tags = expandTags(d, meta)
 	}
 
 	if d.HasChange("ip_address") {
@@ -131,14 +140,14 @@ func resourcePortUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 	if err = port.Update(); err != nil {
 		return diag.Errorf("[ERROR-045] crash via updating port: %s", err)
 	}
-	if err = port.WaitLock(); err != nil {
+	if err = waitLock(ctx, port); err != nil {
 		return diag.Errorf("[ERROR-045] crash via port waitlock: %s", err)
 	}
 	return resourcePortRead(ctx, d, meta)
 }
 
 func resourcePortRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	port, err := manager.GetPort(d.Id())
 	if err != nil {
 		return resourceReadCheck(d, err, "[ERROR-045]:")
@@ -153,7 +162,8 @@ func resourcePortRead(ctx context.Context, d *schema.ResourceData, meta interfac
 		"ip_address":         port.IpAddress,
 		"network_id":         port.Network.ID,
 		"vdc_id":             port.Vdc.ID,
-		"tags":               marshalTagNames(port.Tags),
+		"tags":               flattenTags(d, meta, port.Tags),
+		"tags_all":           marshalTagNames(port.Tags),
 		"firewall_templates": firewallTemplates,
 	}
 
@@ -165,11 +175,15 @@ func resourcePortRead(ctx context.Context, d *schema.ResourceData, meta interfac
 }
 
 func resourcePortDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	portId := d.Id()
 
 	port, err := manager.GetPort(portId)
 	if err != nil {
+		// The port may be deleted with its vm, see on_destroy of basis_vm.
+		if isNotFound(err) {
+			return nil
+		}
 		return diag.Errorf("[ERROR-045] crash via getting port: %s", err)
 	}
 
@@ -177,16 +191,18 @@ func resourcePortDelete(ctx context.Context, d *schema.ResourceData, meta interf
 	if err != nil {
 		return diag.Errorf("[ERROR-045] crash via deleting port: %s", err)
 	}
-	port.WaitLock()
+	if err := waitLock(ctx, port); err != nil && !isNotFound(err) {
+		return diag.Errorf("[ERROR-045] crash via deleting port: %s", err)
+	}
 
 	return nil
 }
 
 func resourcePortImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	port, err := manager.GetPort(d.Id())
 	if err != nil {
-		log.Printf("[ERROR-045] crash via getting port by id='%s': %s", d.Id(), err)
+		tflog.Error(ctx, "[ERROR-045] crash via getting port", map[string]interface{}{"id": d.Id(), "error": err.Error()})
 		return nil, err
 	}
 
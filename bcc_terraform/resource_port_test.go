@@ -0,0 +1,70 @@
+package bcc_terraform
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPort_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_port"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNetworkConfig(fake) + `
+resource "basis_port" "test" {
+  vdc_id     = basis_vdc.test.id
+  network_id = basis_network.test.id
+  ip_address = "10.0.2.50"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_port.test"),
+					resource.TestCheckResourceAttr("basis_port.test", "ip_address", "10.0.2.50"),
+					resource.TestCheckResourceAttrPair("basis_port.test", "network_id", "basis_network.test", "id"),
+				),
+			},
+			{
+				Config: testAccNetworkConfig(fake) + `
+resource "basis_firewall_template" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-firewall"
+}
+
+resource "basis_port" "test" {
+  vdc_id             = basis_vdc.test.id
+  network_id         = basis_network.test.id
+  ip_address         = "10.0.2.51"
+  firewall_templates = [basis_firewall_template.test.id]
+  tags               = ["port"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_port.test", "ip_address", "10.0.2.51"),
+					resource.TestCheckResourceAttr("basis_port.test", "firewall_templates.#", "1"),
+					resource.TestCheckResourceAttr("basis_port.test", "tags.#", "1"),
+				),
+			},
+			{
+				Config: testAccNetworkConfig(fake) + `
+resource "basis_port" "test" {
+  vdc_id     = basis_vdc.test.id
+  network_id = basis_network.test.id
+  ip_address = "10.0.3.51"
+}
+`,
+				ExpectError: regexp.MustCompile(`ip_address: 10.0.3.51 is not inside a subnet of the network tf-acc-network \[10.0.2.0/24\]`),
+			},
+			{
+				ResourceName:      "basis_port.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
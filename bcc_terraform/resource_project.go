@@ -3,9 +3,10 @@ package bcc_terraform
 import (
 	"context"
 	"fmt"
-	"log"
+	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -22,12 +23,18 @@ func resourceProject() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceProjectImport,
 		},
-		Schema: args,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		CustomizeDiff: customizeDiffTagsAll,
+		Schema:        args,
 	}
 }
 
 func resourceProjectCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	var err error
 
 	fields := struct {
@@ -61,22 +68,24 @@ func resourceProjectCreate(ctx context.Context, d *schema.ResourceData, meta int
 	}
 
 	project := bcc.NewProject(fields.name)
-	project.Tags = unmarshalTagNames(d.Get("tags"))
-	log.Printf("[DEBUG] Project create request: %#v", project)
+	project.Tags = expandTags(d, meta)
+	tflog.Debug(ctx, "Project create request", map[string]interface{}{"name": project.Name})
 
 	if err = fields.client.CreateProject(&project); err != nil {
 		return diag.Errorf("[ERROR-002] crash via creating project: %s", err)
 	}
-	project.WaitLock()
+	if err := waitLock(ctx, project); err != nil {
+		return diag.Errorf("[ERROR-002] crash via creating project: %s", err)
+	}
 
 	d.SetId(project.ID)
-	log.Printf("[INFO] Project created, ID: %s", d.Id())
+	tflog.Info(ctx, "Project created", map[string]interface{}{"id": d.Id()})
 
 	return resourceProjectRead(ctx, d, meta)
 }
 
 func resourceProjectUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	project, err := manager.GetProject(d.Id())
 	if err != nil {
@@ -86,27 +95,30 @@ func resourceProjectUpdate(ctx context.Context, d *schema.ResourceData, meta int
 	if d.HasChange("name") {
 		project.Name = d.Get("name").(string)
 	}
-	if d.HasChange("tags") {
-		project.Tags = unmarshalTagNames(d.Get("tags"))
+	if d.HasChanges("tags", "tags_all") {
+		project.Tags = expandTags(d, meta)
 	}
 	if err = project.Update(); err != nil {
 		return diag.Errorf("[ERROR-002] crash via update project: %s", err)
 	}
-	project.WaitLock()
+	if err := waitLock(ctx, project); err != nil {
+		return diag.Errorf("[ERROR-002] crash via update project: %s", err)
+	}
 
 	return resourceProjectRead(ctx, d, meta)
 }
 
 func resourceProjectRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	project, err := manager.GetProject(d.Id())
 	if err != nil {
-		resourceReadCheck(d, err, "[ERROR-002]:")
+		return resourceReadCheck(d, err, "[ERROR-002]:")
 	}
 
 	fields := map[string]interface{}{
-		"name": project.Name,
-		"tags": marshalTagNames(project.Tags),
+		"name":     project.Name,
+		"tags":     flattenTags(d, meta, project.Tags),
+		"tags_all": marshalTagNames(project.Tags),
 	}
 
 	if err := setResourceDataFromMap(d, fields); err != nil {
@@ -117,7 +129,7 @@ func resourceProjectRead(ctx context.Context, d *schema.ResourceData, meta inter
 }
 
 func resourceProjectDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	project, err := manager.GetProject(d.Id())
 	if err != nil {
@@ -127,13 +139,15 @@ func resourceProjectDelete(ctx context.Context, d *schema.ResourceData, meta int
 	if err = project.Delete(); err != nil {
 		return diag.Errorf("[ERROR-002] crash via deleting project: %s", err)
 	}
-	project.WaitLock()
+	if err := waitLock(ctx, project); err != nil && !isNotFound(err) {
+		return diag.Errorf("[ERROR-002] crash via deleting project: %s", err)
+	}
 
 	return nil
 }
 
 func resourceProjectImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	project, err := manager.GetProject(d.Id())
 	if err != nil {
 		return nil, fmt.Errorf("[ERROR-002] crash via getting project: %s", err)
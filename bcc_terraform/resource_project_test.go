@@ -0,0 +1,73 @@
+package bcc_terraform
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccProject_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_project"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(fake) + `
+resource "basis_project" "test" {
+  name = "tf-acc-project"
+  tags = ["created_by:terraform"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_project.test"),
+					resource.TestCheckResourceAttr("basis_project.test", "name", "tf-acc-project"),
+					resource.TestCheckResourceAttr("basis_project.test", "tags.#", "1"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(fake) + `
+resource "basis_project" "test" {
+  name = "tf-acc-project-renamed"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_project.test", "name", "tf-acc-project-renamed"),
+					resource.TestCheckResourceAttr("basis_project.test", "tags.#", "0"),
+				),
+			},
+			{
+				ResourceName:      "basis_project.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccProject_timeout(t *testing.T) {
+	fake := newFakeBcc(t)
+	fake.SetLockPolls(1000)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(fake) + `
+resource "basis_project" "test" {
+  name = "tf-acc-project"
+
+  timeouts {
+    create = "2s"
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`timeout exceeded while waiting`),
+			},
+		},
+	})
+}
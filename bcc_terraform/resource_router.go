@@ -2,11 +2,12 @@ package bcc_terraform
 
 import (
 	"context"
-	"log"
-	"strings"
+	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -20,10 +21,15 @@ func resourceRouter() *schema.Resource {
 		ReadContext:   resourceRouterRead,
 		UpdateContext: resourceRouterUpdate,
 		DeleteContext: resourceRouterDelete,
-		CustomizeDiff: resourceRouterCustomizeDiff,
+		CustomizeDiff: customdiff.All(customizeDiffDefaultVdc, resourceRouterCustomizeDiff, customizeDiffRouterRoutes, customizeDiffTagsAll),
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceRouterImport,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
 		Schema: args,
 	}
 }
@@ -35,7 +41,7 @@ func resourceRouterCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ in
 }
 
 func resourceRouterCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	vdc, err := GetVdcById(d, manager)
 	if err != nil {
@@ -69,7 +75,7 @@ func resourceRouterCreate(ctx context.Context, d *schema.ResourceData, meta inte
 	}
 
 	router := bcc.NewRouter(fields.name, fields.floatingIp, vdc.ID)
-	router.Tags = unmarshalTagNames(d.Get("tags"))
+	router.Tags = expandTags(d, meta)
 	router.IsDefault = fields.isDefault
 
 	for _, portId := range fields.ports {
@@ -80,7 +86,7 @@ func resourceRouterCreate(ctx context.Context, d *schema.ResourceData, meta inte
 		router.Ports = append(router.Ports, port)
 	}
 
-	if strings.EqualFold(vdc.Hypervisor.Type, "Vmware") {
+	if routerSupportsRoutes(vdc) {
 		for _, route := range fields.routes {
 			r := route.(map[string]interface{})
 			router.Routes = append(router.Routes, &bcc.Route{
@@ -94,27 +100,39 @@ func resourceRouterCreate(ctx context.Context, d *schema.ResourceData, meta inte
 		}
 	}
 
-	log.Printf("[DEBUG] Router create request: %#v", router)
+	tflog.Debug(ctx, "Router create request", map[string]interface{}{"name": router.Name, "vdc_id": vdc.ID})
 
-	if err = vdc.WaitLock(); err != nil {
+	unlock, err := lockObject(ctx, meta, "vdc", vdc.ID)
+	if err != nil {
+		return diag.Errorf("[ERROR-044] crash via vdc lock: %s", err)
+	}
+	defer unlock()
+
+	if err = waitLock(ctx, vdc); err != nil {
 		return diag.Errorf("[ERROR-044] crash via waitlock for vdc: %s", err)
 	}
-	if err = vdc.CreateRouter(&router); err != nil {
+	if err = vdc.CreateRouter(&router, router.Ports...); err != nil {
 		return diag.Errorf("[ERROR-044] crash via creating Router: %s", err)
 	}
-	if err = router.WaitLock(); err != nil {
+	if err = waitLock(ctx, router); err != nil {
 		return diag.Errorf("[ERROR-044] crash via waitlock for router: %s", err)
 	}
 
 	d.SetId(router.ID)
 	d.Set("system", fields.system)
-	log.Printf("[INFO] Router created, ID: %s", router.ID)
+	tflog.Info(ctx, "Router created", map[string]interface{}{"id": router.ID})
 
 	return resourceRouterRead(ctx, d, meta)
 }
 
 func resourceRouterUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	unlock, err := lockObject(ctx, meta, "router", d.Id())
+	if err != nil {
+		return diag.Errorf("[ERROR-044] crash via router lock: %s", err)
+	}
+	defer unlock()
+
 	router, err := manager.GetRouter(d.Id())
 	if err != nil {
 		return diag.Errorf("[ERROR-044] crash via getting Router: %s", err)
@@ -124,96 +142,78 @@ func resourceRouterUpdate(ctx context.Context, d *schema.ResourceData, meta inte
 		router.Name = d.Get("name").(string)
 		shouldUpdate = true
 	}
-	if d.HasChange("tags") {
-		router.Tags = unmarshalTagNames(d.Get("tags"))
+	if d.HasChanges("tags", "tags_all") {
+		router.Tags = expandTags(d, meta)
 		shouldUpdate = true
 	}
 	if d.HasChange("is_default") {
 		router.IsDefault = d.Get("is_default").(bool)
 		shouldUpdate = true
 	}
-	if d.HasChange("routes") {
-		shouldUpdate = true
-		addRoutes := make(map[string]*bcc.Route)
-
-		if strings.EqualFold(router.Vdc.Hypervisor.Type, "Vmware") {
-			for _, route := range d.Get("routes").([]interface{}) {
-				r := route.(map[string]interface{})
-				addRoutes[r["next_hop"].(string)] = &bcc.Route{
-					Destination: r["destination"].(string),
-					NextHop:     r["next_hop"].(string),
-				}
-			}
-			for _, route := range router.Routes {
-				if _, exist := addRoutes[route.NextHop]; exist {
-					delete(addRoutes, route.NextHop)
-				} else {
-					if err := route.Delete(); err != nil {
-						return diag.Errorf("[ERROR-044] crash via deleting route by 'id':%s", route.ID)
-					}
-				}
-			}
-			for _, route := range addRoutes {
-				if err := router.CreateRoute(route); err != nil {
-					return diag.Errorf("[ERROR-044] crash via creating route, for router 'id':%s", router.ID)
-				}
-			}
-		} else {
-			if len(d.Get("routes").([]interface{})) > 0 {
-				return diag.Errorf("[ERROR-044] crash via: Routes are not supported for %s hypervisor", router.Vdc.Hypervisor.Type)
-			}
+	if d.HasChanges("routes", "exclusive_routes") {
+		if err = syncRouterRoutes(ctx, d, router); err != nil {
+			return diag.Errorf("[ERROR-044] %s", err)
+		}
+		// The router update sends the routes along, so they are read again.
+		current, err := manager.GetRouter(d.Id())
+		if err != nil {
+			return diag.Errorf("[ERROR-044] crash via getting Router: %s", err)
 		}
+		router.Routes = current.Routes
 	}
 
 	if shouldUpdate {
-		if err = repeatOnError(router.Update, router); err != nil {
+		if err = callUnlocked(ctx, router.Update, router); err != nil {
+			return diag.Errorf("[ERROR-044] crash via router's update %s", err)
+		}
+		if err := waitLock(ctx, router); err != nil {
 			return diag.Errorf("[ERROR-044] crash via router's update %s", err)
 		}
-		router.WaitLock()
 	}
 
 	// Disconnect ports and connect new
-	err = syncRouterPorts(d, manager, router)
+	err = syncRouterPorts(ctx, d, manager, router)
 	if err != nil {
 		return diag.Errorf("[ERROR-044] %s", err)
 	}
-	router.WaitLock()
+	if err := waitLock(ctx, router); err != nil {
+		return diag.Errorf("[ERROR-044] %s", err)
+	}
 
-	if err := syncFloating(d, router); err != nil {
+	if err := syncFloating(ctx, d, router); err != nil {
+		return diag.Errorf("[ERROR-044] %s", err)
+	}
+	if err := waitLock(ctx, router); err != nil {
 		return diag.Errorf("[ERROR-044] %s", err)
 	}
-	router.WaitLock()
 
 	return resourceRouterRead(ctx, d, meta)
 }
 
-func resourceRouterRead(_ context.Context, d *schema.ResourceData, meta interface{}) (diagErr diag.Diagnostics) {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceRouterRead(ctx context.Context, d *schema.ResourceData, meta interface{}) (diagErr diag.Diagnostics) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	router, err := manager.GetRouter(d.Id())
 	if err != nil {
 		return resourceReadCheck(d, err, "[ERROR-044]")
 	}
 
-	ports := make([]*string, len(router.Ports))
-	for i, port := range router.Ports {
-		ports[i] = &port.ID
+	routerPorts := router.Ports
+	if !d.Get("exclusive_ports").(bool) {
+		routerPorts = filterOwnedRouterPorts(d, routerPorts)
 	}
-
-	routes := make([]map[string]interface{}, len(router.Routes))
-	for i, route := range router.Routes {
-		routes[i] = map[string]interface{}{
-			"destination": route.Destination,
-			"next_hop":    route.NextHop,
-		}
+	ports := make([]*string, len(routerPorts))
+	for i, port := range routerPorts {
+		ports[i] = &port.ID
 	}
 
 	fields := map[string]interface{}{
 		"name":        router.Name,
 		"is_default":  router.IsDefault,
-		"routes":      routes,
+		"routes":      flattenRouterRoutes(d, router.Routes),
 		"ports":       ports,
 		"vdc_id":      router.Vdc.ID,
-		"tags":        marshalTagNames(router.Tags),
+		"tags":        flattenTags(d, meta, router.Tags),
+		"tags_all":    marshalTagNames(router.Tags),
 		"floating":    false,
 		"floating_id": "",
 	}
@@ -230,10 +230,16 @@ func resourceRouterRead(_ context.Context, d *schema.ResourceData, meta interfac
 	return nil
 }
 
-func resourceRouterDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceRouterDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	portsIds := d.Get("ports").([]interface{})
 	routerId := d.Id()
+	unlock, err := lockObject(ctx, meta, "router", d.Id())
+	if err != nil {
+		return diag.Errorf("[ERROR-044] crash via router lock: %s", err)
+	}
+	defer unlock()
+
 	router, err := manager.GetRouter(routerId)
 	if err != nil {
 		return diag.Errorf("[ERROR-044] crash via getting Router: %s", err)
@@ -254,7 +260,7 @@ func resourceRouterDelete(_ context.Context, d *schema.ResourceData, meta interf
 			}
 			if router.Floating == nil {
 				router.Floating = &bcc.Port{ID: "RANDOM_FIP"}
-				if err = repeatOnError(router.Update, router); err != nil {
+				if err = callUnlocked(ctx, router.Update, router); err != nil {
 					return diag.Errorf("[ERROR-044] Can't return router to default state: %s", err)
 				}
 			}
@@ -275,18 +281,20 @@ func resourceRouterDelete(_ context.Context, d *schema.ResourceData, meta interf
 		}
 	}
 
-	if err = repeatOnError(router.Delete, router); err != nil {
+	if err = callUnlocked(ctx, router.Delete, router); err != nil {
 		return diag.Errorf("[ERROR-044] crash via deleting Router: %s", err)
 	}
 
-	router.WaitLock()
-	log.Printf("[INFO] Router deleted, ID: %s", routerId)
+	if err := waitLock(ctx, router); err != nil && !isNotFound(err) {
+		return diag.Errorf("[ERROR-044] crash via deleting Router: %s", err)
+	}
+	tflog.Info(ctx, "Router deleted", map[string]interface{}{"id": routerId})
 
 	return nil
 }
 
-func resourceRouterImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceRouterImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	router, err := manager.GetRouter(d.Id())
 	if err != nil {
@@ -294,6 +302,12 @@ func resourceRouterImport(_ context.Context, d *schema.ResourceData, meta interf
 	}
 
 	d.SetId(router.ID)
+	if err = d.Set("exclusive_routes", true); err != nil {
+		return nil, err
+	}
+	if err = d.Set("exclusive_ports", true); err != nil {
+		return nil, err
+	}
 
 	return []*schema.ResourceData{d}, nil
 
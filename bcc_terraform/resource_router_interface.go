@@ -0,0 +1,225 @@
+package bcc_terraform
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceRouterInterface() *schema.Resource {
+	args := Defaults()
+	args.injectContextResourceRouterInterface()
+
+	return &schema.Resource{
+		CreateContext: resourceRouterInterfaceCreate,
+		UpdateContext: resourceRouterInterfaceUpdate,
+		ReadContext:   resourceRouterInterfaceRead,
+		DeleteContext: resourceRouterInterfaceDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceRouterInterfaceImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		CustomizeDiff: customizeDiffPortIp,
+		Schema:        args,
+	}
+}
+
+func resourceRouterInterfaceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	routerId := d.Get("router_id").(string)
+
+	unlock, err := lockObject(ctx, meta, "router", routerId)
+	if err != nil {
+		return diag.Errorf("[ERROR-063] crash via router lock: %s", err)
+	}
+	defer unlock()
+
+	router, err := manager.GetRouter(routerId)
+	if err != nil {
+		return diag.Errorf("[ERROR-063] crash via getting Router: %s", err)
+	}
+
+	var port *bcc.Port
+	if portId, ok := d.GetOk("port_id"); ok {
+		if port, err = manager.GetPort(portId.(string)); err != nil {
+			return diag.Errorf("[ERROR-063] crash via get port: %s", err)
+		}
+		if port.Connected != nil && port.Connected.ID != router.ID {
+			return diag.Errorf("[ERROR-063] port %s is already connected to %s. please disconnect it before conecting", port.ID, port.Connected.ID)
+		}
+		if ipAddress, ok := d.GetOk("ip_address"); ok {
+			ipAddressStr := ipAddress.(string)
+			port.IpAddress = &ipAddressStr
+		}
+		if err = callUnlocked(ctx, func() error { return router.ConnectPort(port, true) }, router); err != nil {
+			return diag.Errorf("[ERROR-063] crash via connecting port: %s", err)
+		}
+	} else {
+		network, err := manager.GetNetwork(d.Get("network_id").(string))
+		if err != nil {
+			return diag.Errorf("[ERROR-063] crash via get network: %s", err)
+		}
+		ipAddressStr := "0.0.0.0"
+		if ipAddress, ok := d.GetOk("ip_address"); ok {
+			ipAddressStr = ipAddress.(string)
+		}
+		newPort := bcc.NewPort(network, nil, ipAddressStr)
+		port = &newPort
+		if err = callUnlocked(ctx, func() error { return router.ConnectPort(port, false) }, router); err != nil {
+			return diag.Errorf("[ERROR-063] crash via creating port: %s", err)
+		}
+	}
+	if err = waitLock(ctx, router); err != nil {
+		return diag.Errorf("[ERROR-063] crash via waitlock for router: %s", err)
+	}
+
+	d.SetId(port.ID)
+	if err = d.Set("created_port", d.Get("port_id").(string) == ""); err != nil {
+		return diag.Errorf("[ERROR-063] crash via set attrs: %s", err)
+	}
+	tflog.Info(ctx, "Router interface connected", map[string]interface{}{"router_id": router.ID, "port_id": port.ID})
+
+	return resourceRouterInterfaceRead(ctx, d, meta)
+}
+
+func resourceRouterInterfaceUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+
+	unlock, err := lockObject(ctx, meta, "router", d.Get("router_id").(string))
+	if err != nil {
+		return diag.Errorf("[ERROR-063] crash via router lock: %s", err)
+	}
+	defer unlock()
+
+	port, err := manager.GetPort(d.Id())
+	if err != nil {
+		return diag.Errorf("[ERROR-063] crash via get port: %s", err)
+	}
+
+	if d.HasChange("ip_address") {
+		ipAddress := d.Get("ip_address").(string)
+		port.IpAddress = &ipAddress
+	}
+	if err = port.Update(); err != nil {
+		return diag.Errorf("[ERROR-063] crash via updating port: %s", err)
+	}
+	if err = waitLock(ctx, port); err != nil {
+		return diag.Errorf("[ERROR-063] crash via port waitlock: %s", err)
+	}
+
+	return resourceRouterInterfaceRead(ctx, d, meta)
+}
+
+func resourceRouterInterfaceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	port, err := manager.GetPort(d.Id())
+	if err != nil {
+		return resourceReadCheck(d, err, "[ERROR-063]")
+	}
+
+	// A port disconnected or moved to another device outside of Terraform
+	// shows up as a change of router_id, which connects the interface again.
+	routerId := ""
+	if port.Connected != nil {
+		routerId = port.Connected.ID
+	}
+	if routerId != d.Get("router_id").(string) {
+		tflog.Warn(ctx, "Port is no longer connected to the router", map[string]interface{}{
+			"router_id": d.Get("router_id"),
+			"port_id":   port.ID,
+			"connected": routerId,
+		})
+	}
+
+	fields := map[string]interface{}{
+		"router_id":  routerId,
+		"network_id": port.Network.ID,
+		"ip_address": port.IpAddress,
+	}
+
+	if err = setResourceDataFromMap(d, fields); err != nil {
+		return diag.Errorf("[ERROR-063] crash via set attrs: %s", err)
+	}
+
+	return nil
+}
+
+func resourceRouterInterfaceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	routerId := d.Get("router_id").(string)
+
+	unlock, err := lockObject(ctx, meta, "router", routerId)
+	if err != nil {
+		return diag.Errorf("[ERROR-063] crash via router lock: %s", err)
+	}
+	defer unlock()
+
+	port, err := manager.GetPort(d.Id())
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return diag.Errorf("[ERROR-063] crash via get port: %s", err)
+	}
+
+	// A port created by the interface goes away with it, an existing or an
+	// imported one is only disconnected.
+	if d.Get("created_port").(bool) {
+		if err = port.ForceDelete(); err != nil {
+			return diag.Errorf("[ERROR-063] crash via deleting port: %s", err)
+		}
+		if err = waitLock(ctx, port); err != nil && !isNotFound(err) {
+			return diag.Errorf("[ERROR-063] crash via deleting port: %s", err)
+		}
+		tflog.Info(ctx, "Router interface deleted", map[string]interface{}{"router_id": routerId, "port_id": port.ID})
+		return nil
+	}
+
+	if port.Connected == nil || port.Connected.ID != routerId {
+		return nil
+	}
+	router, err := manager.GetRouter(routerId)
+	if err != nil {
+		return diag.Errorf("[ERROR-063] crash via getting Router: %s", err)
+	}
+	if err = callUnlocked(ctx, func() error { return router.DisconnectPort(port) }, router); err != nil {
+		return diag.Errorf("[ERROR-063] crash via disconnecting port: %s", err)
+	}
+	if err = waitLock(ctx, router); err != nil {
+		return diag.Errorf("[ERROR-063] crash via disconnecting port: %s", err)
+	}
+	tflog.Info(ctx, "Router interface disconnected", map[string]interface{}{"router_id": routerId, "port_id": port.ID})
+
+	return nil
+}
+
+func resourceRouterInterfaceImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	port, err := manager.GetPort(d.Id())
+	if err != nil {
+		tflog.Error(ctx, "[ERROR-063] crash via getting port", map[string]interface{}{"id": d.Id(), "error": err.Error()})
+		return nil, err
+	}
+
+	// The interface didn't create the port, so destroying it must keep the
+	// port.
+	d.SetId(port.ID)
+	fields := map[string]interface{}{
+		"port_id":      port.ID,
+		"created_port": false,
+	}
+	if err = setResourceDataFromMap(d, fields); err != nil {
+		return nil, fmt.Errorf("[ERROR-063] crash via set attrs: %s", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,120 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testAccRouterInterfaceConfig(fake *fakeBcc, ipAddress string) string {
+	config := testAccRouterConfig(fake, `
+resource "basis_router" "test" {
+  vdc_id          = basis_vdc.test.id
+  name            = "tf-acc-router"
+  ports           = [basis_port.router.id]
+  exclusive_ports = false
+}
+
+resource "basis_network" "third" {
+  name   = "tf-acc-network-third"
+  vdc_id = basis_vdc.test.id
+
+  subnets {
+    cidr     = "10.0.4.0/24"
+    dhcp     = true
+    gateway  = "10.0.4.1"
+    start_ip = "10.0.4.10"
+    end_ip   = "10.0.4.100"
+  }
+}
+`)
+	if ipAddress != "" {
+		config += fmt.Sprintf(`
+resource "basis_router_interface" "test" {
+  router_id  = basis_router.test.id
+  network_id = basis_network.third.id
+  ip_address = %q
+}
+
+resource "basis_router_interface" "shared" {
+  router_id = basis_router.test.id
+  port_id   = basis_port.second.id
+}
+`, ipAddress)
+	}
+	return config
+}
+
+func TestAccRouterInterface_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+	var createdPort string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_router_interface"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRouterInterfaceConfig(fake, "10.0.4.1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPortConnected(fake, "basis_router_interface.test", "basis_router.test"),
+					testAccCheckPortConnected(fake, "basis_port.second", "basis_router.test"),
+					testAccCheckPortConnected(fake, "basis_port.router", "basis_router.test"),
+					resource.TestCheckResourceAttr("basis_router_interface.test", "ip_address", "10.0.4.1"),
+					resource.TestCheckResourceAttrPair("basis_router_interface.shared", "network_id", "basis_network.second", "id"),
+					resource.TestCheckResourceAttr("basis_router_interface.test", "created_port", "true"),
+					resource.TestCheckResourceAttr("basis_router_interface.shared", "created_port", "false"),
+					// basis_router does not claim the ports of the interfaces
+					resource.TestCheckResourceAttr("basis_router.test", "ports.#", "1"),
+					func(s *terraform.State) error {
+						createdPort = s.RootModule().Resources["basis_router_interface.test"].Primary.ID
+						return nil
+					},
+				),
+			},
+			{
+				// An imported interface doesn't know it created its port.
+				ResourceName:            "basis_router_interface.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"port_id", "created_port"},
+			},
+			{
+				ResourceName:      "basis_router_interface.shared",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// The port disconnected outside of Terraform is connected again.
+				PreConfig: func() { fake.DisconnectPort(createdPort) },
+				Config:    testAccRouterInterfaceConfig(fake, "10.0.4.1"),
+				Check:     testAccCheckPortConnected(fake, "basis_router_interface.test", "basis_router.test"),
+			},
+			{
+				Config: testAccRouterInterfaceConfig(fake, "10.0.4.2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_router_interface.test", "ip_address", "10.0.4.2"),
+					testAccCheckPortConnected(fake, "basis_router_interface.test", "basis_router.test"),
+				),
+			},
+			{
+				Config: testAccRouterInterfaceConfig(fake, ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_port.second"),
+					func(s *terraform.State) error {
+						port := s.RootModule().Resources["basis_port.second"].Primary.ID
+						if device := fake.PortDevice(port); device != "" {
+							return fmt.Errorf("port %s is still connected to %s", port, device)
+						}
+						if fake.Exists(createdPort) {
+							return fmt.Errorf("port %s of the interface still exists", createdPort)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,202 @@
+package bcc_terraform
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceRouterRoute() *schema.Resource {
+	args := Defaults()
+	args.injectContextResourceRouterRoute()
+
+	return &schema.Resource{
+		CreateContext: resourceRouterRouteCreate,
+		UpdateContext: resourceRouterRouteUpdate,
+		ReadContext:   resourceRouterRouteRead,
+		DeleteContext: resourceRouterRouteDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceRouterRouteImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		CustomizeDiff: resourceRouterRouteCustomizeDiff,
+		Schema:        args,
+	}
+}
+
+// resourceRouterRouteCustomizeDiff rejects a route on a router whose VDC
+// doesn't support routes, and a route the router already has.
+func resourceRouterRouteCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	routerId := d.Get("router_id").(string)
+	if !d.HasChanges("router_id", "destination", "next_hop") || !d.NewValueKnown("router_id") || routerId == "" {
+		return nil
+	}
+
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	router, err := manager.GetRouter(routerId)
+	if err != nil {
+		return fmt.Errorf("router_id: router '%s' not found: %s", routerId, err)
+	}
+	vdc, err := manager.GetVdc(router.Vdc.ID)
+	if err != nil {
+		return fmt.Errorf("router_id: vdc '%s' of the router not found: %s", router.Vdc.ID, err)
+	}
+	if !routerSupportsRoutes(vdc) {
+		return fmt.Errorf("router_id: routes are not supported by the %s hypervisor of the vdc %s", vdc.Hypervisor.Type, vdc.Name)
+	}
+
+	if !d.NewValueKnown("destination") || !d.NewValueKnown("next_hop") {
+		return nil
+	}
+	key := routerRouteKey(d.Get("destination").(string), d.Get("next_hop").(string))
+	for _, route := range router.Routes {
+		if route.ID != d.Id() && routerRouteKey(route.Destination, route.NextHop) == key {
+			return fmt.Errorf("the router %s already has the route to %s", router.Name, key)
+		}
+	}
+	return nil
+}
+
+func resourceRouterRouteCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	routerId := d.Get("router_id").(string)
+
+	unlock, err := lockObject(ctx, meta, "router", routerId)
+	if err != nil {
+		return diag.Errorf("[ERROR-062] crash via router lock: %s", err)
+	}
+	defer unlock()
+
+	router, err := manager.GetRouter(routerId)
+	if err != nil {
+		return diag.Errorf("[ERROR-062] crash via getting Router: %s", err)
+	}
+
+	route := bcc.NewRoute(d.Get("destination").(string), d.Get("next_hop").(string))
+	if err = callUnlocked(ctx, func() error { return router.CreateRoute(&route) }, router); err != nil {
+		return diag.Errorf("[ERROR-062] crash via creating route: %s", err)
+	}
+	if err = waitLock(ctx, router); err != nil {
+		return diag.Errorf("[ERROR-062] crash via waitlock for router: %s", err)
+	}
+
+	d.SetId(route.ID)
+	tflog.Info(ctx, "Route created", map[string]interface{}{"id": d.Id(), "router_id": routerId})
+
+	return resourceRouterRouteRead(ctx, d, meta)
+}
+
+func resourceRouterRouteUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	routerId := d.Get("router_id").(string)
+
+	unlock, err := lockObject(ctx, meta, "router", routerId)
+	if err != nil {
+		return diag.Errorf("[ERROR-062] crash via router lock: %s", err)
+	}
+	defer unlock()
+
+	router, err := manager.GetRouter(routerId)
+	if err != nil {
+		return diag.Errorf("[ERROR-062] crash via getting Router: %s", err)
+	}
+	route := findRouterRoute(router, d.Id())
+	if route == nil {
+		return diag.Errorf("[ERROR-062] route-%s not found on router-%s", d.Id(), routerId)
+	}
+
+	route.Destination = d.Get("destination").(string)
+	route.NextHop = d.Get("next_hop").(string)
+	if err = callUnlocked(ctx, route.Update, router); err != nil {
+		return diag.Errorf("[ERROR-062] crash via updating route: %s", err)
+	}
+	if err = waitLock(ctx, router); err != nil {
+		return diag.Errorf("[ERROR-062] crash via waitlock for router: %s", err)
+	}
+
+	return resourceRouterRouteRead(ctx, d, meta)
+}
+
+func resourceRouterRouteRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	router, err := manager.GetRouter(d.Get("router_id").(string))
+	if err != nil {
+		return resourceReadCheck(d, err, "[ERROR-062]")
+	}
+	route := findRouterRoute(router, d.Id())
+	if route == nil {
+		tflog.Warn(ctx, "Route is gone", map[string]interface{}{"id": d.Id(), "router_id": router.ID})
+		d.SetId("")
+		return nil
+	}
+
+	fields := map[string]interface{}{
+		"router_id":   router.ID,
+		"destination": route.Destination,
+		"next_hop":    route.NextHop,
+	}
+
+	if err = setResourceDataFromMap(d, fields); err != nil {
+		return diag.Errorf("[ERROR-062] crash via set attrs: %s", err)
+	}
+
+	return nil
+}
+
+func resourceRouterRouteDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	routerId := d.Get("router_id").(string)
+
+	unlock, err := lockObject(ctx, meta, "router", routerId)
+	if err != nil {
+		return diag.Errorf("[ERROR-062] crash via router lock: %s", err)
+	}
+	defer unlock()
+
+	router, err := manager.GetRouter(routerId)
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return diag.Errorf("[ERROR-062] crash via getting Router: %s", err)
+	}
+	route := findRouterRoute(router, d.Id())
+	if route == nil {
+		return nil
+	}
+
+	if err = callUnlocked(ctx, route.Delete, router); err != nil {
+		return diag.Errorf("[ERROR-062] crash via deleting route: %s", err)
+	}
+	if err = waitLock(ctx, router); err != nil {
+		return diag.Errorf("[ERROR-062] crash via deleting route: %s", err)
+	}
+	tflog.Info(ctx, "Route deleted", map[string]interface{}{"id": d.Id(), "router_id": routerId})
+
+	return nil
+}
+
+// resourceRouterRouteImport imports a route by "<router_id>/<route_id>".
+func resourceRouterRouteImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	routerId, routeId, ok := strings.Cut(d.Id(), "/")
+	if !ok || routerId == "" || routeId == "" {
+		return nil, fmt.Errorf("[ERROR-062]: unexpected id %q, expected router_id/route_id", d.Id())
+	}
+
+	d.SetId(routeId)
+	if err := d.Set("router_id", routerId); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
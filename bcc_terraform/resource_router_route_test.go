@@ -0,0 +1,134 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testAccRouterRouteConfig(fake *fakeBcc, destination string) string {
+	return testAccRouterConfig(fake, fmt.Sprintf(`
+resource "basis_router" "test" {
+  vdc_id           = basis_vdc.test.id
+  name             = "tf-acc-router"
+  ports            = [basis_port.router.id]
+  exclusive_routes = false
+
+  routes {
+    destination = "192.168.0.0/24"
+    next_hop    = "10.0.2.254"
+  }
+}
+
+resource "basis_router_route" "test" {
+  router_id   = basis_router.test.id
+  destination = %q
+  next_hop    = "10.0.2.254"
+}
+`, destination))
+}
+
+func TestAccRouterRoute_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_router"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRouterRouteConfig(fake, "192.168.1.0/24"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_router_route.test", "destination", "192.168.1.0/24"),
+					resource.TestCheckResourceAttr("basis_router.test", "routes.#", "1"),
+					testAccCheckRouterRoutes(fake, "basis_router.test", "192.168.0.0/24 via 10.0.2.254", "192.168.1.0/24 via 10.0.2.254"),
+				),
+			},
+			{
+				Config: testAccRouterRouteConfig(fake, "192.168.2.0/24"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_router_route.test", "destination", "192.168.2.0/24"),
+					resource.TestCheckResourceAttr("basis_router.test", "routes.#", "1"),
+					testAccCheckRouterRoutes(fake, "basis_router.test", "192.168.0.0/24 via 10.0.2.254", "192.168.2.0/24 via 10.0.2.254"),
+				),
+			},
+			{
+				ResourceName:      "basis_router_route.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs := s.RootModule().Resources["basis_router_route.test"]
+					return rs.Primary.Attributes["router_id"] + "/" + rs.Primary.ID, nil
+				},
+			},
+			{
+				Config:      testAccRouterRouteConfig(fake, "192.168.0.0/24"),
+				ExpectError: regexp.MustCompile(`already has the route to 192.168.0.0/24 via 10.0.2.254`),
+			},
+		},
+	})
+}
+
+func TestAccRouterRoute_unsupportedHypervisor(t *testing.T) {
+	fake := newFakeBcc(t)
+	router := `
+resource "basis_router" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-router"
+  ports  = [basis_port.router.id]
+}
+`
+	kvm := func(config string) string {
+		return strings.Replace(config, fakeVmwareHypervisorID, fakeKvmHypervisorID, 1)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_router"),
+		Steps: []resource.TestStep{
+			{
+				Config: kvm(testAccRouterConfig(fake, router)),
+			},
+			{
+				Config: kvm(testAccRouterConfig(fake, router+`
+resource "basis_router_route" "test" {
+  router_id   = basis_router.test.id
+  destination = "192.168.1.0/24"
+  next_hop    = "10.0.2.254"
+}
+`)),
+				ExpectError: regexp.MustCompile(`router_id: routes are not supported by the kvm hypervisor`),
+			},
+			{
+				Config: kvm(testAccRouterConfig(fake, strings.Replace(router, `ports  = [basis_port.router.id]`, `ports  = [basis_port.router.id]
+
+  routes {
+    destination = "192.168.1.0/24"
+    next_hop    = "10.0.2.254"
+  }`, 1))),
+				ExpectError: regexp.MustCompile(`routes: routes are not supported by the kvm hypervisor`),
+			},
+		},
+	})
+}
+
+// testAccCheckRouterRoutes compares the routes the fake API stores for the
+// router, inline and standalone ones alike.
+func testAccCheckRouterRoutes(fake *fakeBcc, name string, expected ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("not found: %s", name)
+		}
+		routes := fake.RouterRoutes(rs.Primary.ID)
+		if strings.Join(routes, ", ") != strings.Join(expected, ", ") {
+			return fmt.Errorf("expected the routes %v, got %v", expected, routes)
+		}
+		return nil
+	}
+}
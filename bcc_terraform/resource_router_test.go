@@ -0,0 +1,135 @@
+package bcc_terraform
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testAccRouterConfig(fake *fakeBcc, router string) string {
+	return testAccNetworkConfig(fake) + `
+resource "basis_network" "second" {
+  name   = "tf-acc-network-second"
+  vdc_id = basis_vdc.test.id
+
+  subnets {
+    cidr     = "10.0.3.0/24"
+    dhcp     = true
+    gateway  = "10.0.3.1"
+    start_ip = "10.0.3.10"
+    end_ip   = "10.0.3.100"
+  }
+}
+
+resource "basis_port" "router" {
+  vdc_id     = basis_vdc.test.id
+  network_id = basis_network.test.id
+  ip_address = "10.0.2.1"
+}
+
+resource "basis_port" "second" {
+  vdc_id     = basis_vdc.test.id
+  network_id = basis_network.second.id
+  ip_address = "10.0.3.1"
+}
+` + router
+}
+
+func TestAccRouter_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_router"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRouterConfig(fake, `
+resource "basis_router" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-router"
+  ports  = [basis_port.router.id]
+
+  routes {
+    destination = "192.168.0.0/24"
+    next_hop    = "10.0.2.254"
+  }
+}
+`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_router.test"),
+					resource.TestCheckResourceAttr("basis_router.test", "ports.#", "1"),
+					resource.TestCheckResourceAttr("basis_router.test", "routes.#", "1"),
+					resource.TestCheckResourceAttr("basis_router.test", "floating", "false"),
+					resource.TestCheckResourceAttr("basis_router.test", "floating_id", ""),
+				),
+			},
+			{
+				Config: testAccRouterConfig(fake, `
+resource "basis_router" "test" {
+  vdc_id   = basis_vdc.test.id
+  name     = "tf-acc-router-renamed"
+  ports    = [basis_port.router.id, basis_port.second.id]
+  floating = true
+
+  routes {
+    destination = "192.168.1.0/24"
+    next_hop    = "10.0.3.254"
+  }
+}
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_router.test", "name", "tf-acc-router-renamed"),
+					resource.TestCheckResourceAttr("basis_router.test", "ports.#", "2"),
+					resource.TestCheckResourceAttr("basis_router.test", "routes.#", "1"),
+					resource.TestCheckResourceAttr("basis_router.test", "routes.0.next_hop", "10.0.3.254"),
+					resource.TestCheckResourceAttr("basis_router.test", "floating", "true"),
+					resource.TestCheckResourceAttrSet("basis_router.test", "floating_id"),
+				),
+			},
+			{
+				ResourceName:            "basis_router.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"system"},
+			},
+		},
+	})
+}
+
+func TestAccRouter_disconnectError(t *testing.T) {
+	fake := newFakeBcc(t)
+	var secondPort string
+	config := func(ports string) string {
+		return testAccRouterConfig(fake, `
+resource "basis_router" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-router"
+  ports  = [`+ports+`]
+}
+`)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_router"),
+		Steps: []resource.TestStep{
+			{
+				Config: config("basis_port.router.id, basis_port.second.id"),
+				Check: func(s *terraform.State) error {
+					secondPort = s.RootModule().Resources["basis_port.second"].Primary.ID
+					return nil
+				},
+			},
+			{
+				PreConfig:   func() { fake.FailNext("PATCH", "v1/port/"+secondPort+"/disconnect", http.StatusBadRequest, 1, "") },
+				Config:      config("basis_port.router.id"),
+				ExpectError: regexp.MustCompile("cannot detach port"),
+			},
+		},
+	})
+}
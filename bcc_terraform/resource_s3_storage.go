@@ -3,11 +3,12 @@ package bcc_terraform
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -26,14 +27,16 @@ func resourceS3Storage() *schema.Resource {
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
-		Schema: args,
+		CustomizeDiff: customdiff.All(customizeDiffDefaultProject, customizeDiffTagsAll),
+		Schema:        args,
 	}
 }
 
 func resourceS3StorageCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	project, err := GetProjectById(d, manager)
 	if err != nil {
 		return diag.Errorf("[ERROR-051]: crash via getting Project: %s", err)
@@ -43,24 +46,24 @@ func resourceS3StorageCreate(ctx context.Context, d *schema.ResourceData, meta i
 	backend := d.Get("backend").(string)
 
 	newS3Storage := bcc.NewS3Storage(name, backend)
-	newS3Storage.Tags = unmarshalTagNames(d.Get("tags"))
+	newS3Storage.Tags = expandTags(d, meta)
 
 	err = project.CreateS3Storage(&newS3Storage)
 	if err != nil {
 		return diag.Errorf("[ERROR-051]: crash via creating S3Storage: %s", err)
 	}
 
-	if err = newS3Storage.WaitLock(); err != nil {
+	if err = waitLock(ctx, newS3Storage); err != nil {
 		return diag.Errorf("[ERROR-051]: crash via waiting for S3Storage to be created: %s", err)
 	}
 	d.SetId(newS3Storage.ID)
-	log.Printf("[INFO-051] S3Storage created, ID: %s", d.Id())
+	tflog.Info(ctx, "S3Storage created", map[string]interface{}{"id": d.Id()})
 
 	return resourceS3StorageRead(ctx, d, meta)
 }
 
 func resourceS3StorageUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	s3, err := manager.GetS3Storage(d.Id())
 	if err != nil {
@@ -69,24 +72,24 @@ func resourceS3StorageUpdate(ctx context.Context, d *schema.ResourceData, meta i
 	if d.HasChange("name") {
 		s3.Name = d.Get("name").(string)
 	}
-	if d.HasChange("tags") {
-		s3.Tags = unmarshalTagNames(d.Get("tags"))
+	if d.HasChanges("tags", "tags_all") {
+		s3.Tags = expandTags(d, meta)
 	}
 
 	if err = s3.Update(); err != nil {
 		return diag.Errorf("[ERROR-051]: crash via updating S3Storage: %s", err)
 	}
-	if err = s3.WaitLock(); err != nil {
+	if err = waitLock(ctx, s3); err != nil {
 		return diag.Errorf("[ERROR-051]: crash via waiting for S3Storage to be created: %s", err)
 
 	}
-	log.Printf("[INFO] S3Storage updated, ID: %s", d.Id())
+	tflog.Info(ctx, "S3Storage updated", map[string]interface{}{"id": d.Id()})
 
 	return resourceS3StorageRead(ctx, d, meta)
 }
 
-func resourceS3StorageRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceS3StorageRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	s3Storage, err := manager.GetS3Storage(d.Id())
 	if err != nil {
 		if err.(*bcc.ApiError).Code() == 404 {
@@ -104,7 +107,8 @@ func resourceS3StorageRead(_ context.Context, d *schema.ResourceData, meta inter
 		"client_endpoint": s3Storage.ClientEndpoint,
 		"secret_key":      s3Storage.SecretKey,
 		"access_key":      s3Storage.AccessKey,
-		"tags":            marshalTagNames(s3Storage.Tags),
+		"tags":            flattenTags(d, meta, s3Storage.Tags),
+		"tags_all":        marshalTagNames(s3Storage.Tags),
 	}
 
 	if err := setResourceDataFromMap(d, fields); err != nil {
@@ -114,8 +118,8 @@ func resourceS3StorageRead(_ context.Context, d *schema.ResourceData, meta inter
 	return nil
 }
 
-func resourceS3StorageDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceS3StorageDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	s3, err := manager.GetS3Storage(d.Id())
 	if err != nil {
 		return diag.Errorf("[ERROR-051]: crash via getting S3Storage by 'id'=%s: %s", d.Id(), err)
@@ -124,16 +128,18 @@ func resourceS3StorageDelete(_ context.Context, d *schema.ResourceData, meta int
 	if err = s3.Delete(); err != nil {
 		return diag.Errorf("[ERROR-051]: crash via deleting S3Storage: %s", err)
 	}
-	s3.WaitLock()
+	if err := waitLock(ctx, s3); ctx.Err() != nil {
+		return diag.Errorf("[ERROR-051]: crash via deleting S3Storage: %s", err)
+	}
 
 	d.SetId("")
-	log.Printf("[INFO-051] S3Storage deleted, ID: %s", d.Id())
+	tflog.Info(ctx, "S3Storage deleted", map[string]interface{}{"id": d.Id()})
 
 	return nil
 }
 
-func resourceS3StorageImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceS3StorageImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	S3Storage, err := manager.GetS3Storage(d.Id())
 	if err != nil {
 		d.SetId("")
@@ -3,12 +3,12 @@ package bcc_terraform
 import (
 	"context"
 	"fmt"
-	"log"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -28,6 +28,7 @@ func resourceS3StorageBucket() *schema.Resource {
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
 		Schema: args,
@@ -37,7 +38,7 @@ func resourceS3StorageBucket() *schema.Resource {
 var reForName = regexp.MustCompile(`^[A-z0-9\-]+$`)
 
 func resourceS3StorageBucketCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	s3Id := d.Get("s3_storage_id").(string)
 	s3, err := manager.GetS3Storage(s3Id)
@@ -58,13 +59,13 @@ func resourceS3StorageBucketCreate(ctx context.Context, d *schema.ResourceData,
 	}
 
 	d.SetId(S3StorageBucket.ID)
-	log.Printf("[INFO-052] S3StorageBucket created, ID: %s", d.Id())
+	tflog.Info(ctx, "S3StorageBucket created", map[string]interface{}{"id": d.Id()})
 
 	return resourceS3StorageBucketRead(ctx, d, meta)
 }
 
 func resourceS3StorageBucketUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	s3Id := d.Get("s3_storage_id").(string)
 
 	s3, err := manager.GetS3Storage(s3Id)
@@ -87,18 +88,18 @@ func resourceS3StorageBucketUpdate(ctx context.Context, d *schema.ResourceData,
 	if err = bucket.Update(); err != nil {
 		return diag.Errorf("[ERROR-052]: crash via updating S3StorageBucket: %s", err)
 	}
-	log.Printf("[INFO-052] S3StorageBucket updated, ID: %s", d.Id())
+	tflog.Info(ctx, "S3StorageBucket updated", map[string]interface{}{"id": d.Id()})
 
 	return resourceS3StorageBucketRead(ctx, d, meta)
 }
 
 func resourceS3StorageBucketRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	s3Id := d.Get("s3_storage_id").(string)
 
 	s3, err := manager.GetS3Storage(s3Id)
 	if err != nil {
-		return diag.Errorf("[ERROR-052]: crash via getting S3Storage by 'id'=%bucket: %bucket", s3Id, err)
+		return diag.Errorf("[ERROR-052]: crash via getting S3Storage by 'id'=%s: %s", s3Id, err)
 	}
 
 	bucket, err := s3.GetBucket(d.Id())
@@ -107,7 +108,7 @@ func resourceS3StorageBucketRead(ctx context.Context, d *schema.ResourceData, me
 			d.SetId("")
 			return nil
 		} else {
-			return diag.Errorf("[ERROR-052]: crash via getting S3StorageBucket by 'id'=%bucket: %bucket", d.Id(), err)
+			return diag.Errorf("[ERROR-052]: crash via getting S3StorageBucket by 'id'=%s: %s", d.Id(), err)
 		}
 	}
 
@@ -118,14 +119,14 @@ func resourceS3StorageBucketRead(ctx context.Context, d *schema.ResourceData, me
 	}
 
 	if err := setResourceDataFromMap(d, fields); err != nil {
-		return diag.Errorf("[ERROR-052]: crash via reading S3StorageBucket: %bucket", err)
+		return diag.Errorf("[ERROR-052]: crash via reading S3StorageBucket: %s", err)
 	}
 
 	return nil
 }
 
 func resourceS3StorageBucketDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	s3Id := d.Get("s3_storage_id").(string)
 	s3, err := manager.GetS3Storage(s3Id)
@@ -143,13 +144,13 @@ func resourceS3StorageBucketDelete(ctx context.Context, d *schema.ResourceData,
 	}
 
 	d.SetId("")
-	log.Printf("[INFO] S3StorageBucket deleted, ID: %s", s3Id)
+	tflog.Info(ctx, "S3StorageBucket deleted", map[string]interface{}{"id": s3Id})
 
 	return nil
 }
 
 func resourceS3StorageBucketImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	id := d.Id()
 	ids := strings.Split(id, ",")
@@ -0,0 +1,50 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testAccS3StorageBucketConfig(fake *fakeBcc, name string) string {
+	return testAccS3StorageConfig(fake, "tf-acc-s3") + fmt.Sprintf(`
+resource "basis_s3_storage_bucket" "test" {
+  s3_storage_id = basis_s3_storage.test.id
+  name          = %q
+}
+`, name)
+}
+
+func TestAccS3StorageBucket_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_s3_storage_bucket"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccS3StorageBucketConfig(fake, "tf-acc-bucket"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_s3_storage_bucket.test"),
+					resource.TestCheckResourceAttrSet("basis_s3_storage_bucket.test", "external_name"),
+				),
+			},
+			{
+				Config: testAccS3StorageBucketConfig(fake, "tf-acc-bucket-renamed"),
+				Check:  resource.TestCheckResourceAttr("basis_s3_storage_bucket.test", "name", "tf-acc-bucket-renamed"),
+			},
+			{
+				ResourceName:      "basis_s3_storage_bucket.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs := s.RootModule().Resources["basis_s3_storage_bucket.test"]
+					return rs.Primary.Attributes["s3_storage_id"] + "," + rs.Primary.ID, nil
+				},
+			},
+		},
+	})
+}
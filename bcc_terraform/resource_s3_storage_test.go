@@ -0,0 +1,53 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func testAccS3StorageConfig(fake *fakeBcc, name string) string {
+	return testAccProviderConfig(fake) + fmt.Sprintf(`
+resource "basis_project" "test" {
+  name = "tf-acc-project"
+}
+
+resource "basis_s3_storage" "test" {
+  project_id = basis_project.test.id
+  name       = %q
+  backend    = "minio"
+}
+`, name)
+}
+
+func TestAccS3Storage_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_s3_storage"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccS3StorageConfig(fake, "tf-acc-s3"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_s3_storage.test"),
+					resource.TestCheckResourceAttr("basis_s3_storage.test", "backend", "minio"),
+					resource.TestCheckResourceAttrSet("basis_s3_storage.test", "access_key"),
+					resource.TestCheckResourceAttrSet("basis_s3_storage.test", "secret_key"),
+					resource.TestCheckResourceAttrSet("basis_s3_storage.test", "client_endpoint"),
+				),
+			},
+			{
+				Config: testAccS3StorageConfig(fake, "tf-acc-s3-renamed"),
+				Check:  resource.TestCheckResourceAttr("basis_s3_storage.test", "name", "tf-acc-s3-renamed"),
+			},
+			{
+				ResourceName:      "basis_s3_storage.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
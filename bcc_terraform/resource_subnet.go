@@ -0,0 +1,205 @@
+package bcc_terraform
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceSubnet() *schema.Resource {
+	args := Defaults()
+	args.injectContextResourceSubnet()
+
+	return &schema.Resource{
+		CreateContext: resourceSubnetCreate,
+		UpdateContext: resourceSubnetUpdate,
+		ReadContext:   resourceSubnetRead,
+		DeleteContext: resourceSubnetDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceSubnetImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		CustomizeDiff: customizeDiffSubnet,
+		Schema:        args,
+	}
+}
+
+func resourceSubnetCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	networkId := d.Get("network_id").(string)
+
+	unlock, err := lockObject(ctx, meta, "network", networkId)
+	if err != nil {
+		return diag.Errorf("[ERROR-061]: %s", err)
+	}
+	defer unlock()
+
+	network, err := manager.GetNetwork(networkId)
+	if err != nil {
+		return diag.Errorf("[ERROR-061] crash via getting network-%s: %s", networkId, err)
+	}
+	if err = waitLock(ctx, network); err != nil {
+		return diag.Errorf("[ERROR-061] crash via wait lock %s", err)
+	}
+
+	args := map[string]interface{}{
+		"cidr":     d.Get("cidr"),
+		"gateway":  d.Get("gateway"),
+		"start_ip": d.Get("start_ip"),
+		"end_ip":   d.Get("end_ip"),
+		"dhcp":     d.Get("dhcp"),
+		"dns":      d.Get("dns"),
+	}
+	subnet, err := createSubnet(ctx, network, args)
+	if err != nil {
+		return diag.Errorf("[ERROR-061]: %s", err)
+	}
+	if err = waitLock(ctx, network); err != nil {
+		return diag.Errorf("[ERROR-061] crash via wait lock %s", err)
+	}
+
+	d.SetId(subnet.ID)
+	tflog.Info(ctx, "Subnet created", map[string]interface{}{"id": d.Id(), "network_id": networkId})
+
+	return resourceSubnetRead(ctx, d, meta)
+}
+
+func resourceSubnetUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	networkId := d.Get("network_id").(string)
+
+	unlock, err := lockObject(ctx, meta, "network", networkId)
+	if err != nil {
+		return diag.Errorf("[ERROR-061]: %s", err)
+	}
+	defer unlock()
+
+	network, err := manager.GetNetwork(networkId)
+	if err != nil {
+		return diag.Errorf("[ERROR-061] crash via getting network-%s: %s", networkId, err)
+	}
+	subnet, err := getSubnet(network, d.Id())
+	if err != nil {
+		return diag.Errorf("[ERROR-061] crash via getting subnet-%s: %s", d.Id(), err)
+	}
+	if subnet == nil {
+		return diag.Errorf("[ERROR-061] subnet-%s not found in network-%s", d.Id(), networkId)
+	}
+
+	args := map[string]interface{}{
+		"gateway":  d.Get("gateway"),
+		"start_ip": d.Get("start_ip"),
+		"end_ip":   d.Get("end_ip"),
+		"dhcp":     d.Get("dhcp"),
+		"dns":      d.Get("dns"),
+	}
+	if err = updateSubnet(ctx, subnet, args); err != nil {
+		return diag.Errorf("[ERROR-061]: %s", err)
+	}
+	if err = waitLock(ctx, network); err != nil {
+		return diag.Errorf("[ERROR-061] crash via wait lock %s", err)
+	}
+
+	return resourceSubnetRead(ctx, d, meta)
+}
+
+func resourceSubnetRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	network, err := manager.GetNetwork(d.Get("network_id").(string))
+	if err != nil {
+		return resourceReadCheck(d, err, "[ERROR-061]")
+	}
+	subnet, err := getSubnet(network, d.Id())
+	if err != nil {
+		return diag.Errorf("[ERROR-061] crash via getting subnet-%s: %s", d.Id(), err)
+	}
+	if subnet == nil {
+		tflog.Warn(ctx, "Subnet is gone", map[string]interface{}{"id": d.Id(), "network_id": network.ID})
+		d.SetId("")
+		return nil
+	}
+
+	fields := flattenSubnet(subnet)
+	delete(fields, "id")
+	fields["network_id"] = network.ID
+
+	if err = setResourceDataFromMap(d, fields); err != nil {
+		return diag.Errorf("[ERROR-061] crash via set attrs: %s", err)
+	}
+
+	return nil
+}
+
+func resourceSubnetDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	networkId := d.Get("network_id").(string)
+
+	unlock, err := lockObject(ctx, meta, "network", networkId)
+	if err != nil {
+		return diag.Errorf("[ERROR-061]: %s", err)
+	}
+	defer unlock()
+
+	network, err := manager.GetNetwork(networkId)
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return diag.Errorf("[ERROR-061] crash via getting network-%s: %s", networkId, err)
+	}
+	subnet, err := getSubnet(network, d.Id())
+	if err != nil {
+		return diag.Errorf("[ERROR-061] crash via getting subnet-%s: %s", d.Id(), err)
+	}
+	if subnet == nil {
+		return nil
+	}
+
+	if err = callUnlocked(ctx, subnet.Delete, network); err != nil {
+		return diag.Errorf("[ERROR-061] crash via deleting subnet-%s: %s", d.Id(), err)
+	}
+	if err = waitLock(ctx, network); err != nil {
+		return diag.Errorf("[ERROR-061] crash via deleting subnet-%s: %s", d.Id(), err)
+	}
+	tflog.Info(ctx, "Subnet deleted", map[string]interface{}{"id": d.Id(), "network_id": networkId})
+
+	return nil
+}
+
+// resourceSubnetImport imports a subnet by "<network_id>/<subnet_id>".
+func resourceSubnetImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+
+	networkId, subnetId, ok := strings.Cut(d.Id(), "/")
+	if !ok || networkId == "" || subnetId == "" {
+		return nil, fmt.Errorf("[ERROR-061]: unexpected id %q, expected network_id/subnet_id", d.Id())
+	}
+
+	network, err := manager.GetNetwork(networkId)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR-061] crash via getting network-%s: %s", networkId, err)
+	}
+	subnet, err := getSubnet(network, subnetId)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR-061] crash via getting subnet-%s: %s", subnetId, err)
+	}
+	if subnet == nil {
+		return nil, fmt.Errorf("[ERROR-061] subnet-%s not found in network-%s", subnetId, networkId)
+	}
+
+	d.SetId(subnet.ID)
+	if err = d.Set("network_id", network.ID); err != nil {
+		return nil, fmt.Errorf("[ERROR-061] crash via set 'network_id': %s", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
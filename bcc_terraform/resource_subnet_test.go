@@ -0,0 +1,75 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testAccSubnetConfig(fake *fakeBcc, dhcp bool, dns string) string {
+	return testAccVdcConfig(fake) + fmt.Sprintf(`
+resource "basis_network" "test" {
+  name              = "tf-acc-network"
+  vdc_id            = basis_vdc.test.id
+  exclusive_subnets = false
+
+  subnets {
+    cidr     = "10.0.2.0/24"
+    dhcp     = true
+    gateway  = "10.0.2.1"
+    start_ip = "10.0.2.2"
+    end_ip   = "10.0.2.254"
+  }
+}
+
+resource "basis_subnet" "test" {
+  network_id = basis_network.test.id
+  cidr       = "10.0.3.0/24"
+  gateway    = "10.0.3.1"
+  start_ip   = "10.0.3.10"
+  end_ip     = "10.0.3.100"
+  dhcp       = %t
+  dns        = [%q]
+}
+`, dhcp, dns)
+}
+
+func TestAccSubnet_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_subnet"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSubnetConfig(fake, true, "8.8.8.8"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_subnet.test"),
+					resource.TestCheckResourceAttr("basis_subnet.test", "cidr", "10.0.3.0/24"),
+					resource.TestCheckResourceAttr("basis_subnet.test", "dns.0", "8.8.8.8"),
+					resource.TestCheckResourceAttr("basis_network.test", "subnets.#", "1"),
+				),
+			},
+			{
+				Config: testAccSubnetConfig(fake, false, "1.1.1.1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_subnet.test", "dhcp", "false"),
+					resource.TestCheckResourceAttr("basis_subnet.test", "dns.0", "1.1.1.1"),
+					resource.TestCheckResourceAttr("basis_network.test", "subnets.#", "1"),
+				),
+			},
+			{
+				ResourceName:      "basis_subnet.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs := s.RootModule().Resources["basis_subnet.test"]
+					return rs.Primary.Attributes["network_id"] + "/" + rs.Primary.ID, nil
+				},
+			},
+		},
+	})
+}
@@ -3,11 +3,12 @@ package bcc_terraform
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -26,21 +27,26 @@ func resourceVdc() *schema.Resource {
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
 		Schema: args,
-		CustomizeDiff: func(ctx context.Context, rd *schema.ResourceDiff, i interface{}) error {
-			if rd.Id() != "" && !rd.HasChange("project_id") {
-				rd.Clear("id")
-				rd.Clear("default_network_id")
-			}
-			return nil
-		},
+		CustomizeDiff: customdiff.All(
+			customizeDiffDefaultProject,
+			func(ctx context.Context, rd *schema.ResourceDiff, i interface{}) error {
+				if rd.Id() != "" && !rd.HasChange("project_id") {
+					rd.Clear("id")
+					rd.Clear("default_network_id")
+				}
+				return nil
+			},
+			customizeDiffTagsAll,
+		),
 	}
 }
 
 func resourceVdcCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	targetProject, err := manager.GetProject(d.Get("project_id").(string))
 	if err != nil {
@@ -53,26 +59,31 @@ func resourceVdcCreate(ctx context.Context, d *schema.ResourceData, meta interfa
 	}
 
 	vdc := bcc.NewVdc(d.Get("name").(string), targetHypervisor)
-	vdc.Tags = unmarshalTagNames(d.Get("tags"))
+	vdc.Tags = expandTags(d, meta)
+
+	unlock, err := lockObject(ctx, meta, "project", targetProject.ID)
+	if err != nil {
+		return diag.Errorf("[ERROR-006]: %s", err)
+	}
+	defer unlock()
 
-	// if we're creating multiple vdc at once, there are need some time to get new vnid
 	f := func() error { return targetProject.CreateVdc(&vdc) }
-	if err = repeatOnError(f, targetProject); err != nil {
+	if err = callUnlocked(ctx, f, targetProject); err != nil {
 		return diag.Errorf("[ERROR-006]: crash via creating vdc: %s", err)
 	}
 
-	if err = vdc.WaitLock(); err != nil {
+	if err = waitLock(ctx, vdc); err != nil {
 		return diag.Errorf("[ERROR-006]: %s", err)
 	}
 
 	d.SetId(vdc.ID)
-	log.Printf("[INFO-006] VDC created, ID: %s", d.Id())
+	tflog.Info(ctx, "VDC created", map[string]interface{}{"id": d.Id()})
 
 	return resourceVdcRead(ctx, d, meta)
 }
 
 func resourceVdcUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	vdc, err := manager.GetVdc(d.Id())
 	if err != nil {
@@ -84,22 +95,22 @@ func resourceVdcUpdate(ctx context.Context, d *schema.ResourceData, meta interfa
 	if d.HasChange("name") {
 		vdc.Name = d.Get("name").(string)
 	}
-	if d.HasChange("tags") {
-		vdc.Tags = unmarshalTagNames(d.Get("tags"))
+	if d.HasChanges("tags", "tags_all") {
+		vdc.Tags = expandTags(d, meta)
 	}
 	err = vdc.Update()
 	if err != nil {
 		return diag.Errorf("[ERROR-006] name: Error rename vdc: %s", err)
 	}
-	if err = vdc.WaitLock(); err != nil {
+	if err = waitLock(ctx, vdc); err != nil {
 		return diag.Errorf("[ERROR-006] Error locking vdc: %s", err)
 	}
 
 	return resourceVdcRead(ctx, d, meta)
 }
 
-func resourceVdcRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceVdcRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	vdc, err := manager.GetVdc(d.Id())
 	if err != nil {
 		if err.(*bcc.ApiError).Code() == 404 {
@@ -118,7 +129,8 @@ func resourceVdcRead(_ context.Context, d *schema.ResourceData, meta interface{}
 		"name":          vdc.Name,
 		"project_id":    vdc.Project.ID,
 		"hypervisor_id": vdc.Hypervisor.ID,
-		"tags":          marshalTagNames(vdc.Tags),
+		"tags":          flattenTags(d, meta, vdc.Tags),
+		"tags_all":      marshalTagNames(vdc.Tags),
 	}
 
 	if len(networks) != 0 {
@@ -159,8 +171,8 @@ func resourceVdcRead(_ context.Context, d *schema.ResourceData, meta interface{}
 	return nil
 }
 
-func resourceVdcDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceVdcDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	vdc, err := manager.GetVdc(d.Id())
 	if err != nil {
 		return diag.Errorf("[ERROR-006]: %s", err)
@@ -169,13 +181,15 @@ func resourceVdcDelete(_ context.Context, d *schema.ResourceData, meta interface
 	if err = vdc.Delete(); err != nil {
 		return diag.Errorf("[ERROR-006]: %s", err)
 	}
-	vdc.WaitLock()
+	if err := waitLock(ctx, vdc); err != nil && !isNotFound(err) {
+		return diag.Errorf("[ERROR-006]: %s", err)
+	}
 
 	return nil
 }
 
-func resourceVdcImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceVdcImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	vdc, err := manager.GetVdc(d.Id())
 	if err != nil {
@@ -0,0 +1,57 @@
+package bcc_terraform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccVdc_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_vdc"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVdcConfig(fake),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_vdc.test"),
+					resource.TestCheckResourceAttr("basis_vdc.test", "name", "tf-acc-vdc"),
+					resource.TestCheckResourceAttr("basis_vdc.test", "hypervisor_id", fakeVmwareHypervisorID),
+					resource.TestCheckResourceAttr("basis_vdc.test", "default_network_name", fakeDefaultNetworkName),
+					resource.TestCheckResourceAttr("basis_vdc.test", "default_network_subnets.0.cidr", fakeDefaultNetworkCIDR),
+					resource.TestCheckResourceAttrSet("basis_vdc.test", "default_network_id"),
+				),
+			},
+			{
+				ResourceName:      "basis_vdc.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// TestAccVdc_locked makes the fake keep every written object locked for a
+// few polls, the way the real API does while a task is running.
+func TestAccVdc_locked(t *testing.T) {
+	fake := newFakeBcc(t)
+	fake.SetLockPolls(2)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_vdc"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVdcConfig(fake),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_vdc.test"),
+					resource.TestCheckResourceAttr("basis_vdc.test", "name", "tf-acc-vdc"),
+				),
+			},
+		},
+	})
+}
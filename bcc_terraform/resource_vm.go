@@ -2,11 +2,13 @@ package bcc_terraform
 
 import (
 	"context"
-	"log"
+	"fmt"
 	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -25,29 +27,121 @@ func resourceVm() *schema.Resource {
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
-		CustomizeDiff: resourceVmCustomizeDiff,
-		Schema:        args,
+		CustomizeDiff: customdiff.All(
+			customizeDiffDefaultVdc,
+			customizeDiffTemplateName(findTemplateIdByName),
+			resourceVmCustomizeDiff,
+			customizeDiffTagsAll,
+		),
+		Schema: args,
 	}
 }
 
-func resourceVmCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+func resourceVmCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
 	if d.HasChange("floating") {
 		d.SetNewComputed("floating_ip")
 	}
+
+	if d.Id() != "" && d.HasChange("system_disk.0.size") {
+		oldSize, newSize := d.GetChange("system_disk.0.size")
+		if newSize.(int) < oldSize.(int) {
+			return fmt.Errorf("system_disk.0.size: the system disk can't be shrunk from %d to %d GB", oldSize, newSize)
+		}
+	}
+
+	if d.Id() != "" && d.HasChange("data_disk") {
+		oldDisks, newDisks := d.GetChange("data_disk")
+		matches, _ := matchVmDataDisks(oldDisks.([]interface{}), newDisks.([]interface{}))
+		for i, item := range newDisks.([]interface{}) {
+			if matches[i] == -1 {
+				continue
+			}
+			oldSize := oldDisks.([]interface{})[matches[i]].(map[string]interface{})["size"].(int)
+			if newSize := item.(map[string]interface{})["size"].(int); newSize < oldSize {
+				return fmt.Errorf("data_disk.%d.size: the disk can't be shrunk from %d to %d GB", i, oldSize, newSize)
+			}
+		}
+	}
+
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	if err := validateVmTemplateMinimums(d, manager); err != nil {
+		return err
+	}
+	return validateVmStorageProfile(d, manager)
+}
+
+// validateVmTemplateMinimums rejects a Vm smaller than its template allows.
+// Values which are not known yet are checked at apply time by the API.
+func validateVmTemplateMinimums(d *schema.ResourceDiff, manager *bcc.Manager) error {
+	templateId := d.Get("template_id").(string)
+	if !d.NewValueKnown("template_id") || templateId == "" {
+		return nil
+	}
+	if d.Id() != "" && !d.HasChanges("template_id", "cpu", "ram", "system_disk") {
+		return nil
+	}
+
+	template, err := manager.GetTemplate(templateId)
+	if err != nil {
+		return fmt.Errorf("template_id: template '%s' not found: %s", templateId, err)
+	}
+
+	if cpu := d.Get("cpu").(int); d.NewValueKnown("cpu") && cpu < template.MinCpu {
+		return fmt.Errorf("cpu: template '%s' needs at least %d cpu, got %d", template.Name, template.MinCpu, cpu)
+	}
+	if ram := d.Get("ram").(float64); d.NewValueKnown("ram") && ram < template.MinRam {
+		return fmt.Errorf("ram: template '%s' needs at least %g GB of ram, got %g", template.Name, template.MinRam, ram)
+	}
+	if size := d.Get("system_disk.0.size").(int); d.NewValueKnown("system_disk.0.size") && size < template.MinHdd {
+		return fmt.Errorf("system_disk.0.size: template '%s' needs a system disk of at least %d GB, got %d", template.Name, template.MinHdd, size)
+	}
 	return nil
 }
 
+// validateVmStorageProfile rejects a system disk storage profile of another VDC.
+func validateVmStorageProfile(d *schema.ResourceDiff, manager *bcc.Manager) error {
+	vdcId := d.Get("vdc_id").(string)
+	storageProfileId := d.Get("system_disk.0.storage_profile_id").(string)
+	if !d.NewValueKnown("vdc_id") || !d.NewValueKnown("system_disk.0.storage_profile_id") || vdcId == "" || storageProfileId == "" {
+		return nil
+	}
+	if d.Id() != "" && !d.HasChanges("vdc_id", "system_disk.0.storage_profile_id") {
+		return nil
+	}
+
+	vdc, err := manager.GetVdc(vdcId)
+	if err != nil {
+		return fmt.Errorf("vdc_id: vdc '%s' not found: %s", vdcId, err)
+	}
+	storageProfiles, err := vdc.GetStorageProfiles()
+	if err != nil {
+		return fmt.Errorf("system_disk.0.storage_profile_id: error getting list of storage profiles: %s", err)
+	}
+	for _, storageProfile := range storageProfiles {
+		if storageProfile.ID == storageProfileId {
+			return nil
+		}
+	}
+	return fmt.Errorf("system_disk.0.storage_profile_id: storage profile '%s' doesn't belong to vdc '%s'", storageProfileId, vdc.Name)
+}
+
 func resourceVmCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	vdc, err := GetVdcById(d, manager)
 	if err != nil {
 		return diag.Errorf("[ERROR-021]: %s", err)
 	}
 
-	template, err := GetTemplateById(d, manager)
+	var template *bcc.Template
+	if d.Get("template_id").(string) != "" {
+		template, err = GetTemplateById(d, manager)
+	} else {
+		template, err = findTemplate(vdc, templateFilter{name: d.Get("template_name").(string)})
+	}
 	if err != nil {
 		return diag.Errorf("[ERROR-021]: %s", err)
 	}
@@ -61,6 +155,7 @@ func resourceVmCreate(ctx context.Context, d *schema.ResourceData, meta interfac
 		userData            string
 		hotAdd              bool
 		sysDisk             interface{}
+		sysDiskName         string
 		sysDiskSize         int
 		sysStorageProfileId string
 		disks               []interface{}
@@ -74,6 +169,7 @@ func resourceVmCreate(ctx context.Context, d *schema.ResourceData, meta interfac
 		userData:            d.Get("user_data").(string),
 		hotAdd:              d.Get("hot_add").(bool),
 		sysDisk:             d.Get("system_disk"),
+		sysDiskName:         d.Get("system_disk.0.name").(string),
 		sysDiskSize:         d.Get("system_disk.0.size").(int),
 		sysStorageProfileId: d.Get("system_disk.0.storage_profile_id").(string),
 		disks:               d.Get("disks").(*schema.Set).List(),
@@ -85,8 +181,11 @@ func resourceVmCreate(ctx context.Context, d *schema.ResourceData, meta interfac
 	if err != nil {
 		return diag.Errorf("[ERROR-021]: %s", err)
 	}
+	if config.sysDiskName == "" {
+		config.sysDiskName = vmSystemDiskName
+	}
 	systemDiskList := make([]*bcc.Disk, 1)
-	systemDisk := bcc.NewDisk("Основной диск", config.sysDiskSize, storageProfile)
+	systemDisk := bcc.NewDisk(config.sysDiskName, config.sysDiskSize, storageProfile)
 	systemDiskList[0] = &systemDisk
 
 	// Ports creation
@@ -112,7 +211,7 @@ func resourceVmCreate(ctx context.Context, d *schema.ResourceData, meta interfac
 	)
 	vm.Description = config.description
 	vm.HotAdd = config.hotAdd
-	vm.Tags = unmarshalTagNames(d.Get("tags"))
+	vm.Tags = expandTags(d, meta)
 
 	if config.platform != "" {
 		vm.Platform, err = manager.GetPlatform(config.platform)
@@ -125,10 +224,29 @@ func resourceVmCreate(ctx context.Context, d *schema.ResourceData, meta interfac
 		vm.AffinityGroups = append(vm.AffinityGroups, &bcc.AffinityGroup{ID: item.(string)})
 	}
 
-	if err = vdc.CreateVm(&vm); err != nil {
+	// Only the creation is serialized with the other resources of the vdc,
+	// the Vm is built without holding the lock.
+	unlock, err := lockObject(ctx, meta, "vdc", vdc.ID)
+	if err != nil {
 		return diag.Errorf("[ERROR-021]: %s", err)
 	}
-	if err = vm.WaitLock(); err != nil {
+	err = vdc.CreateVm(&vm)
+	unlock()
+	if err != nil {
+		return diag.Errorf("[ERROR-021]: %s", err)
+	}
+
+	// From here on a failure taints the Vm instead of orphaning it.
+	d.SetId(vm.ID)
+	if err = waitLock(ctx, vm); err != nil {
+		return diag.Errorf("[ERROR-021]: %s", err)
+	}
+
+	// Only the data disks that were really created are kept in the state, so
+	// destroy deletes exactly those.
+	dataDisks := d.Get("data_disk").([]interface{})
+	createdDisks := make([]interface{}, 0, len(dataDisks))
+	if err = d.Set("data_disk", createdDisks); err != nil {
 		return diag.Errorf("[ERROR-021]: %s", err)
 	}
 
@@ -147,27 +265,57 @@ func resourceVmCreate(ctx context.Context, d *schema.ResourceData, meta interfac
 		}
 	}
 
-	vmPower := d.Get("power").(bool)
-	if !vmPower {
+	for _, item := range dataDisks {
+		args := item.(map[string]interface{})
+		disk, err := createVmDataDisk(ctx, meta, manager, vdc, &vm, args)
+		if err != nil {
+			return diag.Errorf("[ERROR-021]: %s", err)
+		}
+		args["id"] = disk.ID
+		createdDisks = append(createdDisks, args)
+		if err = d.Set("data_disk", createdDisks); err != nil {
+			return diag.Errorf("[ERROR-021]: %s", err)
+		}
+	}
+
+	if power, ok := vmConfiguredPower(d); ok && !power {
 		if err = vm.PowerOff(); err != nil {
 			return diag.Errorf("[ERROR-021]: %s", err)
 		}
 	}
 
 	fields := map[string]interface{}{
-		"id":        vm.ID,
 		"user_data": config.userData,
 	}
 	if err = setResourceDataFromMap(d, fields); err != nil {
 		return diag.Errorf("[ERROR-021]: %s", err)
 	}
-	log.Printf("[INFO] VM created, ID: %s", d.Id())
+	tflog.Info(ctx, "VM created", map[string]interface{}{"id": d.Id()})
 
-	return resourceVmRead(ctx, d, meta)
+	diags := resourceVmRead(ctx, d, meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	// The guest can't get ready while the Vm is powered off.
+	if power, ok := vmConfiguredPower(d); ok && !power {
+		return diags
+	}
+	if err = waitVmReady(ctx, d, manager); err != nil {
+		return append(diags, diag.Errorf("[ERROR-021]: %s", err)...)
+	}
+
+	return diags
 }
 
 func resourceVmUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	unlock, err := lockObject(ctx, meta, "vm", d.Id())
+	if err != nil {
+		return diag.Errorf("[ERROR-021]: %s", err)
+	}
+	defer unlock()
+
 	targetVdc, err := GetVdcById(d, manager)
 	if err != nil {
 		return diag.Errorf("[ERROR-021]: %s", err)
@@ -179,14 +327,24 @@ func resourceVmUpdate(ctx context.Context, d *schema.ResourceData, meta interfac
 	}
 
 	lifeCycle := &struct {
-		NeedUpdate bool
-		NeedReload bool
+		NeedUpdate  bool
+		NeedReload  bool
+		NeedRestart bool
 	}{
-		NeedUpdate: false,
-		NeedReload: false,
+		NeedUpdate:  false,
+		NeedReload:  false,
+		NeedRestart: false,
 	}
 
-	if diags := syncVmNetworks(d, manager, vm); diags.HasError() {
+	shutdownTimeout := vmGracefulShutdownTimeout(d)
+	power, ok := vmConfiguredPower(d)
+	if !ok {
+		power = vm.Power
+	}
+	powerChanged := d.HasChanges("power", "power_state") && power != vm.Power
+	lifeCycle.NeedRestart = d.HasChange("reboot_triggers") && vm.Power && power
+
+	if diags := syncVmNetworks(ctx, d, manager, vm); diags.HasError() {
 		return diags
 	}
 
@@ -227,49 +385,60 @@ func resourceVmUpdate(ctx context.Context, d *schema.ResourceData, meta interfac
 		vm.AffinityGroups = _affGrs
 	}
 
-	if d.HasChange("tags") {
+	if d.HasChanges("tags", "tags_all") {
 		lifeCycle.NeedUpdate = true
-		vm.Tags = unmarshalTagNames(d.Get("tags"))
+		vm.Tags = expandTags(d, meta)
 	}
 
 	if lifeCycle.NeedReload {
-		if err = vm.PowerOff(); err != nil {
+		if err = shutdownVm(ctx, manager, vm, shutdownTimeout); err != nil {
 			return diag.Errorf("[ERROR-021]: %s", err)
 		}
 	}
 
 	if lifeCycle.NeedUpdate {
-		if err := repeatOnError(vm.Update, vm); err != nil {
+		if err := callUnlocked(ctx, vm.Update, vm); err != nil {
 			return diag.Errorf("[ERROR-021]: crash via updating vm: %s", err)
 		}
 	}
 
 	if lifeCycle.NeedReload {
-		if err = vm.PowerOn(); err != nil {
-			return diag.FromErr(err)
+		if power {
+			if err = vm.PowerOn(); err != nil {
+				return diag.FromErr(err)
+			}
 		}
-	} else if d.HasChange("power") {
-		a := d.Get("power").(bool)
-		if a {
+	} else if powerChanged {
+		if power {
 			if err = vm.PowerOn(); err != nil {
 				return diag.Errorf("[ERROR-021]: %s", err)
 			}
 		} else {
-			if err = vm.PowerOff(); err != nil {
+			if err = shutdownVm(ctx, manager, vm, shutdownTimeout); err != nil {
 				return diag.Errorf("[ERROR-021]: %s", err)
 			}
 		}
+	} else if lifeCycle.NeedRestart {
+		if err = restartVm(ctx, manager, vm, shutdownTimeout); err != nil {
+			return diag.Errorf("[ERROR-021]: %s", err)
+		}
 	}
 
-	if err = syncVmDisks(d, manager, targetVdc, vm); err != nil {
+	if err = syncVmDisks(ctx, d, manager, targetVdc, vm); err != nil {
 		return diag.Errorf("[ERROR-021]: %s", err)
 	}
 
+	if d.HasChange("data_disk") {
+		if err = syncVmDataDisks(ctx, d, meta, manager, targetVdc, vm); err != nil {
+			return diag.Errorf("[ERROR-021]: %s", err)
+		}
+	}
+
 	return resourceVmRead(ctx, d, meta)
 }
 
-func resourceVmRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceVmRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 	vm, err := manager.GetVm(d.Id())
 	if err != nil {
 		if err.(*bcc.ApiError).Code() == 404 {
@@ -280,29 +449,30 @@ func resourceVmRead(_ context.Context, d *schema.ResourceData, meta interface{})
 		}
 	}
 
-	flattenDisks := make([]string, len(vm.Disks)-1)
-	for i, disk := range vm.Disks {
-		if i == 0 {
-			systemDisk := make([]interface{}, 1)
-			systemDisk[0] = map[string]interface{}{
-				"id":                 disk.ID,
-				"name":               "Основной диск",
-				"size":               disk.Size,
-				"storage_profile_id": disk.StorageProfile.ID,
-				"external_id":        disk.ExternalID,
-			}
-
-			if err = d.Set("system_disk", systemDisk); err != nil {
-				return diag.Errorf("error with setting system_disk %s", err)
-			}
-			continue
-		}
-		flattenDisks[i-1] = disk.ID
+	disk := vm.Disks[0]
+	systemDisk := make([]interface{}, 1)
+	systemDisk[0] = map[string]interface{}{
+		"id":                 disk.ID,
+		"name":               disk.Name,
+		"size":               disk.Size,
+		"storage_profile_id": disk.StorageProfile.ID,
+		"external_id":        disk.ExternalID,
+	}
+	if err = d.Set("system_disk", systemDisk); err != nil {
+		return diag.Errorf("error with setting system_disk %s", err)
+	}
+	flattenDataDisks, flattenDisks := flattenVmDataDisks(d, meta, vm.Disks[1:])
+	if !d.Get("exclusive_disks").(bool) {
+		flattenDisks = filterOwnedVmDisks(d, flattenDisks)
 	}
 
-	flattenPorts := make([]string, len(vm.Ports))
-	flattenNetworks := make([]interface{}, len(vm.Ports))
-	for i, port := range vm.Ports {
+	vmPorts := vm.Ports
+	if !d.Get("exclusive_networks").(bool) {
+		vmPorts = filterOwnedVmPorts(d, vmPorts)
+	}
+	flattenPorts := make([]string, len(vmPorts))
+	flattenNetworks := make([]interface{}, len(vmPorts))
+	for i, port := range vmPorts {
 		flattenPorts[i] = port.ID
 		flattenNetworks[i] = map[string]interface{}{
 			"id":         port.ID,
@@ -323,10 +493,13 @@ func resourceVmRead(_ context.Context, d *schema.ResourceData, meta interface{})
 		"ram":             vm.Ram,
 		"template_id":     vm.Template.ID,
 		"power":           vm.Power,
+		"power_state":     flattenVmPowerState(vm.Power),
 		"hot_add":         vm.HotAdd,
 		"platform":        vm.Platform.ID,
-		"tags":            marshalTagNames(vm.Tags),
+		"tags":            flattenTags(d, meta, vm.Tags),
+		"tags_all":        marshalTagNames(vm.Tags),
 		"affinity_groups": affGr,
+		"data_disk":       flattenDataDisks,
 		"disks":           flattenDisks,
 		"ports":           flattenPorts,
 		"networks":        flattenNetworks,
@@ -346,58 +519,68 @@ func resourceVmRead(_ context.Context, d *schema.ResourceData, meta interface{})
 	return nil
 }
 
-func resourceVmDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceVmDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	unlock, err := lockObject(ctx, meta, "vm", d.Id())
+	if err != nil {
+		return diag.Errorf("Error locking vm: %s", err)
+	}
+	defer unlock()
 
 	vm, err := manager.GetVm(d.Id())
 	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
 		return diag.Errorf("id: Error getting vm: %s", err)
 	}
 
-	vm.Floating = &bcc.Port{IpAddress: nil}
-	if err := repeatOnError(vm.Update, vm); err != nil {
-		return diag.Errorf("Error updating vm: %s", err)
+	onDestroy := expandVmOnDestroy(d)
+	if onDestroy.releaseFloatingIp && vm.Floating != nil {
+		vm.Floating = &bcc.Port{IpAddress: nil}
+		if err := callUnlocked(ctx, vm.Update, vm); err != nil {
+			return diag.Errorf("Error updating vm: %s", err)
+		}
 	}
 
-	if err = vm.WaitLock(); err != nil {
+	if err = waitLock(ctx, vm); err != nil {
 		return diag.FromErr(err)
 	}
 
-	disksIds := d.Get("disks").(*schema.Set).List()
-	for _, diskId := range disksIds {
-		disk, err := manager.GetDisk(diskId.(string))
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		if err = vm.DetachDisk(disk); err != nil {
+	for _, item := range d.Get("data_disk").([]interface{}) {
+		diskId := item.(map[string]interface{})["id"].(string)
+		if err = deleteVmDataDisk(ctx, manager, vm, diskId); err != nil {
 			return diag.FromErr(err)
 		}
 	}
 
-	portsIds := collectVmNetworks(d)
-	for _, portId := range portsIds {
-		port, err := manager.GetPort(portId)
-		if err != nil {
+	for _, diskId := range d.Get("disks").(*schema.Set).List() {
+		if err = releaseVmDisk(ctx, manager, vm, diskId.(string), onDestroy.deleteDataDisks); err != nil {
 			return diag.FromErr(err)
 		}
-		if err = vm.DisconnectPort(port); err != nil {
+	}
+
+	for _, portId := range collectVmNetworks(d) {
+		if err = releaseVmPort(ctx, manager, vm, portId, onDestroy.deletePorts); err != nil {
 			return diag.FromErr(err)
 		}
 	}
 
-	if err = vm.WaitLock(); err != nil {
+	if err = waitLock(ctx, vm); err != nil {
 		return diag.FromErr(err)
 	}
 	if err = vm.Delete(); err != nil {
 		return diag.Errorf("Error deleting vm: %s", err)
 	}
-	vm.WaitLock()
+	if err := waitLock(ctx, vm); err != nil && !isNotFound(err) {
+		return diag.Errorf("Error deleting vm: %s", err)
+	}
 
 	return nil
 }
 
-func resourceVmImport(_ context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	manager := meta.(*CombinedConfig).Manager()
+func resourceVmImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
 
 	vm, err := manager.GetVm(d.Id())
 	if err != nil {
@@ -405,6 +588,12 @@ func resourceVmImport(_ context.Context, d *schema.ResourceData, meta interface{
 	}
 
 	d.SetId(vm.ID)
+	if err = d.Set("exclusive_disks", true); err != nil {
+		return nil, err
+	}
+	if err = d.Set("exclusive_networks", true); err != nil {
+		return nil, err
+	}
 
 	return []*schema.ResourceData{d}, nil
 }
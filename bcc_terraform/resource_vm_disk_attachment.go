@@ -0,0 +1,149 @@
+package bcc_terraform
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceVmDiskAttachment() *schema.Resource {
+	args := Defaults()
+	args.injectContextResourceVmDiskAttachment()
+
+	return &schema.Resource{
+		CreateContext: resourceVmDiskAttachmentCreate,
+		ReadContext:   resourceVmDiskAttachmentRead,
+		DeleteContext: resourceVmDiskAttachmentDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVmDiskAttachmentImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		Schema: args,
+	}
+}
+
+func resourceVmDiskAttachmentCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	vmId := d.Get("vm_id").(string)
+	diskId := d.Get("disk_id").(string)
+
+	unlock, err := lockObject(ctx, meta, "vm", vmId)
+	if err != nil {
+		return diag.Errorf("[ERROR-057]: %s", err)
+	}
+	defer unlock()
+
+	vm, err := manager.GetVm(vmId)
+	if err != nil {
+		return diag.Errorf("[ERROR-057] crash via get vm: %s", err)
+	}
+	disk, err := manager.GetDisk(diskId)
+	if err != nil {
+		return diag.Errorf("[ERROR-057] crash via get disk: %s", err)
+	}
+
+	if disk.Vm != nil && disk.Vm.ID != vm.ID {
+		return diag.Errorf("[ERROR-057] disk %s is already attached to vm %s. please detach it before conecting", disk.ID, disk.Vm.ID)
+	}
+	if disk.Vm == nil {
+		if err = callUnlocked(ctx, func() error { return vm.AttachDisk(disk) }, vm); err != nil {
+			return diag.Errorf("[ERROR-057] crash via attaching disk: %s", err)
+		}
+		if err = waitLock(ctx, vm); err != nil {
+			return diag.Errorf("[ERROR-057] crash via attaching disk: %s", err)
+		}
+	}
+
+	d.SetId(vmDiskAttachmentId(vm.ID, disk.ID))
+	tflog.Info(ctx, "Disk attached", map[string]interface{}{"vm_id": vm.ID, "disk_id": disk.ID})
+
+	return resourceVmDiskAttachmentRead(ctx, d, meta)
+}
+
+func resourceVmDiskAttachmentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	vmId := d.Get("vm_id").(string)
+
+	disk, err := manager.GetDisk(d.Get("disk_id").(string))
+	if err != nil {
+		return resourceReadCheck(d, err, "[ERROR-057]")
+	}
+	if disk.Vm == nil || disk.Vm.ID != vmId {
+		tflog.Warn(ctx, "Disk is no longer attached to the VM, removing the attachment from the state", map[string]interface{}{
+			"vm_id":   vmId,
+			"disk_id": disk.ID,
+		})
+		d.SetId("")
+		return nil
+	}
+
+	return nil
+}
+
+func resourceVmDiskAttachmentDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	vmId := d.Get("vm_id").(string)
+
+	unlock, err := lockObject(ctx, meta, "vm", vmId)
+	if err != nil {
+		return diag.Errorf("[ERROR-057]: %s", err)
+	}
+	defer unlock()
+
+	// The disk or the vm may be gone already, or the disk moved to another vm.
+	// Only a disk still attached to this vm is detached.
+	disk, err := manager.GetDisk(d.Get("disk_id").(string))
+	if err != nil {
+		if apiErr, ok := err.(*bcc.ApiError); ok && apiErr.Code() == 404 {
+			return nil
+		}
+		return diag.Errorf("[ERROR-057] crash via get disk: %s", err)
+	}
+	if disk.Vm == nil || disk.Vm.ID != vmId {
+		return nil
+	}
+
+	vm, err := manager.GetVm(vmId)
+	if err != nil {
+		return diag.Errorf("[ERROR-057] crash via get vm: %s", err)
+	}
+	if err = callUnlocked(ctx, func() error { return vm.DetachDisk(disk) }, vm); err != nil {
+		return diag.Errorf("[ERROR-057] crash via detaching disk: %s", err)
+	}
+	if err = waitLock(ctx, vm); err != nil {
+		return diag.Errorf("[ERROR-057] crash via detaching disk: %s", err)
+	}
+	tflog.Info(ctx, "Disk detached", map[string]interface{}{"vm_id": vmId, "disk_id": disk.ID})
+
+	return nil
+}
+
+func resourceVmDiskAttachmentImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	vmId, diskId, ok := strings.Cut(d.Id(), "/")
+	if !ok || vmId == "" || diskId == "" {
+		return nil, fmt.Errorf("[ERROR-057]: unexpected id %q, expected vm_id/disk_id", d.Id())
+	}
+
+	d.SetId(vmDiskAttachmentId(vmId, diskId))
+	if err := d.Set("vm_id", vmId); err != nil {
+		return nil, err
+	}
+	if err := d.Set("disk_id", diskId); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func vmDiskAttachmentId(vmId string, diskId string) string {
+	return vmId + "/" + diskId
+}
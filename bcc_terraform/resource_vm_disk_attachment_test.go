@@ -0,0 +1,84 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testAccVmDiskAttachmentConfig(fake *fakeBcc, attached bool) string {
+	config := testAccVmConfig(fake, "tf-acc-vm") + fmt.Sprintf(`
+resource "basis_disk" "shared" {
+  vdc_id             = basis_vdc.test.id
+  name               = "tf-acc-shared"
+  size               = 10
+  storage_profile_id = %q
+}
+`, fakeStorageProfileID)
+	config = strings.Replace(config, `user_data   = ""`, `user_data   = ""
+  exclusive_disks = false`, 1)
+	if attached {
+		config += `
+resource "basis_vm_disk_attachment" "test" {
+  vm_id   = basis_vm.test.id
+  disk_id = basis_disk.shared.id
+}
+`
+	}
+	return config
+}
+
+func TestAccVmDiskAttachment_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_disk"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVmDiskAttachmentConfig(fake, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDiskAttached(fake, "basis_disk.shared", "basis_vm.test", true),
+					// basis_vm does not claim the disk attached by the attachment
+					resource.TestCheckResourceAttr("basis_vm.test", "disks.#", "0"),
+				),
+			},
+			{
+				ResourceName:      "basis_vm_disk_attachment.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccVmDiskAttachmentConfig(fake, false),
+				Check:  testAccCheckDiskAttached(fake, "basis_disk.shared", "basis_vm.test", false),
+			},
+		},
+	})
+}
+
+// testAccCheckDiskAttached makes sure the disk is attached to the vm, or to no vm.
+func testAccCheckDiskAttached(fake *fakeBcc, diskName string, vmName string, attached bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		disk, ok := s.RootModule().Resources[diskName]
+		if !ok {
+			return fmt.Errorf("%s not found", diskName)
+		}
+		vm, ok := s.RootModule().Resources[vmName]
+		if !ok {
+			return fmt.Errorf("%s not found", vmName)
+		}
+
+		current := fake.DiskVm(disk.Primary.ID)
+		switch {
+		case attached && current != vm.Primary.ID:
+			return fmt.Errorf("disk %s is not attached to vm %s", disk.Primary.ID, vm.Primary.ID)
+		case !attached && current != "":
+			return fmt.Errorf("disk %s is still attached to vm %s", disk.Primary.ID, current)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,279 @@
+package bcc_terraform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/id"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceVmGroup() *schema.Resource {
+	args := Defaults()
+	args.injectContextResourceVmGroup()
+	args.injectContextRequiredVdc()
+
+	return &schema.Resource{
+		CreateContext: resourceVmGroupCreate,
+		UpdateContext: resourceVmGroupUpdate,
+		ReadContext:   resourceVmGroupRead,
+		DeleteContext: resourceVmGroupDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(60 * time.Minute),
+			Delete: schema.DefaultTimeout(30 * time.Minute),
+		},
+		CustomizeDiff: customdiff.All(customizeDiffDefaultVdc, resourceVmGroupCustomizeDiff),
+		Schema:        args,
+	}
+}
+
+// resourceVmGroupCustomizeDiff plans new members when Vms are missing, are
+// too many or were created from other settings.
+func resourceVmGroupCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+	if d.HasChanges("member_count", "name_prefix", "vm", "affinity_group_id") || !d.NewValueKnown("vm") {
+		return d.SetNewComputed("members")
+	}
+
+	hash, err := vmGroupConfigHash(d.Get("vm").([]interface{}), d.Get("affinity_group_id").(string))
+	if err != nil {
+		return err
+	}
+	members := expandVmGroupMembers(d.Get("members"))
+	if len(members) != d.Get("member_count").(int) {
+		return d.SetNewComputed("members")
+	}
+	for _, member := range members {
+		if member.configHash != hash {
+			return d.SetNewComputed("members")
+		}
+	}
+	return nil
+}
+
+func resourceVmGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId(id.UniqueId())
+	if err := d.Set("members", []interface{}{}); err != nil {
+		return diag.Errorf("[ERROR-060] crash via set attrs: %s", err)
+	}
+
+	if err := applyVmGroup(ctx, d, meta, nil); err != nil {
+		return diag.Errorf("[ERROR-060]: %s", err)
+	}
+	tflog.Info(ctx, "VM group created", map[string]interface{}{"id": d.Id()})
+
+	return resourceVmGroupRead(ctx, d, meta)
+}
+
+func resourceVmGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	oldPool, _ := d.GetChange("lbaas_pool")
+	if err := applyVmGroup(ctx, d, meta, expandVmGroupPool(oldPool)); err != nil {
+		return diag.Errorf("[ERROR-060]: %s", err)
+	}
+
+	return resourceVmGroupRead(ctx, d, meta)
+}
+
+// applyVmGroup brings the members in line with the configuration: surplus
+// members are deleted, missing ones are created and outdated ones are
+// replaced, at most max_unavailable at once. The state is updated after every
+// step, so a failed apply keeps the Vms which exist.
+func applyVmGroup(ctx context.Context, d *schema.ResourceData, meta interface{}, oldPool *vmGroupPool) error {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+
+	vdc, err := GetVdcById(d, manager)
+	if err != nil {
+		return err
+	}
+	config, err := expandVmGroupConfig(d, meta, manager, vdc)
+	if err != nil {
+		return err
+	}
+	pool := expandVmGroupPool(d.Get("lbaas_pool"))
+	memberCount := d.Get("member_count").(int)
+	parallelism := d.Get("parallelism").(int)
+	maxUnavailable := d.Get("max_unavailable").(int)
+
+	oldMembers, _ := d.GetChange("members")
+	members := expandVmGroupMembers(oldMembers)
+	var mu sync.Mutex
+	save := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		return d.Set("members", flattenVmGroupMembers(members))
+	}
+	setMember := func(index int, member *vmGroupMember) {
+		mu.Lock()
+		defer mu.Unlock()
+		if member == nil {
+			delete(members, index)
+		} else {
+			members[index] = member
+		}
+	}
+	create := func(index int) error {
+		member, err := createVmGroupMember(ctx, meta, manager, vdc, config, pool, index)
+		if member != nil {
+			setMember(index, member)
+		}
+		return err
+	}
+	getMember := func(index int) *vmGroupMember {
+		mu.Lock()
+		defer mu.Unlock()
+		return members[index]
+	}
+	remove := func(index int, pool *vmGroupPool) error {
+		if err := deleteVmGroupMember(ctx, meta, manager, pool, getMember(index)); err != nil {
+			return err
+		}
+		setMember(index, nil)
+		return nil
+	}
+
+	poolChanged := d.HasChange("lbaas_pool")
+	if poolChanged && oldPool != nil && len(members) > 0 {
+		if err = registerVmGroupMembers(ctx, meta, manager, oldPool, vmGroupMemberIds(members), false); err != nil {
+			return err
+		}
+	}
+
+	var surplus, missing, outdated, renamed []int
+	for index, member := range members {
+		switch {
+		case index >= memberCount:
+			surplus = append(surplus, index)
+		case member.configHash != config.hash:
+			outdated = append(outdated, index)
+		case member.name != vmGroupMemberName(config.namePrefix, index):
+			renamed = append(renamed, index)
+		}
+	}
+	for index := 0; index < memberCount; index++ {
+		if _, ok := members[index]; !ok {
+			missing = append(missing, index)
+		}
+	}
+
+	err = forEachParallel(ctx, surplus, parallelism, func(index int) error { return remove(index, pool) })
+	if err = errors.Join(err, save()); err != nil {
+		return err
+	}
+
+	err = forEachParallel(ctx, renamed, parallelism, func(index int) error {
+		return renameVmGroupMember(ctx, meta, getMember(index), vmGroupMemberName(config.namePrefix, index))
+	})
+	if err = errors.Join(err, save()); err != nil {
+		return err
+	}
+
+	err = forEachParallel(ctx, missing, parallelism, create)
+	if err = errors.Join(err, save()); err != nil {
+		return err
+	}
+
+	sort.Ints(outdated)
+	for start := 0; start < len(outdated); start += maxUnavailable {
+		batch := outdated[start:min(start+maxUnavailable, len(outdated))]
+		tflog.Info(ctx, "Replacing VM group members", map[string]interface{}{"id": d.Id(), "indexes": batch})
+		err = forEachParallel(ctx, batch, parallelism, func(index int) error {
+			if err := remove(index, pool); err != nil {
+				return err
+			}
+			return create(index)
+		})
+		if err = errors.Join(err, save()); err != nil {
+			return err
+		}
+	}
+
+	if poolChanged && pool != nil && len(members) > 0 {
+		if err = registerVmGroupMembers(ctx, meta, manager, pool, vmGroupMemberIds(members), true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resourceVmGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+
+	members := expandVmGroupMembers(d.Get("members"))
+	for index, member := range members {
+		vm, err := manager.GetVm(member.id)
+		if err != nil {
+			if isNotFound(err) {
+				tflog.Warn(ctx, "VM group member is gone", map[string]interface{}{"id": d.Id(), "index": index, "vm_id": member.id})
+				delete(members, index)
+				continue
+			}
+			return diag.Errorf("[ERROR-060] crash via getting vm-%s: %s", member.id, err)
+		}
+		refreshVmGroupMember(member, vm)
+	}
+
+	if err := d.Set("members", flattenVmGroupMembers(members)); err != nil {
+		return diag.Errorf("[ERROR-060] crash via set attrs: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVmGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+
+	members := expandVmGroupMembers(d.Get("members"))
+	pool := expandVmGroupPool(d.Get("lbaas_pool"))
+	if pool != nil && len(members) > 0 {
+		if err := registerVmGroupMembers(ctx, meta, manager, pool, vmGroupMemberIds(members), false); err != nil {
+			return diag.Errorf("[ERROR-060]: %s", err)
+		}
+	}
+
+	indexes := make([]int, 0, len(members))
+	for index := range members {
+		indexes = append(indexes, index)
+	}
+	err := forEachParallel(ctx, indexes, d.Get("parallelism").(int), func(index int) error {
+		return deleteVmGroupMember(ctx, meta, manager, nil, members[index])
+	})
+	if err != nil {
+		return diag.Errorf("[ERROR-060]: %s", err)
+	}
+	tflog.Info(ctx, "VM group deleted", map[string]interface{}{"id": d.Id()})
+
+	return nil
+}
+
+func renameVmGroupMember(ctx context.Context, meta interface{}, member *vmGroupMember, name string) error {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	vm, err := manager.GetVm(member.id)
+	if err != nil {
+		return fmt.Errorf("crash via getting vm %s: %s", member.name, err)
+	}
+	vm.Name = name
+	if err = callUnlocked(ctx, vm.Update, vm); err != nil {
+		return fmt.Errorf("crash via renaming vm %s: %s", member.name, err)
+	}
+	member.name = name
+	return nil
+}
+
+func vmGroupMemberIds(members map[int]*vmGroupMember) []string {
+	ids := make([]string, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.id)
+	}
+	return ids
+}
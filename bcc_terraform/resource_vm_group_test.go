@@ -0,0 +1,159 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testAccVmGroupConfig(fake *fakeBcc, memberCount int, userData string) string {
+	return testAccVmConfig(fake, "tf-acc-vm") + fmt.Sprintf(`
+resource "basis_lbaas" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-lbaas"
+
+  port {
+    network_id = basis_network.test.id
+    ip_address = "10.0.2.60"
+  }
+}
+
+resource "basis_lbaas_pool" "test" {
+  lbaas_id          = basis_lbaas.test.id
+  port              = 80
+  method            = "ROUND_ROBIN"
+  protocol          = "TCP"
+  exclusive_members = false
+
+  member {
+    vm_id = basis_vm.test.id
+    port  = 8080
+  }
+}
+
+resource "basis_vm_group" "test" {
+  vdc_id          = basis_vdc.test.id
+  member_count    = %d
+  name_prefix     = "tf-acc-worker"
+  max_unavailable = 1
+
+  vm {
+    cpu                            = 1
+    ram                            = 1
+    template_id                    = %q
+    user_data                      = %q
+    system_disk_size               = 10
+    system_disk_storage_profile_id = %q
+    network_ids                    = [basis_network.test.id]
+  }
+
+  lbaas_pool {
+    lbaas_id = basis_lbaas.test.id
+    pool_id  = basis_lbaas_pool.test.id
+    port     = 8080
+  }
+}
+`, memberCount, fakeTemplateID, userData, fakeStorageProfileID)
+}
+
+func TestAccVmGroup_rolling(t *testing.T) {
+	fake := newFakeBcc(t)
+	var firstIds []string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckVmGroupDestroyed(fake),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVmGroupConfig(fake, 2, "v1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_vm_group.test", "members.#", "2"),
+					resource.TestCheckResourceAttr("basis_vm_group.test", "members.1.name", "tf-acc-worker-2"),
+					resource.TestCheckResourceAttrSet("basis_vm_group.test", "members.0.ip_addresses.0"),
+					// the pool resource keeps only its own member
+					resource.TestCheckResourceAttr("basis_lbaas_pool.test", "member.#", "1"),
+					testAccCheckVmGroupPool(fake, 2),
+					testAccSaveVmGroupIds(&firstIds),
+				),
+			},
+			{
+				Config: testAccVmGroupConfig(fake, 2, "v2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_vm_group.test", "members.#", "2"),
+					testAccCheckVmGroupPool(fake, 2),
+					func(s *terraform.State) error {
+						for _, id := range firstIds {
+							if fake.Exists(id) {
+								return fmt.Errorf("member %s was not replaced", id)
+							}
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccVmGroupConfig(fake, 1, "v2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_vm_group.test", "members.#", "1"),
+					testAccCheckVmGroupPool(fake, 1),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckVmGroupPool makes sure the pool has the members of the group
+// next to the member of basis_lbaas_pool.
+func testAccCheckVmGroupPool(fake *fakeBcc, groupMembers int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pool := s.RootModule().Resources["basis_lbaas_pool.test"]
+		group := s.RootModule().Resources["basis_vm_group.test"]
+
+		expected := []string{s.RootModule().Resources["basis_vm.test"].Primary.ID}
+		for i := 0; i < groupMembers; i++ {
+			expected = append(expected, group.Primary.Attributes[fmt.Sprintf("members.%d.id", i)])
+		}
+		actual := fake.PoolMembers(pool.Primary.ID)
+		sort.Strings(expected)
+		sort.Strings(actual)
+		if strings.Join(expected, ",") != strings.Join(actual, ",") {
+			return fmt.Errorf("pool members are %v, expected %v", actual, expected)
+		}
+		return nil
+	}
+}
+
+func testAccSaveVmGroupIds(ids *[]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		group := s.RootModule().Resources["basis_vm_group.test"]
+		*ids = nil
+		for key, value := range group.Primary.Attributes {
+			if strings.HasPrefix(key, "members.") && strings.HasSuffix(key, ".id") {
+				*ids = append(*ids, value)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccCheckVmGroupDestroyed makes sure every member of the groups is gone.
+func testAccCheckVmGroupDestroyed(fake *fakeBcc) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "basis_vm_group" {
+				continue
+			}
+			for key, value := range rs.Primary.Attributes {
+				if strings.HasPrefix(key, "members.") && strings.HasSuffix(key, ".id") && fake.Exists(value) {
+					return fmt.Errorf("member %s still exists", value)
+				}
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,271 @@
+package bcc_terraform
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceVmNetworkInterface() *schema.Resource {
+	args := Defaults()
+	args.injectContextResourceVmNetworkInterface()
+
+	return &schema.Resource{
+		CreateContext: resourceVmNetworkInterfaceCreate,
+		UpdateContext: resourceVmNetworkInterfaceUpdate,
+		ReadContext:   resourceVmNetworkInterfaceRead,
+		DeleteContext: resourceVmNetworkInterfaceDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVmNetworkInterfaceImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		CustomizeDiff: customizeDiffPortIp,
+		Schema:        args,
+	}
+}
+
+func resourceVmNetworkInterfaceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	vmId := d.Get("vm_id").(string)
+
+	unlock, err := lockObject(ctx, meta, "vm", vmId)
+	if err != nil {
+		return diag.Errorf("[ERROR-058]: %s", err)
+	}
+	defer unlock()
+
+	vm, err := manager.GetVm(vmId)
+	if err != nil {
+		return diag.Errorf("[ERROR-058] crash via get vm: %s", err)
+	}
+
+	var firewalls []*bcc.FirewallTemplate
+	if _, ok := d.GetOk("firewall_templates"); ok {
+		if firewalls, err = expandPortFirewallTemplates(d, manager); err != nil {
+			return diag.Errorf("[ERROR-058] crash via getting Firewall Template: %s", err)
+		}
+	}
+
+	var port *bcc.Port
+	if portId, ok := d.GetOk("port_id"); ok {
+		if port, err = manager.GetPort(portId.(string)); err != nil {
+			return diag.Errorf("[ERROR-058] crash via get port: %s", err)
+		}
+		if port.Connected != nil && port.Connected.ID != vm.ID {
+			return diag.Errorf("[ERROR-058] port %s is already connected to %s. please disconnect it before conecting", port.ID, port.Connected.ID)
+		}
+		ipAddress, changeIp := d.GetOk("ip_address")
+		if changeIp {
+			ipAddressStr := ipAddress.(string)
+			port.IpAddress = &ipAddressStr
+		}
+		if firewalls != nil {
+			port.FirewallTemplates = firewalls
+		}
+		if port.Connected == nil {
+			if err = callUnlocked(ctx, func() error { return vm.ConnectPort(port, true) }, vm); err != nil {
+				return diag.Errorf("[ERROR-058] crash via connecting port: %s", err)
+			}
+		} else if changeIp || firewalls != nil {
+			// The port is already connected to the vm, only its settings
+			// are applied.
+			if err = port.Update(); err != nil {
+				return diag.Errorf("[ERROR-058] crash via updating port: %s", err)
+			}
+			if err = waitLock(ctx, port); err != nil {
+				return diag.Errorf("[ERROR-058] crash via port waitlock: %s", err)
+			}
+		}
+	} else {
+		network, err := manager.GetNetwork(d.Get("network_id").(string))
+		if err != nil {
+			return diag.Errorf("[ERROR-058] crash via get network: %s", err)
+		}
+		ipAddressStr := "0.0.0.0"
+		if ipAddress, ok := d.GetOk("ip_address"); ok {
+			ipAddressStr = ipAddress.(string)
+		}
+		newPort := bcc.NewPort(network, firewalls, ipAddressStr)
+		port = &newPort
+		if err = callUnlocked(ctx, func() error { return vm.ConnectPort(port, false) }, vm); err != nil {
+			return diag.Errorf("[ERROR-058] crash via creating port: %s", err)
+		}
+	}
+	if err = waitLock(ctx, vm); err != nil {
+		return diag.Errorf("[ERROR-058] crash via connecting port: %s", err)
+	}
+
+	d.SetId(port.ID)
+	if err = d.Set("created_port", d.Get("port_id").(string) == ""); err != nil {
+		return diag.Errorf("[ERROR-058] crash via set attrs: %s", err)
+	}
+	tflog.Info(ctx, "Network interface connected", map[string]interface{}{"vm_id": vm.ID, "port_id": port.ID})
+
+	return resourceVmNetworkInterfaceRead(ctx, d, meta)
+}
+
+func resourceVmNetworkInterfaceUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+
+	unlock, err := lockObject(ctx, meta, "vm", d.Get("vm_id").(string))
+	if err != nil {
+		return diag.Errorf("[ERROR-058]: %s", err)
+	}
+	defer unlock()
+
+	port, err := manager.GetPort(d.Id())
+	if err != nil {
+		return diag.Errorf("[ERROR-058] crash via get port: %s", err)
+	}
+
+	if d.HasChange("ip_address") {
+		ipAddress := d.Get("ip_address").(string)
+		port.IpAddress = &ipAddress
+	}
+	if d.HasChange("firewall_templates") {
+		if port.FirewallTemplates, err = expandPortFirewallTemplates(d, manager); err != nil {
+			return diag.Errorf("[ERROR-058] crash via updating Firewall Template: %s", err)
+		}
+	}
+	if err = port.Update(); err != nil {
+		return diag.Errorf("[ERROR-058] crash via updating port: %s", err)
+	}
+	if err = waitLock(ctx, port); err != nil {
+		return diag.Errorf("[ERROR-058] crash via port waitlock: %s", err)
+	}
+
+	return resourceVmNetworkInterfaceRead(ctx, d, meta)
+}
+
+func resourceVmNetworkInterfaceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	port, err := manager.GetPort(d.Id())
+	if err != nil {
+		return resourceReadCheck(d, err, "[ERROR-058]")
+	}
+
+	// A port disconnected or moved to another vm outside of Terraform shows
+	// up as a change of vm_id, which connects the interface again.
+	vmId := ""
+	if port.Connected != nil {
+		vmId = port.Connected.ID
+	}
+	if vmId != d.Get("vm_id").(string) {
+		tflog.Warn(ctx, "Port is no longer connected to the VM", map[string]interface{}{
+			"vm_id":     d.Get("vm_id"),
+			"port_id":   port.ID,
+			"connected": vmId,
+		})
+	}
+
+	firewallTemplates := make([]*string, len(port.FirewallTemplates))
+	for i, firewall := range port.FirewallTemplates {
+		firewallTemplates[i] = &firewall.ID
+	}
+
+	fields := map[string]interface{}{
+		"vm_id":              vmId,
+		"network_id":         port.Network.ID,
+		"ip_address":         port.IpAddress,
+		"firewall_templates": firewallTemplates,
+	}
+
+	if err = setResourceDataFromMap(d, fields); err != nil {
+		return diag.Errorf("[ERROR-058] crash via set attrs: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVmNetworkInterfaceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	vmId := d.Get("vm_id").(string)
+
+	unlock, err := lockObject(ctx, meta, "vm", vmId)
+	if err != nil {
+		return diag.Errorf("[ERROR-058]: %s", err)
+	}
+	defer unlock()
+
+	port, err := manager.GetPort(d.Id())
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return diag.Errorf("[ERROR-058] crash via get port: %s", err)
+	}
+
+	// A port created by the interface goes away with it, an existing or an
+	// imported one is only disconnected.
+	if d.Get("created_port").(bool) {
+		if err = port.ForceDelete(); err != nil {
+			return diag.Errorf("[ERROR-058] crash via deleting port: %s", err)
+		}
+		if err = waitLock(ctx, port); err != nil && !isNotFound(err) {
+			return diag.Errorf("[ERROR-058] crash via deleting port: %s", err)
+		}
+		tflog.Info(ctx, "Network interface deleted", map[string]interface{}{"vm_id": vmId, "port_id": port.ID})
+		return nil
+	}
+
+	if port.Connected == nil || port.Connected.ID != vmId {
+		return nil
+	}
+	vm, err := manager.GetVm(vmId)
+	if err != nil {
+		return diag.Errorf("[ERROR-058] crash via get vm: %s", err)
+	}
+	if err = callUnlocked(ctx, func() error { return vm.DisconnectPort(port) }, vm); err != nil {
+		return diag.Errorf("[ERROR-058] crash via disconnecting port: %s", err)
+	}
+	if err = waitLock(ctx, vm); err != nil {
+		return diag.Errorf("[ERROR-058] crash via disconnecting port: %s", err)
+	}
+	tflog.Info(ctx, "Network interface disconnected", map[string]interface{}{"vm_id": vmId, "port_id": port.ID})
+
+	return nil
+}
+
+func resourceVmNetworkInterfaceImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	port, err := manager.GetPort(d.Id())
+	if err != nil {
+		tflog.Error(ctx, "[ERROR-058] crash via getting port", map[string]interface{}{"id": d.Id(), "error": err.Error()})
+		return nil, err
+	}
+
+	// The interface didn't create the port, so destroying it must keep the
+	// port.
+	d.SetId(port.ID)
+	fields := map[string]interface{}{
+		"port_id":      port.ID,
+		"created_port": false,
+	}
+	if err = setResourceDataFromMap(d, fields); err != nil {
+		return nil, fmt.Errorf("[ERROR-058] crash via set attrs: %s", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func expandPortFirewallTemplates(d *schema.ResourceData, manager *bcc.Manager) ([]*bcc.FirewallTemplate, error) {
+	firewallsResourceData := d.Get("firewall_templates").(*schema.Set).List()
+	firewalls := make([]*bcc.FirewallTemplate, len(firewallsResourceData))
+	for j, firewallId := range firewallsResourceData {
+		portFirewall, err := manager.GetFirewallTemplate(firewallId.(string))
+		if err != nil {
+			return nil, err
+		}
+		firewalls[j] = portFirewall
+	}
+	return firewalls, nil
+}
@@ -0,0 +1,143 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testAccVmNetworkInterfaceConfig(fake *fakeBcc, connected bool) string {
+	config := testAccVmConfig(fake, "tf-acc-vm") + `
+resource "basis_firewall_template" "test" {
+  vdc_id = basis_vdc.test.id
+  name   = "tf-acc-firewall"
+}
+
+resource "basis_port" "shared" {
+  vdc_id     = basis_vdc.test.id
+  network_id = basis_network.test.id
+}
+`
+	config = strings.Replace(config, `user_data   = ""`, `user_data   = ""
+  exclusive_networks = false`, 1)
+	if connected {
+		config += `
+resource "basis_vm_network_interface" "test" {
+  vm_id              = basis_vm.test.id
+  network_id         = basis_network.test.id
+  ip_address         = "10.0.2.60"
+  firewall_templates = [basis_firewall_template.test.id]
+}
+
+resource "basis_vm_network_interface" "shared" {
+  vm_id   = basis_vm.test.id
+  port_id = basis_port.shared.id
+}
+`
+	}
+	return config
+}
+
+func TestAccVmNetworkInterface_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+	var createdPort string
+	var sharedPort, vmId string
+	var sharedConnected int
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_vm_network_interface"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVmNetworkInterfaceConfig(fake, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPortConnected(fake, "basis_vm_network_interface.test", "basis_vm.test"),
+					testAccCheckPortConnected(fake, "basis_port.shared", "basis_vm.test"),
+					resource.TestCheckResourceAttr("basis_vm_network_interface.test", "ip_address", "10.0.2.60"),
+					resource.TestCheckResourceAttr("basis_vm_network_interface.test", "firewall_templates.#", "1"),
+					resource.TestCheckResourceAttrPair("basis_vm_network_interface.shared", "network_id", "basis_network.test", "id"),
+					resource.TestCheckResourceAttr("basis_vm_network_interface.test", "created_port", "true"),
+					resource.TestCheckResourceAttr("basis_vm_network_interface.shared", "created_port", "false"),
+					// basis_vm does not claim the ports of the interfaces
+					resource.TestCheckResourceAttr("basis_vm.test", "networks.#", "1"),
+					func(s *terraform.State) error {
+						createdPort = s.RootModule().Resources["basis_vm_network_interface.test"].Primary.ID
+						return nil
+					},
+				),
+			},
+			{
+				// An imported interface keeps its port, see the shared one.
+				ResourceName:            "basis_vm_network_interface.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"port_id", "created_port"},
+			},
+			{
+				ResourceName:      "basis_vm_network_interface.shared",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// The port disconnected outside of Terraform is connected again.
+				PreConfig: func() { fake.DisconnectPort(createdPort) },
+				Config:    testAccVmNetworkInterfaceConfig(fake, true),
+				Check:     testAccCheckPortConnected(fake, "basis_vm_network_interface.test", "basis_vm.test"),
+			},
+			{
+				Config: testAccVmNetworkInterfaceConfig(fake, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_port.shared"),
+					func(s *terraform.State) error {
+						port := s.RootModule().Resources["basis_port.shared"].Primary.ID
+						if device := fake.PortDevice(port); device != "" {
+							return fmt.Errorf("port %s is still connected to %s", port, device)
+						}
+						sharedPort = port
+						vmId = s.RootModule().Resources["basis_vm.test"].Primary.ID
+						return nil
+					},
+				),
+			},
+			{
+				// A port already connected to the vm is adopted without
+				// connecting it again.
+				PreConfig: func() { sharedConnected = fake.ConnectPort(sharedPort, vmId) },
+				Config:    testAccVmNetworkInterfaceConfig(fake, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPortConnected(fake, "basis_vm_network_interface.shared", "basis_vm.test"),
+					func(s *terraform.State) error {
+						if fake.PortConnectedAt(sharedPort) != sharedConnected {
+							return fmt.Errorf("port %s was connected again", sharedPort)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckPortConnected makes sure the port of the resource is connected
+// to the vm.
+func testAccCheckPortConnected(fake *fakeBcc, portName string, vmName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		port, ok := s.RootModule().Resources[portName]
+		if !ok {
+			return fmt.Errorf("%s not found", portName)
+		}
+		vm, ok := s.RootModule().Resources[vmName]
+		if !ok {
+			return fmt.Errorf("%s not found", vmName)
+		}
+
+		if device := fake.PortDevice(port.Primary.ID); device != vm.Primary.ID {
+			return fmt.Errorf("port %s is connected to %q, not to vm %s", port.Primary.ID, device, vm.Primary.ID)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,521 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccVm_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_vm"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVmConfig(fake, "tf-acc-vm"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_vm.test"),
+					resource.TestCheckResourceAttr("basis_vm.test", "power", "true"),
+					resource.TestCheckResourceAttr("basis_vm.test", "platform", fakePlatformID),
+					resource.TestCheckResourceAttr("basis_vm.test", "system_disk.0.size", "10"),
+					resource.TestCheckResourceAttr("basis_vm.test", "networks.#", "1"),
+					resource.TestCheckResourceAttrSet("basis_vm.test", "networks.0.ip_address"),
+					resource.TestCheckResourceAttr("basis_vm.test", "floating", "false"),
+				),
+			},
+			{
+				Config: testAccNetworkConfig(fake) + fmt.Sprintf(`
+resource "basis_port" "vm" {
+  vdc_id     = basis_vdc.test.id
+  network_id = basis_network.test.id
+}
+
+resource "basis_disk" "data" {
+  vdc_id             = basis_vdc.test.id
+  name               = "tf-acc-data"
+  size               = 20
+  storage_profile_id = %[2]q
+}
+
+resource "basis_vm" "test" {
+  vdc_id      = basis_vdc.test.id
+  name        = "tf-acc-vm-renamed"
+  cpu         = 2
+  ram         = 2
+  template_id = %[1]q
+  user_data   = ""
+  floating    = true
+  tags        = ["vm"]
+
+  system_disk {
+    size               = 15
+    storage_profile_id = %[2]q
+  }
+
+  networks {
+    id = basis_port.vm.id
+  }
+
+  disks = [basis_disk.data.id]
+}
+`, fakeTemplateID, fakeStorageProfileID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_vm.test", "name", "tf-acc-vm-renamed"),
+					resource.TestCheckResourceAttr("basis_vm.test", "cpu", "2"),
+					resource.TestCheckResourceAttr("basis_vm.test", "system_disk.0.size", "15"),
+					resource.TestCheckResourceAttr("basis_vm.test", "disks.#", "1"),
+					resource.TestCheckResourceAttr("basis_vm.test", "floating", "true"),
+					resource.TestCheckResourceAttrSet("basis_vm.test", "floating_ip"),
+				),
+			},
+			{
+				ResourceName:            "basis_vm.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"user_data"},
+			},
+		},
+	})
+}
+
+func TestAccVm_powerOff(t *testing.T) {
+	fake := newFakeBcc(t)
+	config := testAccVmConfig(fake, "tf-acc-vm")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_vm"),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				Config: strings.Replace(config, `user_data   = ""`, `user_data   = ""
+  power       = false`, 1),
+				Check: resource.TestCheckResourceAttr("basis_vm.test", "power", "false"),
+			},
+		},
+	})
+}
+
+func TestAccVm_sizingValidation(t *testing.T) {
+	fake := newFakeBcc(t)
+	config := testAccVmConfig(fake, "tf-acc-vm")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_vm"),
+		Steps: []resource.TestStep{
+			{
+				Config:      strings.Replace(config, "size               = 10", "size               = 5", 1),
+				ExpectError: regexp.MustCompile(`needs a system disk of at least 10 GB, got 5`),
+			},
+			{
+				Config:      strings.Replace(config, "ram         = 1", "ram         = 0.5", 1),
+				ExpectError: regexp.MustCompile(`needs at least 1 GB of ram, got 0.5`),
+			},
+			{
+				Config: strings.Replace(config, "size               = 10", "size               = 15", 1),
+			},
+			{
+				Config:      config,
+				ExpectError: regexp.MustCompile(`the system disk can't be shrunk from 15 to 10 GB`),
+			},
+		},
+	})
+}
+
+func TestAccVm_powerState(t *testing.T) {
+	fake := newFakeBcc(t)
+	config := func(extra string) string {
+		return strings.Replace(testAccVmConfig(fake, "tf-acc-vm"), `user_data   = ""`, `user_data   = ""
+`+extra, 1)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_vm"),
+		Steps: []resource.TestStep{
+			{
+				Config: config(`power_state = "running"
+  reboot_triggers = { user_data = "v1" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_vm.test", "power_state", "running"),
+					resource.TestCheckResourceAttr("basis_vm.test", "power", "true"),
+					testAccCheckVmStates(fake, "basis_vm.test"),
+				),
+			},
+			{
+				Config: config(`power_state = "running"
+  reboot_triggers = { user_data = "v2" }`),
+				Check: testAccCheckVmStates(fake, "basis_vm.test", "reboot"),
+			},
+			{
+				PreConfig: func() { fake.SetIgnoreShutdown(true) },
+				Config: config(`power_state = "stopped"
+  graceful_shutdown_timeout = "1s"
+  reboot_triggers = { user_data = "v2" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_vm.test", "power_state", "stopped"),
+					resource.TestCheckResourceAttr("basis_vm.test", "power", "false"),
+					testAccCheckVmStates(fake, "basis_vm.test", "reboot", "shutdown", "power_off"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckVmStates makes sure the given state changes were requested for the vm.
+func testAccCheckVmStates(fake *fakeBcc, name string, expected ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("%s not found", name)
+		}
+		got := fake.VmStates(rs.Primary.ID)
+		if strings.Join(got, ",") != strings.Join(expected, ",") {
+			return fmt.Errorf("expected state changes %v, got %v", expected, got)
+		}
+		return nil
+	}
+}
+
+func testAccVmDataDiskConfig(fake *fakeBcc, disks string) string {
+	return strings.Replace(testAccVmConfig(fake, "tf-acc-vm"), `  system_disk {
+    size               = 10`, disks+`
+  system_disk {
+    name               = "root"
+    size               = 10`, 1)
+}
+
+func TestAccVm_dataDisks(t *testing.T) {
+	fake := newFakeBcc(t)
+	var dataDisks []string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy: func(s *terraform.State) error {
+			for _, id := range dataDisks {
+				if fake.Exists(id) {
+					return fmt.Errorf("data disk %s still exists", id)
+				}
+			}
+			return testAccCheckDestroyed(fake, "basis_vm")(s)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVmDataDiskConfig(fake, fmt.Sprintf(`
+  data_disk {
+    name               = "data"
+    size               = 20
+    storage_profile_id = %[1]q
+    tags               = ["data"]
+  }
+
+  data_disk {
+    name               = "logs"
+    size               = 5
+    storage_profile_id = %[1]q
+  }
+`, fakeStorageProfileID)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_vm.test", "system_disk.0.name", "root"),
+					resource.TestCheckResourceAttr("basis_vm.test", "data_disk.#", "2"),
+					resource.TestCheckResourceAttr("basis_vm.test", "data_disk.0.name", "data"),
+					resource.TestCheckResourceAttr("basis_vm.test", "data_disk.0.tags.#", "1"),
+					resource.TestCheckResourceAttrSet("basis_vm.test", "data_disk.1.id"),
+					// the data disks are not listed in `disks`
+					resource.TestCheckResourceAttr("basis_vm.test", "disks.#", "0"),
+					func(s *terraform.State) error {
+						attrs := s.RootModule().Resources["basis_vm.test"].Primary.Attributes
+						dataDisks = []string{attrs["data_disk.0.id"], attrs["data_disk.1.id"]}
+						return nil
+					},
+				),
+			},
+			{
+				// Removing a block deletes its disk only, a block added at the
+				// same time gets a new disk even though it is smaller.
+				Config: testAccVmDataDiskConfig(fake, fmt.Sprintf(`
+  data_disk {
+    name               = "data"
+    size               = 20
+    storage_profile_id = %[1]q
+    tags               = ["data"]
+  }
+
+  data_disk {
+    name               = "cache"
+    size               = 3
+    storage_profile_id = %[1]q
+  }
+`, fakeStorageProfileID)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_vm.test", "data_disk.#", "2"),
+					resource.TestCheckResourceAttr("basis_vm.test", "data_disk.1.name", "cache"),
+					resource.TestCheckResourceAttr("basis_vm.test", "data_disk.1.size", "3"),
+					func(s *terraform.State) error {
+						attrs := s.RootModule().Resources["basis_vm.test"].Primary.Attributes
+						if attrs["data_disk.0.id"] != dataDisks[0] {
+							return fmt.Errorf("data disk was replaced, %s instead of %s", attrs["data_disk.0.id"], dataDisks[0])
+						}
+						if attrs["data_disk.1.id"] == dataDisks[1] {
+							return fmt.Errorf("the disk of the removed block %s was reused", dataDisks[1])
+						}
+						if fake.Exists(dataDisks[1]) {
+							return fmt.Errorf("removed data disk %s still exists", dataDisks[1])
+						}
+						dataDisks = append(dataDisks, attrs["data_disk.1.id"])
+						return nil
+					},
+				),
+			},
+			{
+				// A renamed block is a new disk.
+				Config: testAccVmDataDiskConfig(fake, fmt.Sprintf(`
+  data_disk {
+    name               = "data"
+    size               = 20
+    storage_profile_id = %[1]q
+    tags               = ["data"]
+  }
+
+  data_disk {
+    name               = "cache-renamed"
+    size               = 3
+    storage_profile_id = %[1]q
+  }
+`, fakeStorageProfileID)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_vm.test", "data_disk.#", "2"),
+					resource.TestCheckResourceAttr("basis_vm.test", "data_disk.1.name", "cache-renamed"),
+					func(s *terraform.State) error {
+						attrs := s.RootModule().Resources["basis_vm.test"].Primary.Attributes
+						if fake.Exists(dataDisks[2]) {
+							return fmt.Errorf("the disk %s of the renamed block still exists", dataDisks[2])
+						}
+						dataDisks = append(dataDisks, attrs["data_disk.1.id"])
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccVmDataDiskConfig(fake, fmt.Sprintf(`
+  data_disk {
+    name               = "data"
+    size               = 10
+    storage_profile_id = %[1]q
+    tags               = ["data"]
+  }
+
+  data_disk {
+    name               = "cache-renamed"
+    size               = 3
+    storage_profile_id = %[1]q
+  }
+`, fakeStorageProfileID)),
+				ExpectError: regexp.MustCompile(`data_disk.0.size: the disk can't be shrunk`),
+			},
+		},
+	})
+}
+
+func TestAccVm_onDestroy(t *testing.T) {
+	fake := newFakeBcc(t)
+	config := strings.Replace(testAccVmConfig(fake, "tf-acc-vm"), `user_data   = ""`, `user_data   = ""
+  floating    = true
+  disks       = [basis_disk.data.id]
+
+  on_destroy {
+    delete_data_disks = true
+    delete_ports      = true
+  }`, 1) + fmt.Sprintf(`
+resource "basis_disk" "data" {
+  vdc_id             = basis_vdc.test.id
+  name               = "tf-acc-data"
+  size               = 10
+  storage_profile_id = %q
+}
+`, fakeStorageProfileID)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		// The disk and the port are deleted with the vm, their own delete
+		// finds them gone.
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckDestroyed(fake, "basis_vm"),
+			testAccCheckDestroyed(fake, "basis_disk"),
+			testAccCheckDestroyed(fake, "basis_port"),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_vm.test", "on_destroy.0.delete_data_disks", "true"),
+					resource.TestCheckResourceAttr("basis_vm.test", "on_destroy.0.release_floating_ip", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVm_waitFor(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	pollInterval := vmWaitPollInterval
+	vmWaitPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { vmWaitPollInterval = pollInterval })
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	marker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/done" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(marker.Close)
+
+	config := func(waitFor string) string {
+		return strings.Replace(testAccVmConfig(fake, "tf-acc-vm"), `user_data   = ""`, `user_data   = ""
+`+waitFor, 1)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_vm"),
+		Steps: []resource.TestStep{
+			{
+				Config: config(fmt.Sprintf(`
+  wait_for {
+    ip {}
+    tcp {
+      host = "127.0.0.1"
+      port = %d
+    }
+    cloud_init {
+      url = "%s/done"
+    }
+  }`, port, marker.URL)),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_vm.test"),
+					resource.TestCheckResourceAttr("basis_vm.test", "wait_for.0.tcp.0.timeout", "5m"),
+				),
+			},
+			{
+				// the checks only run on create
+				Config: config(`
+  wait_for {
+    cloud_init {
+      url     = "http://127.0.0.1:1/done"
+      timeout = "1s"
+    }
+  }`),
+			},
+		},
+	})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_vm"),
+		Steps: []resource.TestStep{
+			{
+				Config: config(fmt.Sprintf(`
+  wait_for {
+    cloud_init {
+      url     = "%s/missing"
+      timeout = "50ms"
+    }
+  }`, marker.URL)),
+				ExpectError: regexp.MustCompile(`wait_for.0.cloud_init: .*/missing did not answer with 200 after 50ms: status 404`),
+			},
+		},
+	})
+}
+
+func TestAccVm_templateName(t *testing.T) {
+	fake := newFakeBcc(t)
+	const debianTemplateID = "00000000-0000-4000-8000-7e0000000002"
+	config := strings.Replace(testAccVmConfig(fake, "tf-acc-vm"),
+		fmt.Sprintf("template_id = %q", fakeTemplateID), `template_name = "Debian 12"`, 1)
+	var vmId string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_vm"),
+		Steps: []resource.TestStep{
+			{
+				Config: strings.Replace(testAccVmConfig(fake, "tf-acc-vm"), fakeTemplateID, debianTemplateID, 1),
+				Check: func(s *terraform.State) error {
+					vmId = s.RootModule().Resources["basis_vm.test"].Primary.ID
+					return nil
+				},
+			},
+			{
+				// The name of the same template keeps the Vm.
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_vm.test"),
+					resource.TestCheckResourceAttr("basis_vm.test", "template_id", debianTemplateID),
+					resource.TestCheckResourceAttrWith("basis_vm.test", "id", func(value string) error {
+						if value != vmId {
+							return fmt.Errorf("vm %s was recreated as %s", vmId, value)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				// Another template can't be applied in place, the Vm is recreated.
+				PreConfig: func() { fake.AddTemplate("Ubuntu 22") },
+				Config:    strings.Replace(config, `"Debian 12"`, `"Ubuntu 22"`, 1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(fake, "basis_vm.test"),
+					resource.TestCheckResourceAttrWith("basis_vm.test", "id", func(value string) error {
+						if value == vmId {
+							return fmt.Errorf("vm %s was not recreated", value)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				PreConfig:   func() { fake.AddTemplate("debian 12") },
+				Config:      config,
+				ExpectError: regexp.MustCompile(`template_name: ERROR: 2 Templates match name 'Debian 12'`),
+			},
+		},
+	})
+}
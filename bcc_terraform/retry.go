@@ -0,0 +1,208 @@
+package bcc_terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// RetryPolicy describes how failed API requests are repeated.
+type RetryPolicy struct {
+	MaxAttempts    int
+	MinBackoff     time.Duration
+	MaxBackoff     time.Duration
+	Jitter         bool
+	RetryableCodes []int
+}
+
+var defaultRetryableCodes = []int{409, 423, 429, 500, 502, 503, 504}
+
+func defaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    5,
+		MinBackoff:     time.Second,
+		MaxBackoff:     30 * time.Second,
+		Jitter:         true,
+		RetryableCodes: defaultRetryableCodes,
+	}
+}
+
+func validateDuration(v interface{}, k string) (ws []string, es []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		es = append(es, err)
+	}
+	return
+}
+
+// expandRetryPolicy reads the `retry` block of the provider configuration.
+func expandRetryPolicy(d *schema.ResourceData) (*RetryPolicy, error) {
+	policy := defaultRetryPolicy()
+	if _, ok := d.GetOk("retry.0"); !ok {
+		return policy, nil
+	}
+
+	var err error
+	policy.MaxAttempts = d.Get("retry.0.max_attempts").(int)
+	policy.Jitter = d.Get("retry.0.jitter").(bool)
+	if policy.MinBackoff, err = time.ParseDuration(d.Get("retry.0.min_backoff").(string)); err != nil {
+		return nil, err
+	}
+	if policy.MaxBackoff, err = time.ParseDuration(d.Get("retry.0.max_backoff").(string)); err != nil {
+		return nil, err
+	}
+	if codes := d.Get("retry.0.retryable_codes").([]interface{}); len(codes) > 0 {
+		policy.RetryableCodes = make([]int, len(codes))
+		for i, code := range codes {
+			policy.RetryableCodes[i] = code.(int)
+		}
+	}
+
+	return policy, nil
+}
+
+func (p *RetryPolicy) isRetryableCode(code int) bool {
+	for _, item := range p.RetryableCodes {
+		if item == code {
+			return true
+		}
+	}
+	return false
+}
+
+// isRetryableResponse reports whether the request is sent again after the
+// response. A POST which failed on the server side may still have created the
+// object, so only idempotent requests are repeated after a server error.
+func (p *RetryPolicy) isRetryableResponse(method string, code int) bool {
+	if !p.isRetryableCode(code) {
+		return false
+	}
+	return isIdempotentMethod(method) || code == http.StatusTooManyRequests
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// isDialError reports whether the connection failed before the request was
+// sent, such a request is safe to repeat whatever its method.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// backoff returns the delay before the given retry, attempts are counted from 1.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MinBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter && delay > 0 {
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+	}
+	return delay
+}
+
+// retryAfter parses the Retry-After header, it holds either seconds or a date.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// isObjectLocked reports whether the conflict is about a locked object, bcc-go
+// already waits for those on its own.
+func isObjectLocked(body []byte) bool {
+	var conflict struct {
+		ErrorAlias []string `json:"error_alias"`
+	}
+	if err := json.Unmarshal(body, &conflict); err != nil {
+		return false
+	}
+	return len(conflict.ErrorAlias) > 0 && conflict.ErrorAlias[0] == "object_locked"
+}
+
+// retryTransport repeats requests answered with a retryable status code, so
+// the policy applies to every call bcc-go makes.
+type retryTransport struct {
+	next   http.RoundTripper
+	policy *RetryPolicy
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		current := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			current = req.Clone(req.Context())
+			current.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(current)
+		if err != nil {
+			if attempt >= t.policy.MaxAttempts || !isDialError(err) {
+				return nil, err
+			}
+		} else if attempt >= t.policy.MaxAttempts || !t.policy.isRetryableResponse(req.Method, resp.StatusCode) {
+			return resp, nil
+		}
+
+		fields := map[string]interface{}{
+			"method":  req.Method,
+			"path":    req.URL.Path,
+			"attempt": attempt,
+			"retries": t.policy.MaxAttempts - 1,
+		}
+		delay := t.policy.backoff(attempt)
+		if err != nil {
+			fields["error"] = err.Error()
+		} else {
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode == http.StatusConflict && isObjectLocked(body) {
+				resp.Body = io.NopCloser(bytes.NewReader(body))
+				return resp, nil
+			}
+			if after, ok := retryAfter(resp.Header); ok {
+				delay = after
+			}
+			fields["status"] = resp.StatusCode
+		}
+		fields["delay"] = delay.String()
+		tflog.SubsystemDebug(subsystemLogContext(req.Context(), apiSubsystem), apiSubsystem, "Retrying API request", fields)
+
+		if err = bcc.SleepWithContext(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
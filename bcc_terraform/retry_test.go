@@ -0,0 +1,201 @@
+package bcc_terraform
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+)
+
+func testRetryManager(t *testing.T, fake *fakeBcc, policy *RetryPolicy) *bcc.Manager {
+	t.Helper()
+	config := Config{
+		Token:              fakeToken,
+		APIEndpoint:        fake.URL(),
+		APIRequestTimeout:  time.Minute,
+		APIRequestInterval: 100 * time.Millisecond,
+		TerraformVersion:   "test",
+		Retry:              policy,
+	}
+	client, diags := config.Client()
+	if diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	return client.Manager()
+}
+
+func testRetryPolicy(maxAttempts int) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		MinBackoff:     10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+		RetryableCodes: defaultRetryableCodes,
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := &RetryPolicy{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, delay := range expected {
+		if got := policy.backoff(i + 1); got != delay {
+			t.Fatalf("attempt %d: expected %s, got %s", i+1, delay, got)
+		}
+	}
+
+	policy.Jitter = true
+	for attempt := 1; attempt <= 5; attempt++ {
+		got := policy.backoff(attempt)
+		if limit := expected[attempt-1]; got < limit/2 || got > limit {
+			t.Fatalf("attempt %d: %s is out of [%s, %s]", attempt, got, limit/2, limit)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	cases := map[string]time.Duration{
+		"3": 3 * time.Second,
+		"0": 0,
+		time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat): 0,
+	}
+	for value, expected := range cases {
+		header := http.Header{}
+		header.Set("Retry-After", value)
+		got, ok := retryAfter(header)
+		if !ok || got != expected {
+			t.Fatalf("%q: expected %s, got %s (%t)", value, expected, got, ok)
+		}
+	}
+
+	if _, ok := retryAfter(http.Header{}); ok {
+		t.Fatal("expected no delay without the header")
+	}
+	header := http.Header{}
+	header.Set("Retry-After", "soon")
+	if _, ok := retryAfter(header); ok {
+		t.Fatal("expected an invalid header to be ignored")
+	}
+}
+
+func TestRetry_serverError(t *testing.T) {
+	fake := newFakeBcc(t)
+	manager := testRetryManager(t, fake, testRetryPolicy(5))
+
+	fake.FailNext("GET", "v1/account/me", http.StatusServiceUnavailable, 2, "")
+	if _, err := manager.GetAccount(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := fake.Requests("GET", "v1/account/me"); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestRetry_retryAfter(t *testing.T) {
+	fake := newFakeBcc(t)
+	manager := testRetryManager(t, fake, testRetryPolicy(5))
+
+	fake.FailNext("GET", "v1/account/me", http.StatusTooManyRequests, 1, "1")
+	start := time.Now()
+	if _, err := manager.GetAccount(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("Retry-After was not honored, retried after %s", elapsed)
+	}
+}
+
+func TestRetry_writes(t *testing.T) {
+	fake := newFakeBcc(t)
+	manager := testRetryManager(t, fake, testRetryPolicy(5))
+	project := testCreateProject(t, manager, "project")
+
+	fake.FailNext("PUT", "v1/project/"+project.ID, http.StatusBadGateway, 1, "")
+	project.Name = "renamed"
+	if err := project.Update(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := fake.Requests("PUT", "v1/project/"+project.ID); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+
+	// the body is sent again with every attempt
+	updated, err := manager.GetProject(project.ID)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if updated.Name != "renamed" {
+		t.Fatalf("expected the project to be renamed, got %q", updated.Name)
+	}
+}
+
+func TestRetry_creates(t *testing.T) {
+	fake := newFakeBcc(t)
+	manager := testRetryManager(t, fake, testRetryPolicy(5))
+
+	clients, err := manager.GetClients()
+	if err != nil || len(clients) != 1 {
+		t.Fatalf("expected a single client, got %d: %v", len(clients), err)
+	}
+
+	// the project may have been created before the gateway failed
+	fake.FailNext("POST", "v1/project", http.StatusBadGateway, 1, "")
+	project := bcc.NewProject("project")
+	err = clients[0].CreateProject(&project)
+	if apiErr, ok := err.(*bcc.ApiError); !ok || apiErr.Code() != http.StatusBadGateway {
+		t.Fatalf("expected 502, got %#v", err)
+	}
+	if got := fake.Requests("POST", "v1/project"); got != 1 {
+		t.Fatalf("expected a single attempt, got %d", got)
+	}
+
+	fake.FailNext("POST", "v1/project", http.StatusTooManyRequests, 1, "")
+	if err = clients[0].CreateProject(&project); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := fake.Requests("POST", "v1/project"); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestRetry_failFast(t *testing.T) {
+	fake := newFakeBcc(t)
+	manager := testRetryManager(t, fake, testRetryPolicy(5))
+
+	fake.FailNext("GET", "v1/account/me", http.StatusBadRequest, 5, "")
+	_, err := manager.GetAccount()
+	if apiErr, ok := err.(*bcc.ApiError); !ok || apiErr.Code() != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %#v", err)
+	}
+	if got := fake.Requests("GET", "v1/account/me"); got != 1 {
+		t.Fatalf("expected a single attempt, got %d", got)
+	}
+}
+
+func TestRetry_exhausted(t *testing.T) {
+	fake := newFakeBcc(t)
+	manager := testRetryManager(t, fake, testRetryPolicy(3))
+
+	fake.FailNext("GET", "v1/account/me", http.StatusInternalServerError, 5, "")
+	_, err := manager.GetAccount()
+	if apiErr, ok := err.(*bcc.ApiError); !ok || apiErr.Code() != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %#v", err)
+	}
+	if got := fake.Requests("GET", "v1/account/me"); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestRetry_customCodes(t *testing.T) {
+	fake := newFakeBcc(t)
+	policy := testRetryPolicy(5)
+	policy.RetryableCodes = []int{http.StatusServiceUnavailable}
+	manager := testRetryManager(t, fake, policy)
+
+	fake.FailNext("GET", "v1/account/me", http.StatusTooManyRequests, 1, "")
+	if _, err := manager.GetAccount(); err == nil {
+		t.Fatal("expected 429 to be returned as is")
+	}
+	if got := fake.Requests("GET", "v1/account/me"); got != 1 {
+		t.Fatalf("expected a single attempt, got %d", got)
+	}
+}
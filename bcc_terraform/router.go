@@ -1,11 +1,14 @@
 package bcc_terraform
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
@@ -99,14 +102,16 @@ func (args *Arguments) injectContextDataRouterList() {
 func (args *Arguments) injectReqRouterRoutes() {
 	args.merge(Arguments{
 		"destination": {
-			Type:        schema.TypeString,
-			Required:    true,
-			Description: "route destination",
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validateCidr,
+			Description:  "route destination, a network in CIDR notation",
 		},
 		"next_hop": {
-			Type:        schema.TypeString,
-			Required:    true,
-			Description: "next for before destination",
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.IsIPAddress,
+			Description:  "next for before destination",
 		},
 	})
 }
@@ -170,7 +175,7 @@ func (args *Arguments) injectContextResourceRouter() {
 			Type:        schema.TypeBool,
 			Optional:    true,
 			Default:     false,
-			Deprecated:  "param has been removed",
+			Deprecated:  "param has been removed, manage the default router with basis_default_router",
 			Description: "Determinate if router is system.",
 		},
 		"routes": {
@@ -181,15 +186,39 @@ func (args *Arguments) injectContextResourceRouter() {
 				Schema: routes,
 			},
 		},
-		"tags": newTagNamesResourceSchema("tags of the router"),
+		"exclusive_ports": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "whether `ports` lists every port of the Router, disable it to connect networks with basis_router_interface",
+		},
+		"exclusive_routes": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "whether `routes` lists every route of the Router, disable it to add routes with basis_router_route",
+		},
+		"tags":     newTagNamesResourceSchema("tags of the router"),
+		"tags_all": newTagNamesDataSchema("tags of the router including the provider default tags"),
 	})
 }
 
-func syncRouterPorts(d *schema.ResourceData, manager *bcc.Manager, router *bcc.Router) (err error) {
-	portsIds := d.Get("ports").([]interface{})
+// syncRouterPorts connects the ports of `ports` to the router and disconnects
+// the others. Without exclusive_ports only the ports of the previous state are
+// disconnected, the others belong to basis_router_interface or are connected
+// outside of Terraform.
+func syncRouterPorts(ctx context.Context, d *schema.ResourceData, manager *bcc.Manager, router *bcc.Router) (err error) {
+	oldPorts, newPorts := d.GetChange("ports")
+	portsIds := newPorts.([]interface{})
 	routerId := d.Id()
+	exclusive := d.Get("exclusive_ports").(bool)
+	owned := make(map[string]bool)
+	for _, portId := range oldPorts.([]interface{}) {
+		owned[strings.ToLower(portId.(string))] = true
+	}
 
-	for _, port := range router.Ports {
+	// DisconnectPort removes the port from router.Ports, so a copy is iterated.
+	for _, port := range append([]*bcc.Port(nil), router.Ports...) {
 		found := false
 		for _, portId := range portsIds {
 			if strings.EqualFold(portId.(string), port.ID) {
@@ -198,12 +227,15 @@ func syncRouterPorts(d *schema.ResourceData, manager *bcc.Manager, router *bcc.R
 			}
 		}
 
-		if !found {
+		if !found && (exclusive || owned[strings.ToLower(port.ID)]) {
 			if port.Connected != nil && strings.EqualFold(port.Connected.ID, routerId) {
-				log.Printf("Port %s found on vm and not mentioned in the state."+
-					" Port will be detached", port.ID)
-				router.DisconnectPort(port)
-				port.WaitLock()
+				tflog.Debug(ctx, "Port found on router and not mentioned in the state, it will be detached", map[string]interface{}{"port_id": port.ID})
+				if err := router.DisconnectPort(port); err != nil {
+					return fmt.Errorf("cannot detach port `%s`: %s", port.ID, err)
+				}
+				if err := waitLock(ctx, port); err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -226,14 +258,18 @@ func syncRouterPorts(d *schema.ResourceData, manager *bcc.Manager, router *bcc.R
 				return fmt.Errorf("unable to bind a port that is already connected to the server")
 			}
 			if port.Connected != nil && port.Connected.ID != routerId {
-				router.DisconnectPort(port)
-				port.WaitLock()
+				if err := router.DisconnectPort(port); err != nil {
+					return fmt.Errorf("cannot detach port `%s` from %s: %s", port.ID, port.Connected.ID, err)
+				}
+				if err := waitLock(ctx, port); err != nil {
+					return err
+				}
 			}
 			port, err = manager.GetPort(portId.(string))
 			if err != nil {
 				return fmt.Errorf("cannot get port `%s`: %s", portId, err)
 			}
-			log.Printf("Port `%s` will be Attached", port.ID)
+			tflog.Debug(ctx, "Port will be attached", map[string]interface{}{"port_id": port.ID})
 			if err := router.ConnectPort(port, true); err != nil {
 				return fmt.Errorf("cannot attach port `%s`: %s", port.ID, err)
 			}
@@ -243,7 +279,23 @@ func syncRouterPorts(d *schema.ResourceData, manager *bcc.Manager, router *bcc.R
 	return
 }
 
-func syncFloating(d *schema.ResourceData, router *bcc.Router) (err error) {
+// filterOwnedRouterPorts keeps only the ports listed in `ports`, the others
+// are connected by basis_router_interface or outside of Terraform.
+func filterOwnedRouterPorts(d *schema.ResourceData, ports []*bcc.Port) []*bcc.Port {
+	owned := make(map[string]bool)
+	for _, portId := range d.Get("ports").([]interface{}) {
+		owned[strings.ToLower(portId.(string))] = true
+	}
+	filtered := make([]*bcc.Port, 0, len(ports))
+	for _, port := range ports {
+		if owned[strings.ToLower(port.ID)] {
+			filtered = append(filtered, port)
+		}
+	}
+	return filtered
+}
+
+func syncFloating(ctx context.Context, d *schema.ResourceData, router *bcc.Router) (err error) {
 	oldFloating, newFloating := d.GetChange("floating")
 
 	if !oldFloating.(bool) && newFloating.(bool) {
@@ -252,13 +304,285 @@ func syncFloating(d *schema.ResourceData, router *bcc.Router) (err error) {
 		router.Floating = nil
 	}
 
-	if err = repeatOnError(router.Update, router); err != nil {
+	if err = callUnlocked(ctx, router.Update, router); err != nil {
+		return fmt.Errorf("crash via updating floating for router: %s", err)
+	}
+	if err = waitLock(ctx, router); err != nil {
 		return fmt.Errorf("crash via updating floating for router: %s", err)
 	}
-	router.WaitLock()
 	if err = d.Set("floating", router.Floating != nil); err != nil {
 		return fmt.Errorf("crash via setting floating: %s", err)
 	}
 
 	return nil
 }
+
+func (args *Arguments) injectContextResourceDefaultRouter() {
+	args.merge(Arguments{
+		"name": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+			ValidateFunc: validation.All(
+				validation.NoZeroValues,
+				validation.StringLenBetween(1, 100),
+			),
+			Description: "Name of the Router",
+		},
+		"floating": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "Enable floating ip for the Router",
+		},
+		"floating_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Floating id address.",
+		},
+		"ports": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "List of Ports connected to the router",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"initial_name": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "name of the Router when it was adopted, restored on destroy",
+		},
+		"initial_floating": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "whether the Router had a floating ip when it was adopted, restored on destroy",
+		},
+	})
+}
+
+func (args *Arguments) injectContextResourceRouterRoute() {
+	args.merge(Arguments{
+		"router_id": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.IsUUID,
+			Description:  "id of the Router",
+		},
+		"destination": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validateCidr,
+			Description:  "route destination, a network in CIDR notation",
+		},
+		"next_hop": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.IsIPAddress,
+			Description:  "ip address of the next hop",
+		},
+	})
+}
+
+func (args *Arguments) injectContextResourceRouterInterface() {
+	args.merge(Arguments{
+		"router_id": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.IsUUID,
+			Description:  "id of the Router",
+		},
+		"network_id": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			ForceNew:     true,
+			ExactlyOneOf: []string{"network_id", "port_id"},
+			Description:  "id of the Network, a new Port of the network is connected to the Router",
+		},
+		"port_id": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.IsUUID,
+			ExactlyOneOf: []string{"network_id", "port_id"},
+			Description:  "id of an existing Port connected to the Router, the Port is kept when the interface is destroyed",
+		},
+		"created_port": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "whether the interface created its Port, only such a Port is deleted with the interface",
+		},
+		"ip_address": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "ip_address of the Port, usually the gateway of the subnet",
+			ValidateFunc: validation.All(
+				validation.StringIsNotEmpty,
+				validation.StringDoesNotMatch(regexp.MustCompile(`^0\.0\.0\.0`), "remove ip_address to choose random IP"),
+				validation.IsIPAddress,
+			),
+		},
+	})
+}
+
+// routerRouteKey identifies a route by what it does, two routes may share a
+// destination or a next hop.
+func routerRouteKey(destination string, nextHop string) string {
+	return destination + " via " + nextHop
+}
+
+// routerSupportsRoutes reports whether the hypervisor of the VDC supports
+// static routes, only VMware does.
+func routerSupportsRoutes(vdc *bcc.Vdc) bool {
+	return strings.EqualFold(vdc.Hypervisor.Type, "Vmware")
+}
+
+// syncRouterRoutes brings the routes of the router in line with `routes`.
+// Without exclusive_routes only the routes of the previous state are deleted,
+// the others belong to basis_router_route or are managed outside of Terraform.
+func syncRouterRoutes(ctx context.Context, d *schema.ResourceData, router *bcc.Router) error {
+	oldRoutes, newRoutes := d.GetChange("routes")
+	owned := make(map[string]bool)
+	for _, item := range oldRoutes.([]interface{}) {
+		r := item.(map[string]interface{})
+		owned[routerRouteKey(r["destination"].(string), r["next_hop"].(string))] = true
+	}
+	exclusive := d.Get("exclusive_routes").(bool)
+
+	missing := make(map[string]bool)
+	for _, item := range newRoutes.([]interface{}) {
+		r := item.(map[string]interface{})
+		missing[routerRouteKey(r["destination"].(string), r["next_hop"].(string))] = true
+	}
+
+	for _, route := range router.Routes {
+		key := routerRouteKey(route.Destination, route.NextHop)
+		if missing[key] {
+			delete(missing, key)
+			continue
+		}
+		if !exclusive && !owned[key] {
+			continue
+		}
+		tflog.Debug(ctx, "Deleting route", map[string]interface{}{"router_id": router.ID, "route": key})
+		if err := callUnlocked(ctx, route.Delete, router); err != nil {
+			return fmt.Errorf("crash via deleting route %s: %s", key, err)
+		}
+	}
+
+	for _, item := range newRoutes.([]interface{}) {
+		r := item.(map[string]interface{})
+		route := bcc.NewRoute(r["destination"].(string), r["next_hop"].(string))
+		key := routerRouteKey(route.Destination, route.NextHop)
+		if !missing[key] {
+			continue
+		}
+		tflog.Debug(ctx, "Creating route", map[string]interface{}{"router_id": router.ID, "route": key})
+		if err := callUnlocked(ctx, func() error { return router.CreateRoute(&route) }, router); err != nil {
+			return fmt.Errorf("crash via creating route %s: %s", key, err)
+		}
+	}
+
+	return nil
+}
+
+// flattenRouterRoutes lists the routes in the order of `routes`, new ones
+// last. Without exclusive_routes only the routes of `routes` are listed.
+func flattenRouterRoutes(d *schema.ResourceData, routes []*bcc.Route) []map[string]interface{} {
+	order := make(map[string]int)
+	for i, item := range d.Get("routes").([]interface{}) {
+		r := item.(map[string]interface{})
+		order[routerRouteKey(r["destination"].(string), r["next_hop"].(string))] = i
+	}
+	exclusive := d.Get("exclusive_routes").(bool)
+	position := func(route *bcc.Route) int {
+		if i, ok := order[routerRouteKey(route.Destination, route.NextHop)]; ok {
+			return i
+		}
+		return len(order)
+	}
+
+	sorted := make([]*bcc.Route, 0, len(routes))
+	for _, route := range routes {
+		if exclusive || position(route) < len(order) {
+			sorted = append(sorted, route)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return position(sorted[i]) < position(sorted[j]) })
+
+	result := make([]map[string]interface{}, len(sorted))
+	for i, route := range sorted {
+		result[i] = map[string]interface{}{
+			"destination": route.Destination,
+			"next_hop":    route.NextHop,
+		}
+	}
+	return result
+}
+
+// customizeDiffRouterRoutes rejects duplicated routes, and routes in a VDC
+// whose hypervisor doesn't support them.
+func customizeDiffRouterRoutes(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.HasChanges("routes", "vdc_id") {
+		return nil
+	}
+	routes := d.Get("routes").([]interface{})
+	seen := make(map[string]int)
+	for i, item := range routes {
+		r := item.(map[string]interface{})
+		key := routerRouteKey(r["destination"].(string), r["next_hop"].(string))
+		if j, ok := seen[key]; ok {
+			return fmt.Errorf("routes.%d: the route to %s duplicates routes.%d", i, key, j)
+		}
+		seen[key] = i
+	}
+
+	vdcId := d.Get("vdc_id").(string)
+	if len(routes) == 0 || !d.NewValueKnown("vdc_id") || vdcId == "" {
+		return nil
+	}
+	manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+	vdc, err := manager.GetVdc(vdcId)
+	if err != nil {
+		return fmt.Errorf("vdc_id: vdc '%s' not found: %s", vdcId, err)
+	}
+	if !routerSupportsRoutes(vdc) {
+		return fmt.Errorf("routes: routes are not supported by the %s hypervisor of the vdc %s", vdc.Hypervisor.Type, vdc.Name)
+	}
+	return nil
+}
+
+func findRouterRoute(router *bcc.Router, id string) *bcc.Route {
+	for _, route := range router.Routes {
+		if route.ID == id {
+			return route
+		}
+	}
+	return nil
+}
+
+// getVdcDefaultRouter returns the router created together with the VDC.
+func getVdcDefaultRouter(vdc *bcc.Vdc) (*bcc.Router, error) {
+	routers, err := vdc.GetRouters()
+	if err != nil {
+		return nil, err
+	}
+	for _, router := range routers {
+		if router.IsDefault {
+			return router, nil
+		}
+	}
+	return nil, fmt.Errorf("the vdc %s has no default router", vdc.Name)
+}
+
+// setRouterFloating requests a floating ip for the router or releases it.
+func setRouterFloating(router *bcc.Router, floating bool) {
+	if floating && router.Floating == nil {
+		router.Floating = &bcc.Port{ID: "RANDOM_FIP"}
+	} else if !floating {
+		router.Floating = nil
+	}
+}
@@ -66,7 +66,8 @@ func (args *Arguments) injectContextResourceS3() {
 			Computed:    true,
 			Description: "secret_key for access to s3",
 		},
-		"tags": newTagNamesResourceSchema("tags of the s3"),
+		"tags":     newTagNamesResourceSchema("tags of the s3"),
+		"tags_all": newTagNamesDataSchema("tags of the s3 including the provider default tags"),
 	})
 }
 
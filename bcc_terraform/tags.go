@@ -1,6 +1,8 @@
 package bcc_terraform
 
 import (
+	"context"
+
 	"github.com/basis-cloud/bcc-go/bcc"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -75,3 +77,72 @@ func newTagsDatasourceSchema(description string) *schema.Schema {
 		Description: description,
 	}
 }
+
+// defaultTagNames returns the tags of the provider `default_tags` block.
+func defaultTagNames(meta interface{}) []string {
+	if config, ok := meta.(*CombinedConfig); ok {
+		return config.defaultTags
+	}
+	return nil
+}
+
+// mergeTagNames returns the resource tags followed by the default tags
+// which are not among them.
+func mergeTagNames(tags *schema.Set, defaultTags []string) []interface{} {
+	merged := tags.List()
+	for _, tag := range defaultTags {
+		if !tags.Contains(tag) {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
+
+// expandTags returns the tags of the resource together with the provider
+// default tags, the way they are sent to the API.
+func expandTags(d *schema.ResourceData, meta interface{}) []bcc.Tag {
+	return expandTagNames(d.Get("tags").(*schema.Set), meta)
+}
+
+// expandTagNames is expandTags for the tags of a nested block.
+func expandTagNames(tags *schema.Set, meta interface{}) []bcc.Tag {
+	merged := mergeTagNames(tags, defaultTagNames(meta))
+	return unmarshalTagNames(schema.NewSet(schema.HashString, merged))
+}
+
+// flattenTags returns the tags read from the API without the provider default
+// tags, unless the resource sets them explicitly.
+func flattenTags(d *schema.ResourceData, meta interface{}, tags []bcc.Tag) []interface{} {
+	return flattenTagNames(d.Get("tags").(*schema.Set), meta, tags)
+}
+
+// flattenTagNames is flattenTags for the tags of a nested block.
+func flattenTagNames(configured *schema.Set, meta interface{}, tags []bcc.Tag) []interface{} {
+	defaults := schema.NewSet(schema.HashString, nil)
+	for _, tag := range defaultTagNames(meta) {
+		defaults.Add(tag)
+	}
+
+	flattened := make([]interface{}, 0, len(tags))
+	for _, tag := range tags {
+		if defaults.Contains(tag.Name) && !configured.Contains(tag.Name) {
+			continue
+		}
+		flattened = append(flattened, tag.Name)
+	}
+	return flattened
+}
+
+// customizeDiffTagsAll plans `tags_all`, so a change of the provider default
+// tags updates the resource.
+func customizeDiffTagsAll(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("tags") {
+		return d.SetNewComputed("tags_all")
+	}
+
+	merged := schema.NewSet(schema.HashString, mergeTagNames(d.Get("tags").(*schema.Set), defaultTagNames(meta)))
+	if current, ok := d.Get("tags_all").(*schema.Set); ok && current.Equal(merged) {
+		return nil
+	}
+	return d.SetNew("tags_all", merged)
+}
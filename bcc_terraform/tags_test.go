@@ -0,0 +1,98 @@
+package bcc_terraform
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func testAccDefaultTagsConfig(fake *fakeBcc, defaultTags []string, projectTags []string) string {
+	return fmt.Sprintf(`
+provider "basis" {
+  api_endpoint         = %q
+  token                = %q
+  api_request_interval = "100ms"
+
+  default_tags {
+    tags = [%s]
+  }
+}
+
+resource "basis_project" "test" {
+  name = "tf-acc-project"
+  tags = [%s]
+}
+
+resource "basis_vdc" "test" {
+  name          = "tf-acc-vdc"
+  project_id    = basis_project.test.id
+  hypervisor_id = %q
+}
+`, fake.URL(), fakeToken, testAccQuoteList(defaultTags), testAccQuoteList(projectTags), fakeVmwareHypervisorID)
+}
+
+func testAccQuoteList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = fmt.Sprintf("%q", item)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func TestAccDefaultTags_basic(t *testing.T) {
+	fake := newFakeBcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDestroyed(fake, "basis_project"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDefaultTagsConfig(fake, []string{"cost-center:42", "owner:ops"}, []string{"env:test"}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_project.test", "tags.#", "1"),
+					resource.TestCheckTypeSetElemAttr("basis_project.test", "tags.*", "env:test"),
+					resource.TestCheckResourceAttr("basis_project.test", "tags_all.#", "3"),
+					resource.TestCheckTypeSetElemAttr("basis_project.test", "tags_all.*", "cost-center:42"),
+					resource.TestCheckTypeSetElemAttr("basis_project.test", "tags_all.*", "owner:ops"),
+					resource.TestCheckResourceAttr("basis_vdc.test", "tags.#", "0"),
+					resource.TestCheckResourceAttr("basis_vdc.test", "tags_all.#", "2"),
+				),
+			},
+			{
+				// a change of the defaults updates every resource in place
+				Config: testAccDefaultTagsConfig(fake, []string{"cost-center:43"}, []string{"env:test"}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_project.test", "tags.#", "1"),
+					resource.TestCheckResourceAttr("basis_project.test", "tags_all.#", "2"),
+					resource.TestCheckTypeSetElemAttr("basis_project.test", "tags_all.*", "cost-center:43"),
+					resource.TestCheckResourceAttr("basis_vdc.test", "tags_all.#", "1"),
+					resource.TestCheckTypeSetElemAttr("basis_vdc.test", "tags_all.*", "cost-center:43"),
+				),
+			},
+			{
+				ResourceName:      "basis_project.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// a tag set both ways stays in the resource tags
+				Config: testAccDefaultTagsConfig(fake, []string{"cost-center:43"}, []string{"env:test", "cost-center:43"}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_project.test", "tags.#", "2"),
+					resource.TestCheckResourceAttr("basis_project.test", "tags_all.#", "2"),
+				),
+			},
+			{
+				Config: testAccDefaultTagsConfig(fake, nil, nil),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("basis_project.test", "tags.#", "0"),
+					resource.TestCheckResourceAttr("basis_project.test", "tags_all.#", "0"),
+					resource.TestCheckResourceAttr("basis_vdc.test", "tags_all.#", "0"),
+				),
+			},
+		},
+	})
+}
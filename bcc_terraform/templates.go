@@ -1,6 +1,18 @@
 package bcc_terraform
 
-import "github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/pkg/errors"
+)
 
 func (args *Arguments) injectContextTemplateById() {
 	args.merge(Arguments{
@@ -27,6 +39,27 @@ func (args *Arguments) injectContextGetTemplate() {
 			Description: "id of the Template",
 		},
 	})
+	args.injectContextTemplateFilter()
+}
+
+// injectContextTemplateFilter adds the lookup of a template by a regular
+// expression to the data sources of templates.
+func (args *Arguments) injectContextTemplateFilter() {
+	args.merge(Arguments{
+		"name_regex": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			ValidateFunc:  validation.StringIsValidRegExp,
+			ConflictsWith: []string{"name", "id"},
+			Description:   "regular expression the name of the Template matches",
+		},
+		"most_recent": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "pick the most recent of several matching Templates instead of failing",
+		},
+	})
 }
 
 func (args *Arguments) injectResultTemplate() {
@@ -73,3 +106,177 @@ func (args *Arguments) injectResultListTemplate() {
 		},
 	})
 }
+
+// customizeDiffTemplateName plans the template_id of the template named by
+// template_name, so a name matching no or several templates fails the plan.
+// The lookup waits for the apply while the VDC is not known yet. Only another
+// template replaces the object, switching between template_id and a name of
+// the same template does not.
+func customizeDiffTemplateName(find func(vdc *bcc.Vdc, name string) (string, error)) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		if !d.NewValueKnown("template_name") {
+			return d.SetNewComputed("template_id")
+		}
+		name := d.Get("template_name").(string)
+		if name == "" {
+			return nil
+		}
+		vdcId := d.Get("vdc_id").(string)
+		if !d.NewValueKnown("vdc_id") || vdcId == "" {
+			return d.SetNewComputed("template_id")
+		}
+
+		manager := meta.(*CombinedConfig).Manager().WithContext(ctx)
+		vdc, err := manager.GetVdc(vdcId)
+		if err != nil {
+			return fmt.Errorf("vdc_id: vdc '%s' not found: %s", vdcId, err)
+		}
+		templateId, err := find(vdc, name)
+		if err != nil {
+			return fmt.Errorf("template_name: %s", err)
+		}
+		if templateId == d.Get("template_id").(string) {
+			return nil
+		}
+		if err = d.SetNew("template_id", templateId); err != nil {
+			return err
+		}
+		if d.Id() != "" {
+			return d.ForceNew("template_id")
+		}
+		return nil
+	}
+}
+
+func findTemplateIdByName(vdc *bcc.Vdc, name string) (string, error) {
+	template, err := findTemplate(vdc, templateFilter{name: name})
+	if err != nil {
+		return "", err
+	}
+	return template.ID, nil
+}
+
+func findKubernetesTemplateIdByName(vdc *bcc.Vdc, name string) (string, error) {
+	template, err := findKubernetesTemplate(vdc, templateFilter{name: name})
+	if err != nil {
+		return "", err
+	}
+	return template.ID, nil
+}
+
+// templateFilter selects a template by its name, case-insensitive, or by a
+// regular expression.
+type templateFilter struct {
+	name       string
+	nameRegex  string
+	mostRecent bool
+}
+
+func expandTemplateFilter(d *schema.ResourceData) templateFilter {
+	return templateFilter{
+		name:       d.Get("name").(string),
+		nameRegex:  d.Get("name_regex").(string),
+		mostRecent: d.Get("most_recent").(bool),
+	}
+}
+
+func findTemplate(vdc *bcc.Vdc, filter templateFilter) (*bcc.Template, error) {
+	templates, err := vdc.GetTemplates()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting list of templates")
+	}
+	return selectTemplate("Template", templates, func(t *bcc.Template) string { return t.Name }, filter)
+}
+
+func findKubernetesTemplate(vdc *bcc.Vdc, filter templateFilter) (*bcc.KubernetesTemplate, error) {
+	templates, err := vdc.GetKubernetesTemplates()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting list of kubernetes templates")
+	}
+	return selectTemplate("Kubernetes template", templates, func(t *bcc.KubernetesTemplate) string { return t.Name }, filter)
+}
+
+// selectTemplate returns the only template which matches the filter. With
+// most_recent the greatest name in natural order wins, e.g.
+// "Ubuntu 22.04 (2024-10)" over "Ubuntu 22.04 (2024-9)": the API reports no
+// creation time of a template, so the dates and versions in the names decide.
+func selectTemplate[T any](kind string, templates []T, nameOf func(T) string, filter templateFilter) (T, error) {
+	var none T
+	var matches []T
+	var description string
+
+	if filter.nameRegex != "" {
+		nameRegex, err := regexp.Compile(filter.nameRegex)
+		if err != nil {
+			return none, fmt.Errorf("name_regex: %s", err)
+		}
+		for _, template := range templates {
+			if nameRegex.MatchString(nameOf(template)) {
+				matches = append(matches, template)
+			}
+		}
+		description = fmt.Sprintf("name_regex '%s'", filter.nameRegex)
+	} else {
+		for _, template := range templates {
+			if strings.EqualFold(nameOf(template), filter.name) {
+				matches = append(matches, template)
+			}
+		}
+		description = fmt.Sprintf("name '%s'", filter.name)
+	}
+
+	switch {
+	case len(matches) == 0:
+		return none, fmt.Errorf("ERROR: %s with %s not found", kind, description)
+	case len(matches) == 1:
+		return matches[0], nil
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return naturalLess(nameOf(matches[j]), nameOf(matches[i]))
+	})
+	if !filter.mostRecent {
+		names := make([]string, len(matches))
+		for i, template := range matches {
+			names[i] = fmt.Sprintf("'%s'", nameOf(template))
+		}
+		return none, fmt.Errorf("ERROR: %d %ss match %s: %s. Set most_recent to pick the most recent one or narrow the filter",
+			len(matches), kind, description, strings.Join(names, ", "))
+	}
+	return matches[0], nil
+}
+
+// naturalLess compares the strings case-insensitive, with the runs of digits
+// compared as numbers.
+func naturalLess(a, b string) bool {
+	ra, rb := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+	for len(ra) > 0 && len(rb) > 0 {
+		if unicode.IsDigit(ra[0]) && unicode.IsDigit(rb[0]) {
+			na, nb := digitRun(ra), digitRun(rb)
+			// Compare the numbers by length and then digit by digit, so
+			// long numbers don't overflow.
+			ta, tb := strings.TrimLeft(string(ra[:na]), "0"), strings.TrimLeft(string(rb[:nb]), "0")
+			if len(ta) != len(tb) {
+				return len(ta) < len(tb)
+			}
+			if ta != tb {
+				return ta < tb
+			}
+			ra, rb = ra[na:], rb[nb:]
+			continue
+		}
+		if ra[0] != rb[0] {
+			return ra[0] < rb[0]
+		}
+		ra, rb = ra[1:], rb[1:]
+	}
+	return len(ra) < len(rb)
+}
+
+func digitRun(r []rune) int {
+	n := 0
+	for n < len(r) && unicode.IsDigit(r[n]) {
+		n++
+	}
+	return n
+}
@@ -1,11 +1,13 @@
 package bcc_terraform
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/pkg/errors"
@@ -41,25 +43,14 @@ func GetFirewallTemplateByName(d *schema.ResourceData, manager *bcc.Manager, vdc
 }
 
 func GetKubernetesTemplateByName(d *schema.ResourceData, manager *bcc.Manager, vdc *bcc.Vdc) (*bcc.KubernetesTemplate, error) {
-	templateName := d.Get("name").(string)
-	templates, err := vdc.GetKubernetesTemplates()
-
-	if err != nil {
-		return nil, errors.Wrap(err, "Error getting list of kubernetes templates")
-	}
-
-	for _, template := range templates {
-		if strings.EqualFold(template.Name, templateName) {
-			return template, nil
-		}
-	}
-
-	return nil, fmt.Errorf("ERROR: Kubernetes template with name '%s' not found", templateName)
-
+	return findKubernetesTemplate(vdc, expandTemplateFilter(d))
 }
 
 func GetKubernetesTemplateById(d *schema.ResourceData, manager *bcc.Manager, vdc *bcc.Vdc) (*bcc.KubernetesTemplate, error) {
 	templateId := d.Get("template_id").(string)
+	if templateId == "" {
+		return findKubernetesTemplate(vdc, templateFilter{name: d.Get("template_name").(string)})
+	}
 	template, err := manager.GetKubernetesTemplate(templateId)
 
 	if err != nil {
@@ -89,20 +80,7 @@ func GetKubernetesByName(d *schema.ResourceData, manager *bcc.Manager, vdc *bcc.
 }
 
 func GetTemplateByName(d *schema.ResourceData, manager *bcc.Manager, vdc *bcc.Vdc) (*bcc.Template, error) {
-	templateName := d.Get("name").(string)
-	templates, err := vdc.GetTemplates()
-
-	if err != nil {
-		return nil, errors.Wrap(err, "Error getting list of templates")
-	}
-
-	for _, template := range templates {
-		if strings.EqualFold(template.Name, templateName) {
-			return template, nil
-		}
-	}
-
-	return nil, fmt.Errorf("ERROR: Template with name '%s' not found", templateName)
+	return findTemplate(vdc, expandTemplateFilter(d))
 }
 
 func GetPlatformByName(d *schema.ResourceData, manager *bcc.Manager, vdc *bcc.Vdc) (*bcc.Platform, error) {
@@ -490,6 +468,37 @@ func MakePrefix(prefix *string, name string) string {
 	return fmt.Sprintf("%s.%s", *prefix, name)
 }
 
+// customizeDiffProviderDefault plans the provider default of key when the
+// resource does not set it. Once in the state, a change of the default makes
+// a diff like a change of the argument itself.
+func customizeDiffProviderDefault(key string, defaultValue func(meta interface{}) string) schema.CustomizeDiffFunc {
+	return func(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		if config := d.GetRawConfig(); !config.IsNull() && !config.GetAttr(key).IsNull() {
+			return nil
+		}
+		value := defaultValue(meta)
+		if value == "" {
+			return fmt.Errorf("`%s` must be set on the resource or on the provider", key)
+		}
+		if d.Get(key).(string) == value {
+			return nil
+		}
+		return d.SetNew(key, value)
+	}
+}
+
+// setProviderDefault sets key to the provider default when the data source
+// does not set it.
+func setProviderDefault(d *schema.ResourceData, key string, value string) error {
+	if _, ok := d.GetOk(key); ok {
+		return nil
+	}
+	if value == "" {
+		return fmt.Errorf("`%s` must be set on the data source or on the provider", key)
+	}
+	return d.Set(key, value)
+}
+
 func setResourceDataFromMap(d *schema.ResourceData, m map[string]interface{}) error {
 	for key, value := range m {
 		if strings.EqualFold(key, "id") {
@@ -518,17 +527,64 @@ func getRouterByNetwork(manager bcc.Manager, network bcc.Network) (router *bcc.R
 	return
 }
 
-func repeatOnError(f func() error, targerInterface interface{ WaitLock() error }) (err error) {
-	for j := 0; j < 15; j++ {
-		targerInterface.WaitLock()
-		err = f()
-		if err == nil {
-			return
+// callUnlocked waits until the object is unlocked and calls f. Failed requests
+// are repeated by the retry policy of the API client, see retryTransport.
+func callUnlocked(ctx context.Context, f func() error, target interface{ WaitLock() error }) error {
+	if err := waitLock(ctx, target); err != nil {
+		return err
+	}
+	if err := f(); err != nil {
+		if ctx.Err() != nil {
+			return contextError(ctx)
 		}
-		fmt.Printf("err: %v\n", err)
-		time.Sleep(time.Second)
+		return err
 	}
-	return
+	return nil
+}
+
+// waitLock waits until the object is unlocked. Unlike WaitLock it returns as
+// soon as ctx is done, so Ctrl-C and the resource timeouts stop the polling.
+// WaitLock itself can't be interrupted: its goroutine ends with the next poll
+// when the object was fetched by a manager bound to ctx (Manager().WithContext),
+// as the resources do, otherwise once the object is unlocked.
+func waitLock(ctx context.Context, target interface{ WaitLock() error }) error {
+	ctx = subsystemLogContext(ctx, waitSubsystem)
+	fields := map[string]interface{}{"object": fmt.Sprintf("%T", target)}
+	tflog.SubsystemDebug(ctx, waitSubsystem, "Waiting for the object to unlock", fields)
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- target.WaitLock()
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+		if err != nil && ctx.Err() != nil {
+			err = contextError(ctx)
+		}
+	case <-ctx.Done():
+		err = contextError(ctx)
+	}
+
+	fields["waited_ms"] = time.Since(start).Milliseconds()
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.SubsystemDebug(ctx, waitSubsystem, "Waiting for the object to unlock failed", fields)
+		return err
+	}
+	tflog.SubsystemDebug(ctx, waitSubsystem, "Object unlocked", fields)
+	return nil
+}
+
+// contextError explains why the operation was interrupted.
+func contextError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timeout exceeded while waiting for the operation to complete, " +
+			"consider increasing the resource timeouts")
+	}
+	return fmt.Errorf("operation cancelled while waiting for it to complete")
 }
 
 func GetServiseNetworkByVdc(vdc *bcc.Vdc) (*bcc.Network, error) {
@@ -684,6 +740,12 @@ func checkDatasourceNameOrId(d *schema.ResourceData) (search string, err error)
 	return "", fmt.Errorf("must be specified 'name' or 'id'")
 }
 
+// isNotFound reports whether the API answered the request with 404.
+func isNotFound(err error) bool {
+	apiErr, ok := err.(*bcc.ApiError)
+	return ok && apiErr.Code() == 404
+}
+
 func resourceReadCheck(d *schema.ResourceData, err interface{}, msg string) diag.Diagnostics {
 	if err.(*bcc.ApiError).Code() == 404 {
 		d.SetId("")
@@ -693,7 +755,7 @@ func resourceReadCheck(d *schema.ResourceData, err interface{}, msg string) diag
 	}
 }
 
-func ensureLocationCreated(vdcId string, manager *bcc.Manager) error {
+func ensureLocationCreated(ctx context.Context, vdcId string, manager *bcc.Manager) error {
 	vdc, err := manager.GetVdc(vdcId)
 	if err != nil {
 		return err
@@ -708,11 +770,16 @@ func ensureLocationCreated(vdcId string, manager *bcc.Manager) error {
 	for {
 		vdc, err := manager.GetVdc(vdcId)
 		if err != nil {
+			if ctx.Err() != nil {
+				return contextError(ctx)
+			}
 			return err
 		}
 		if vdc.Paas != nil && !vdc.Paas.Locked {
 			return nil
 		}
-		time.Sleep(time.Second)
+		if bcc.SleepWithContext(ctx, time.Second) != nil {
+			return contextError(ctx)
+		}
 	}
 }
@@ -0,0 +1,74 @@
+package bcc_terraform
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitLock_timeout(t *testing.T) {
+	fake := newFakeBcc(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	manager := testManager(t, fake).WithContext(ctx)
+
+	fake.SetLockPolls(1000)
+	project := testCreateProject(t, manager, "locked")
+
+	start := time.Now()
+	err := waitLock(ctx, project)
+	if err == nil || !strings.Contains(err.Error(), "timeout exceeded") {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("waiting stopped %s after the deadline", elapsed)
+	}
+}
+
+func TestWaitLock_cancel(t *testing.T) {
+	fake := newFakeBcc(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	manager := testManager(t, fake).WithContext(ctx)
+
+	fake.SetLockPolls(1000)
+	project := testCreateProject(t, manager, "locked")
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+	err := waitLock(ctx, project)
+	if err == nil || !strings.Contains(err.Error(), "operation cancelled") {
+		t.Fatalf("expected a cancellation, got %v", err)
+	}
+}
+
+func TestWaitLock_unlocked(t *testing.T) {
+	fake := newFakeBcc(t)
+	manager := testManager(t, fake)
+
+	fake.SetLockPolls(1)
+	project := testCreateProject(t, manager, "locked")
+
+	if err := waitLock(context.Background(), project); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestCallUnlocked_cancel(t *testing.T) {
+	fake := newFakeBcc(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	manager := testManager(t, fake).WithContext(ctx)
+	project := testCreateProject(t, manager, "project")
+	cancel()
+
+	calls := 0
+	err := callUnlocked(ctx, func() error {
+		calls++
+		return nil
+	}, project)
+	if err == nil || !strings.Contains(err.Error(), "operation cancelled") {
+		t.Fatalf("expected a cancellation, got %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("expected no attempts after cancellation, got %d", calls)
+	}
+}
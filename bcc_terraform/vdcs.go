@@ -9,9 +9,10 @@ func (args *Arguments) injectContextResourceVdc() {
 	args.merge(Arguments{
 		"project_id": {
 			Type:        schema.TypeString,
-			Required:    true,
+			Optional:    true,
+			Computed:    true,
 			ForceNew:    true,
-			Description: "id of the Project",
+			Description: "id of the Project, the provider `project_id` by default",
 		},
 		"name": {
 			Type:     schema.TypeString,
@@ -82,17 +83,36 @@ func (args *Arguments) injectContextResourceVdc() {
 				},
 			},
 		},
-		"tags": newTagNamesResourceSchema("tags of the VDC"),
+		"tags":     newTagNamesResourceSchema("tags of the VDC"),
+		"tags_all": newTagNamesDataSchema("tags of the VDC including the provider default tags"),
 	})
 }
 
+// defaultVdcId returns the `vdc_id` of the provider.
+func defaultVdcId(meta interface{}) string {
+	if config, ok := meta.(*CombinedConfig); ok {
+		return config.vdcID
+	}
+	return ""
+}
+
+// customizeDiffDefaultVdc plans the provider `vdc_id` for resources which do
+// not set it.
+var customizeDiffDefaultVdc = customizeDiffProviderDefault("vdc_id", defaultVdcId)
+
+// setDefaultVdcId sets the provider `vdc_id` for data sources which do not set it.
+func setDefaultVdcId(d *schema.ResourceData, meta interface{}) error {
+	return setProviderDefault(d, "vdc_id", defaultVdcId(meta))
+}
+
 func (args *Arguments) injectContextRequiredVdc() {
 	args.merge(Arguments{
 		"vdc_id": {
 			Type:        schema.TypeString,
-			Required:    true,
+			Optional:    true,
+			Computed:    true,
 			ForceNew:    true,
-			Description: "VDC identifier",
+			Description: "VDC identifier, the provider `vdc_id` by default",
 			ValidateDiagFunc: validation.ToDiagFunc(
 				validation.StringIsNotEmpty,
 			),
@@ -118,8 +138,9 @@ func (args *Arguments) injectContextRequiredVdcForData() {
 	args.merge(Arguments{
 		"vdc_id": {
 			Type:        schema.TypeString,
-			Required:    true,
-			Description: "id of the VDC",
+			Optional:    true,
+			Computed:    true,
+			Description: "id of the VDC, the provider `vdc_id` by default",
 		},
 	})
 }
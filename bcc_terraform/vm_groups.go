@@ -0,0 +1,582 @@
+package bcc_terraform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/mitchellh/hashstructure/v2"
+)
+
+func (args *Arguments) injectContextResourceVmGroup() {
+	vmTemplate := Defaults()
+	vmTemplate.injectVmGroupTemplate()
+	lbaasPool := Defaults()
+	lbaasPool.injectVmGroupLbaasPool()
+	member := Defaults()
+	member.injectVmGroupMember()
+
+	args.merge(Arguments{
+		"member_count": {
+			Type:         schema.TypeInt,
+			Required:     true,
+			ValidateFunc: validation.IntBetween(0, 100),
+			Description:  "number of the Vms in the group",
+		},
+		"name_prefix": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.StringLenBetween(1, 90),
+			Description:  "the Vms are named `<name_prefix>-<n>`, n counts from 1",
+		},
+		"affinity_group_id": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "id of the Affinity Group of the Vms",
+		},
+		"parallelism": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      5,
+			ValidateFunc: validation.IntBetween(1, 20),
+			Description:  "how many Vms are created or deleted at once",
+		},
+		"max_unavailable": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      1,
+			ValidateFunc: validation.IntAtLeast(1),
+			Description:  "how many Vms are replaced at once when `vm` changes",
+		},
+		"vm": {
+			Type:     schema.TypeList,
+			Required: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: vmTemplate,
+			},
+			Description: "the settings of every Vm of the group",
+		},
+		"lbaas_pool": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: lbaasPool,
+			},
+			Description: "the Lbaas pool the Vms are registered with",
+		},
+		"members": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: member,
+			},
+			Description: "the Vms of the group, ordered by index",
+		},
+	})
+}
+
+func (args *Arguments) injectVmGroupTemplate() {
+	args.merge(Arguments{
+		"cpu": {
+			Type:         schema.TypeInt,
+			Required:     true,
+			ValidateFunc: validation.IntBetween(1, 128),
+			Description:  "the number of virtual cpus",
+		},
+		"ram": {
+			Type:         schema.TypeFloat,
+			Required:     true,
+			ValidateFunc: validation.FloatAtLeast(0.5),
+			Description:  "memory of the Vm in gigabytes",
+		},
+		"template_id": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "id of the Template",
+		},
+		"user_data": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "script for cloud-init",
+		},
+		"floating": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "enable floating ip for the Vms",
+		},
+		"system_disk_size": {
+			Type:         schema.TypeInt,
+			Required:     true,
+			ValidateFunc: validation.IntAtLeast(1),
+			Description:  "the size of the System Disk in gigabytes",
+		},
+		"system_disk_storage_profile_id": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "id of the Storage profile of the System Disk",
+		},
+		"network_ids": {
+			Type:        schema.TypeList,
+			Required:    true,
+			MinItems:    1,
+			MaxItems:    10,
+			Description: "ids of the Networks, every Vm gets a port in each of them",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"firewall_templates": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Description: "list of firewall templates ids of the ports",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"tags": newTagNamesResourceSchema("tags of the Vms"),
+	})
+}
+
+func (args *Arguments) injectVmGroupLbaasPool() {
+	args.merge(Arguments{
+		"lbaas_id": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "id of the Lbaas",
+		},
+		"pool_id": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "id of the Lbaas pool",
+		},
+		"port": {
+			Type:         schema.TypeInt,
+			Required:     true,
+			ValidateFunc: validation.IsPortNumber,
+			Description:  "the port of the Vms the pool sends the traffic to",
+		},
+		"weight": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      1,
+			ValidateFunc: validation.IntBetween(0, 256),
+			Description:  "the weight of the Vms in the pool",
+		},
+	})
+}
+
+func (args *Arguments) injectVmGroupMember() {
+	args.merge(Arguments{
+		"index": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "index of the Vm in the group, counting from 0",
+		},
+		"id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "id of the Vm",
+		},
+		"name": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "name of the Vm",
+		},
+		"ip_addresses": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "the private ip addresses of the Vm, in the order of `network_ids`",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"floating_ip": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "the floating ip of the Vm",
+		},
+		"config_hash": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "hash of the `vm` block the Vm was created from",
+		},
+	})
+}
+
+// vmGroupConfig holds the settings every member of the group is created from.
+type vmGroupConfig struct {
+	namePrefix      string
+	cpu             int
+	ram             float64
+	userData        string
+	floating        bool
+	sysDiskSize     int
+	template        *bcc.Template
+	storageProfile  *bcc.StorageProfile
+	networks        []*bcc.Network
+	firewalls       []*bcc.FirewallTemplate
+	tags            []bcc.Tag
+	affinityGroupId string
+	hash            string
+}
+
+// vmGroupMember is an item of `members`.
+type vmGroupMember struct {
+	index       int
+	id          string
+	name        string
+	ipAddresses []string
+	floatingIp  string
+	configHash  string
+}
+
+// vmGroupPool is the `lbaas_pool` block.
+type vmGroupPool struct {
+	lbaasId string
+	poolId  string
+	port    int
+	weight  int
+}
+
+func vmGroupMemberName(namePrefix string, index int) string {
+	return fmt.Sprintf("%s-%d", namePrefix, index+1)
+}
+
+// vmGroupConfigHash identifies the settings a member is created from, a
+// member with another hash is replaced. Sets are hashed as sorted lists so
+// the hash is the same in the plan and in the apply.
+func vmGroupConfigHash(vm []interface{}, affinityGroupId string) (string, error) {
+	normalized := make(map[string]interface{})
+	if len(vm) > 0 && vm[0] != nil {
+		for key, value := range vm[0].(map[string]interface{}) {
+			if set, ok := value.(*schema.Set); ok {
+				items := make([]string, 0, set.Len())
+				for _, item := range set.List() {
+					items = append(items, item.(string))
+				}
+				sort.Strings(items)
+				value = items
+			}
+			normalized[key] = value
+		}
+	}
+	normalized["affinity_group_id"] = affinityGroupId
+
+	hash, err := hashstructure.Hash(normalized, hashstructure.FormatV2, nil)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash), nil
+}
+
+func expandVmGroupConfig(d *schema.ResourceData, meta interface{}, manager *bcc.Manager, vdc *bcc.Vdc) (*vmGroupConfig, error) {
+	hash, err := vmGroupConfigHash(d.Get("vm").([]interface{}), d.Get("affinity_group_id").(string))
+	if err != nil {
+		return nil, fmt.Errorf("crash via compute hash: %s", err)
+	}
+
+	config := &vmGroupConfig{
+		namePrefix:      d.Get("name_prefix").(string),
+		cpu:             d.Get("vm.0.cpu").(int),
+		ram:             d.Get("vm.0.ram").(float64),
+		userData:        d.Get("vm.0.user_data").(string),
+		floating:        d.Get("vm.0.floating").(bool),
+		sysDiskSize:     d.Get("vm.0.system_disk_size").(int),
+		tags:            expandTagNames(d.Get("vm.0.tags").(*schema.Set), meta),
+		affinityGroupId: d.Get("affinity_group_id").(string),
+		hash:            hash,
+	}
+
+	if config.template, err = manager.GetTemplate(d.Get("vm.0.template_id").(string)); err != nil {
+		return nil, fmt.Errorf("crash via getting template: %s", err)
+	}
+	if config.storageProfile, err = vdc.GetStorageProfile(d.Get("vm.0.system_disk_storage_profile_id").(string)); err != nil {
+		return nil, fmt.Errorf("crash via getting storage profile: %s", err)
+	}
+	for _, networkId := range d.Get("vm.0.network_ids").([]interface{}) {
+		network, err := manager.GetNetwork(networkId.(string))
+		if err != nil {
+			return nil, fmt.Errorf("crash via getting network: %s", err)
+		}
+		config.networks = append(config.networks, network)
+	}
+	for _, firewallId := range d.Get("vm.0.firewall_templates").(*schema.Set).List() {
+		firewall, err := manager.GetFirewallTemplate(firewallId.(string))
+		if err != nil {
+			return nil, fmt.Errorf("crash via getting Firewall Template: %s", err)
+		}
+		config.firewalls = append(config.firewalls, firewall)
+	}
+	return config, nil
+}
+
+func expandVmGroupPool(items interface{}) *vmGroupPool {
+	list := items.([]interface{})
+	if len(list) == 0 || list[0] == nil {
+		return nil
+	}
+	args := list[0].(map[string]interface{})
+	return &vmGroupPool{
+		lbaasId: args["lbaas_id"].(string),
+		poolId:  args["pool_id"].(string),
+		port:    args["port"].(int),
+		weight:  args["weight"].(int),
+	}
+}
+
+func expandVmGroupMembers(items interface{}) map[int]*vmGroupMember {
+	members := make(map[int]*vmGroupMember)
+	for _, item := range items.([]interface{}) {
+		args := item.(map[string]interface{})
+		member := &vmGroupMember{
+			index:       args["index"].(int),
+			id:          args["id"].(string),
+			name:        args["name"].(string),
+			ipAddresses: interfacesToStrings(args["ip_addresses"].([]interface{})),
+			floatingIp:  args["floating_ip"].(string),
+			configHash:  args["config_hash"].(string),
+		}
+		members[member.index] = member
+	}
+	return members
+}
+
+func flattenVmGroupMembers(members map[int]*vmGroupMember) []interface{} {
+	indexes := make([]int, 0, len(members))
+	for index := range members {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	flattened := make([]interface{}, len(indexes))
+	for i, index := range indexes {
+		member := members[index]
+		flattened[i] = map[string]interface{}{
+			"index":        member.index,
+			"id":           member.id,
+			"name":         member.name,
+			"ip_addresses": member.ipAddresses,
+			"floating_ip":  member.floatingIp,
+			"config_hash":  member.configHash,
+		}
+	}
+	return flattened
+}
+
+// refreshVmGroupMember reads the name and the addresses of the member.
+func refreshVmGroupMember(member *vmGroupMember, vm *bcc.Vm) {
+	member.name = vm.Name
+	member.ipAddresses = make([]string, 0, len(vm.Ports))
+	for _, port := range vm.Ports {
+		if port.IpAddress != nil {
+			member.ipAddresses = append(member.ipAddresses, *port.IpAddress)
+		}
+	}
+	member.floatingIp = ""
+	if vm.Floating != nil && vm.Floating.IpAddress != nil {
+		member.floatingIp = *vm.Floating.IpAddress
+	}
+}
+
+// createVmGroupMember creates the Vm with its ports. A member is returned as
+// soon as the Vm exists, so the state keeps it when a later step fails; such
+// a member has no config_hash and is replaced by the next apply.
+func createVmGroupMember(ctx context.Context, meta interface{}, manager *bcc.Manager, vdc *bcc.Vdc, config *vmGroupConfig, pool *vmGroupPool, index int) (*vmGroupMember, error) {
+	systemDisk := bcc.NewDisk(vmSystemDiskName, config.sysDiskSize, config.storageProfile)
+	var floatingIp *string
+	if config.floating {
+		floatingIpStr := "RANDOM_FIP"
+		floatingIp = &floatingIpStr
+	}
+
+	vm := bcc.NewVm(
+		vmGroupMemberName(config.namePrefix, index), config.cpu, config.ram, config.template, nil,
+		&config.userData, nil, []*bcc.Disk{&systemDisk}, floatingIp,
+	)
+	vm.Tags = config.tags
+	if config.affinityGroupId != "" {
+		vm.AffinityGroups = []*bcc.AffinityGroup{{ID: config.affinityGroupId}}
+	}
+
+	// Only the API call is serialized with the other resources of the vdc,
+	// the members are built in parallel.
+	unlock, err := lockObject(ctx, meta, "vdc", vdc.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = vdc.CreateVm(&vm)
+	unlock()
+	if err != nil {
+		return nil, fmt.Errorf("crash via creating vm %s: %s", vm.Name, err)
+	}
+
+	member := &vmGroupMember{index: index, id: vm.ID, name: vm.Name}
+	if err = waitLock(ctx, vm); err != nil {
+		return member, err
+	}
+
+	for _, network := range config.networks {
+		port := bcc.NewPort(network, config.firewalls, "0.0.0.0")
+		if err = callUnlocked(ctx, func() error { return vm.ConnectPort(&port, false) }, vm); err != nil {
+			return member, fmt.Errorf("crash via creating port of vm %s: %s", vm.Name, err)
+		}
+		if err = waitLock(ctx, vm); err != nil {
+			return member, err
+		}
+	}
+
+	if pool != nil {
+		if err = registerVmGroupMembers(ctx, meta, manager, pool, []string{vm.ID}, true); err != nil {
+			return member, err
+		}
+	}
+
+	created, err := manager.GetVm(vm.ID)
+	if err != nil {
+		return member, fmt.Errorf("crash via getting vm %s: %s", vm.Name, err)
+	}
+	refreshVmGroupMember(member, created)
+	member.configHash = config.hash
+	tflog.Debug(ctx, "VM group member created", map[string]interface{}{"index": index, "id": vm.ID})
+
+	return member, nil
+}
+
+// deleteVmGroupMember deletes the Vm with its ports and floating ip. A Vm
+// which is gone already is not an error.
+func deleteVmGroupMember(ctx context.Context, meta interface{}, manager *bcc.Manager, pool *vmGroupPool, member *vmGroupMember) error {
+	if pool != nil {
+		if err := registerVmGroupMembers(ctx, meta, manager, pool, []string{member.id}, false); err != nil {
+			return err
+		}
+	}
+
+	vm, err := manager.GetVm(member.id)
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("crash via getting vm %s: %s", member.name, err)
+	}
+
+	if vm.Floating != nil {
+		vm.Floating = &bcc.Port{IpAddress: nil}
+		if err = callUnlocked(ctx, vm.Update, vm); err != nil {
+			return fmt.Errorf("crash via releasing floating ip of vm %s: %s", vm.Name, err)
+		}
+	}
+	for _, port := range vm.Ports {
+		if err = releaseVmPort(ctx, manager, vm, port.ID, true); err != nil {
+			return err
+		}
+	}
+
+	if err = waitLock(ctx, vm); err != nil {
+		return err
+	}
+	if err = vm.Delete(); err != nil {
+		return fmt.Errorf("crash via deleting vm %s: %s", vm.Name, err)
+	}
+	if err = waitLock(ctx, vm); err != nil && !isNotFound(err) {
+		return err
+	}
+	tflog.Debug(ctx, "VM group member deleted", map[string]interface{}{"index": member.index, "id": member.id})
+
+	return nil
+}
+
+// registerVmGroupMembers adds the Vms to the pool, or removes them from it.
+// Members of other Vms are kept, a Vm registered already gets the weight of
+// the group.
+func registerVmGroupMembers(ctx context.Context, meta interface{}, manager *bcc.Manager, pool *vmGroupPool, vmIds []string, register bool) error {
+	unlock, err := lockObject(ctx, meta, "lbaas", pool.lbaasId)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	lbaas, err := manager.GetLoadBalancer(pool.lbaasId)
+	if err != nil {
+		if !register && isNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("crash via getting Lbaas: %s", err)
+	}
+	lbaasPool, err := lbaas.GetLoadBalancerPool(pool.poolId)
+	if err != nil {
+		if !register && isNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("crash via getting Lbaas pool: %s", err)
+	}
+
+	ids := make(map[string]bool, len(vmIds))
+	for _, vmId := range vmIds {
+		ids[vmId] = true
+	}
+	members := make([]*bcc.PoolMember, 0, len(lbaasPool.Members)+len(vmIds))
+	for _, member := range lbaasPool.Members {
+		if member.Vm == nil || !ids[member.Vm.ID] {
+			members = append(members, member)
+		}
+	}
+	if !register && len(members) == len(lbaasPool.Members) {
+		return nil
+	}
+
+	if register {
+		for _, vmId := range vmIds {
+			vm, err := manager.GetVm(vmId)
+			if err != nil {
+				return fmt.Errorf("crash via getting vm by id: %s", err)
+			}
+			tmpVm := bcc.TmpVm{
+				ID: vm.ID, Name: vm.Name, Cpu: vm.Cpu, Ram: vm.Ram, Power: vm.Power, Platform: vm.Platform.ID, Vdc: vm.Vdc,
+			}
+			member := bcc.NewLoadBalancerPoolMember(pool.port, pool.weight, &tmpVm)
+			members = append(members, &member)
+		}
+	}
+
+	lbaasPool.Members = members
+	if err = callUnlocked(ctx, func() error { return lbaas.UpdatePool(&lbaasPool) }, lbaas); err != nil {
+		return fmt.Errorf("crash via updating Lbaas pool: %s", err)
+	}
+	return waitLock(ctx, lbaas)
+}
+
+// forEachParallel calls f for every item, at most parallelism calls at once.
+// Every item is tried, the errors are joined.
+func forEachParallel(ctx context.Context, items []int, parallelism int, f func(item int) error) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+	sem := make(chan struct{}, parallelism)
+
+	for _, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return errors.Join(append(errs, contextError(ctx))...)
+		}
+		wg.Add(1)
+		go func(item int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := f(item); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(item)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
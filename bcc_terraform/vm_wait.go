@@ -0,0 +1,244 @@
+package bcc_terraform
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const defaultVmWaitTimeout = 5 * time.Minute
+
+// vmWaitPollInterval is how often the readiness of a new Vm is checked.
+var vmWaitPollInterval = 5 * time.Second
+
+func vmWaitTimeoutSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "5m",
+		ValidateFunc: validateDuration,
+		Description:  "how long to wait, e.g. `10m`",
+	}
+}
+
+func (args *Arguments) injectVmWaitFor() {
+	args.merge(Arguments{
+		"ip": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "wait until every port of the Vm has an ip address",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"timeout": vmWaitTimeoutSchema(),
+				},
+			},
+		},
+		"tcp": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "wait until a tcp port of the Vm accepts connections",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"port": {
+						Type:         schema.TypeInt,
+						Required:     true,
+						ValidateFunc: validation.IsPortNumber,
+						Description:  "the tcp port, e.g. 22",
+					},
+					"host": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "the address to connect to, the floating ip or else the first private ip by default",
+					},
+					"timeout": vmWaitTimeoutSchema(),
+				},
+			},
+		},
+		"cloud_init": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "wait until a marker url served by the Vm answers",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"url": {
+						Type:         schema.TypeString,
+						Required:     true,
+						ValidateFunc: validation.StringIsNotWhiteSpace,
+						Description:  "the marker url, `{ip}` is replaced with the floating ip or else the first private ip",
+					},
+					"status_code": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						Default:      http.StatusOK,
+						ValidateFunc: validation.IntBetween(100, 599),
+						Description:  "the expected status code",
+					},
+					"timeout": vmWaitTimeoutSchema(),
+				},
+			},
+		},
+	})
+}
+
+// waitVmReady runs the checks of the `wait_for` block on a new Vm, in the
+// order ip, tcp, cloud_init.
+func waitVmReady(ctx context.Context, d *schema.ResourceData, manager *bcc.Manager) error {
+	if _, ok := d.GetOk("wait_for.0"); !ok {
+		return nil
+	}
+	ctx = subsystemLogContext(ctx, waitSubsystem)
+
+	if _, ok := d.GetOk("wait_for.0.ip.0"); ok {
+		timeout := vmWaitTimeout(d, "wait_for.0.ip.0.timeout")
+		if err := waitVmIp(ctx, manager, d.Id(), timeout); err != nil {
+			return fmt.Errorf("wait_for.0.ip: %s", err)
+		}
+	}
+
+	if _, ok := d.GetOk("wait_for.0.tcp.0"); ok {
+		timeout := vmWaitTimeout(d, "wait_for.0.tcp.0.timeout")
+		host := d.Get("wait_for.0.tcp.0.host").(string)
+		if host == "" {
+			var err error
+			if host, err = vmWaitAddress(manager, d.Id()); err != nil {
+				return fmt.Errorf("wait_for.0.tcp: %s", err)
+			}
+		}
+		address := net.JoinHostPort(host, strconv.Itoa(d.Get("wait_for.0.tcp.0.port").(int)))
+		if err := waitVmTcp(ctx, address, timeout); err != nil {
+			return fmt.Errorf("wait_for.0.tcp: %s", err)
+		}
+	}
+
+	if _, ok := d.GetOk("wait_for.0.cloud_init.0"); ok {
+		timeout := vmWaitTimeout(d, "wait_for.0.cloud_init.0.timeout")
+		url := d.Get("wait_for.0.cloud_init.0.url").(string)
+		if strings.Contains(url, "{ip}") {
+			host, err := vmWaitAddress(manager, d.Id())
+			if err != nil {
+				return fmt.Errorf("wait_for.0.cloud_init: %s", err)
+			}
+			url = strings.ReplaceAll(url, "{ip}", host)
+		}
+		statusCode := d.Get("wait_for.0.cloud_init.0.status_code").(int)
+		if err := waitVmCloudInit(ctx, url, statusCode, timeout); err != nil {
+			return fmt.Errorf("wait_for.0.cloud_init: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func vmWaitTimeout(d *schema.ResourceData, key string) time.Duration {
+	timeout, err := time.ParseDuration(d.Get(key).(string))
+	if err != nil {
+		return defaultVmWaitTimeout
+	}
+	return timeout
+}
+
+// vmWaitAddress returns the floating ip of the Vm, or else its first private ip.
+func vmWaitAddress(manager *bcc.Manager, vmId string) (string, error) {
+	vm, err := manager.GetVm(vmId)
+	if err != nil {
+		return "", err
+	}
+	if vm.Floating != nil && vm.Floating.IpAddress != nil && *vm.Floating.IpAddress != "" {
+		return *vm.Floating.IpAddress, nil
+	}
+	for _, port := range vm.Ports {
+		if isAssignedIp(port.IpAddress) {
+			return *port.IpAddress, nil
+		}
+	}
+	return "", fmt.Errorf("the vm has no ip address to connect to")
+}
+
+func isAssignedIp(ip *string) bool {
+	return ip != nil && *ip != "" && *ip != "0.0.0.0"
+}
+
+// pollVm calls check until it reports done, the timeout runs out or ctx is
+// done. The error of the last check is part of the timeout error.
+func pollVm(ctx context.Context, what string, timeout time.Duration, check func() (bool, error)) error {
+	fields := map[string]interface{}{"what": what, "timeout": timeout.String()}
+	tflog.SubsystemDebug(ctx, waitSubsystem, "Waiting for VM readiness", fields)
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+	for {
+		done, err := check()
+		if done {
+			fields["waited_ms"] = time.Since(start).Milliseconds()
+			tflog.SubsystemDebug(ctx, waitSubsystem, "VM is ready", fields)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return fmt.Errorf("%s after %s: %s", what, timeout, err)
+			}
+			return fmt.Errorf("%s after %s", what, timeout)
+		}
+		if sleepErr := bcc.SleepWithContext(ctx, min(vmWaitPollInterval, time.Until(deadline)+time.Millisecond)); sleepErr != nil {
+			return contextError(ctx)
+		}
+	}
+}
+
+func waitVmIp(ctx context.Context, manager *bcc.Manager, vmId string, timeout time.Duration) error {
+	return pollVm(ctx, "the vm has no ip address on every port", timeout, func() (bool, error) {
+		vm, err := manager.GetVm(vmId)
+		if err != nil {
+			return false, err
+		}
+		for _, port := range vm.Ports {
+			if !isAssignedIp(port.IpAddress) {
+				return false, fmt.Errorf("port %s has no ip address", port.ID)
+			}
+		}
+		return true, nil
+	})
+}
+
+func waitVmTcp(ctx context.Context, address string, timeout time.Duration) error {
+	dialer := net.Dialer{Timeout: vmWaitPollInterval}
+	return pollVm(ctx, fmt.Sprintf("%s is not reachable", address), timeout, func() (bool, error) {
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return false, err
+		}
+		conn.Close()
+		return true, nil
+	})
+}
+
+func waitVmCloudInit(ctx context.Context, url string, statusCode int, timeout time.Duration) error {
+	client := &http.Client{Timeout: vmWaitPollInterval}
+	return pollVm(ctx, fmt.Sprintf("%s did not answer with %d", url, statusCode), timeout, func() (bool, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return false, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return false, err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != statusCode {
+			return false, fmt.Errorf("status %d", resp.StatusCode)
+		}
+		return true, nil
+	})
+}
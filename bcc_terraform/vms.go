@@ -1,11 +1,13 @@
 package bcc_terraform
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"strings"
+	"time"
 
 	"github.com/basis-cloud/bcc-go/bcc"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -44,6 +46,10 @@ func (args *Arguments) injectContextVmById() {
 func (args *Arguments) injectCreateVm() {
 	systemDisk := Defaults()
 	systemDisk.injectSystemDisk()
+	dataDisk := Defaults()
+	dataDisk.injectDataDisk()
+	waitFor := Defaults()
+	waitFor.injectVmWaitFor()
 
 	args.merge(Arguments{
 		"name": {
@@ -80,9 +86,18 @@ func (args *Arguments) injectCreateVm() {
 			Description: "memory of the Vm in gigabytes",
 		},
 		"template_id": {
-			Type:        schema.TypeString,
-			Required:    true,
-			Description: "id of the Template",
+			Type:         schema.TypeString,
+			ForceNew:     true,
+			Optional:     true,
+			Computed:     true,
+			ExactlyOneOf: []string{"template_id", "template_name"},
+			Description:  "id of the Template",
+		},
+		"template_name": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringIsNotWhiteSpace,
+			Description:  "name of the Template, looked up in the VDC instead of `template_id`",
 		},
 		"user_data": {
 			Type:        schema.TypeString,
@@ -99,12 +114,70 @@ func (args *Arguments) injectCreateVm() {
 			},
 			Description: "System disk.",
 		},
+		"data_disk": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 15,
+			Elem: &schema.Resource{
+				Schema: dataDisk,
+			},
+			Description: "Disks created, attached and deleted with the Vm.",
+		},
+		"on_destroy": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"delete_data_disks": {
+						Type:        schema.TypeBool,
+						Optional:    true,
+						Default:     false,
+						Description: "delete the disks of `disks` with the Vm instead of detaching them",
+					},
+					"delete_ports": {
+						Type:        schema.TypeBool,
+						Optional:    true,
+						Default:     false,
+						Description: "delete the ports of `networks` with the Vm instead of disconnecting them",
+					},
+					"release_floating_ip": {
+						Type:        schema.TypeBool,
+						Optional:    true,
+						Default:     true,
+						Description: "release the floating ip before the Vm is deleted",
+					},
+				},
+			},
+			Description: "What happens to the disks, ports and floating ip of the Vm when it is destroyed.",
+		},
+		"wait_for": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: waitFor,
+			},
+			Description: "Checks of the guest readiness run after the Vm is created, a failed check taints the Vm.",
+		},
 		"disks": {
 			Type:        schema.TypeSet,
 			Optional:    true,
 			Description: "list of Disks attached to the Vm",
 			Elem:        &schema.Schema{Type: schema.TypeString},
 		},
+		"exclusive_disks": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "whether `disks` lists every disk of the Vm, disable it to attach disks with basis_vm_disk_attachment",
+		},
+		"exclusive_networks": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "whether `networks` lists every port of the Vm, disable it to connect ports with basis_vm_network_interface",
+		},
 		"ports": {
 			Type:        schema.TypeList,
 			Optional:    true,
@@ -147,12 +220,35 @@ func (args *Arguments) injectCreateVm() {
 			Computed:    true,
 			Description: "floating ip for the Vm. May be omitted",
 		},
-		"tags": newTagNamesResourceSchema("tags of the Vm"),
+		"tags":     newTagNamesResourceSchema("tags of the Vm"),
+		"tags_all": newTagNamesDataSchema("tags of the Vm including the provider default tags"),
 		"power": {
-			Type:        schema.TypeBool,
+			Type:          schema.TypeBool,
+			Optional:      true,
+			Computed:      true,
+			ConflictsWith: []string{"power_state"},
+			Deprecated:    "Use power_state instead of power",
+			Description:   "power of vw on/off",
+		},
+		"power_state": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			Computed:      true,
+			ConflictsWith: []string{"power"},
+			ValidateFunc:  validation.StringInSlice([]string{vmPowerStateRunning, vmPowerStateStopped}, false),
+			Description:   "power state of the Vm, `running` or `stopped`",
+		},
+		"reboot_triggers": {
+			Type:        schema.TypeMap,
 			Optional:    true,
-			Default:     true,
-			Description: "power of vw on/off",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "arbitrary values which restart the running Vm when they change",
+		},
+		"graceful_shutdown_timeout": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validateDuration,
+			Description:  "how long to wait for the guest to shut down before the Vm is powered off",
 		},
 		"hot_add": {
 			Type:        schema.TypeBool,
@@ -292,8 +388,11 @@ func (args *Arguments) injectSystemDisk() {
 			Description: "id of the System Disk",
 		},
 		"name": {
-			Type:     schema.TypeString,
-			Computed: true,
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			ValidateFunc: validation.StringLenBetween(1, 100),
+			Description:  "name of the System Disk",
 		},
 		"size": {
 			Type:     schema.TypeInt,
@@ -311,6 +410,39 @@ func (args *Arguments) injectSystemDisk() {
 	})
 }
 
+func (args *Arguments) injectDataDisk() {
+	args.merge(Arguments{
+		"id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "id of the Disk",
+		},
+		"name": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.StringLenBetween(1, 100),
+			Description:  "name of the Disk",
+		},
+		"size": {
+			Type:         schema.TypeInt,
+			Required:     true,
+			ValidateFunc: validation.IntAtLeast(1),
+			Description:  "the size of the Disk in gigabytes",
+		},
+		"storage_profile_id": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "id of the Storage profile",
+		},
+		"external_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "external id of the volume. It can be empty",
+		},
+		"tags": newTagNamesResourceSchema("tags of the Disk"),
+	})
+}
+
 func (args *Arguments) injectDataSystemDisk() {
 	args.merge(Arguments{
 		"id": {
@@ -353,7 +485,7 @@ func (args *Arguments) injectResultListVm() {
 	})
 }
 
-func syncVmNetworks(d *schema.ResourceData, manager *bcc.Manager, vm *bcc.Vm) (err diag.Diagnostics) {
+func syncVmNetworks(ctx context.Context, d *schema.ResourceData, manager *bcc.Manager, vm *bcc.Vm) (err diag.Diagnostics) {
 	var targetDefinition string
 
 	if d.HasChange("networks") {
@@ -398,13 +530,13 @@ func syncVmNetworks(d *schema.ResourceData, manager *bcc.Manager, vm *bcc.Vm) (e
 	for _, item := range newNetworks {
 		newNetworksSet[item] = true
 	}
-	if len(newNetworksSet) == 0 && newFloating.(bool) {
+	if len(newNetworksSet) == 0 && newFloating.(bool) && d.Get("exclusive_networks").(bool) {
 		return diag.Errorf("floating cannot be added without existing networks")
 	}
 
 	if olfFloating.(bool) && !newFloating.(bool) {
 		vm.Floating = &bcc.Port{IpAddress: nil}
-		if err := repeatOnError(vm.Update, vm); err != nil {
+		if err := callUnlocked(ctx, vm.Update, vm); err != nil {
 			return diag.Errorf("Error with deletting floating for vm: %s", err)
 		}
 	}
@@ -413,23 +545,21 @@ func syncVmNetworks(d *schema.ResourceData, manager *bcc.Manager, vm *bcc.Vm) (e
 		if newNetworksSet[item] {
 			delete(newNetworksSet, item)
 		} else {
-			if err = disconnectVmOldPort(item, manager, vm); err != nil {
-				log.Printf("Error disconnecting new port: %v", err)
+			if err = disconnectVmOldPort(ctx, item, manager, vm); err != nil {
 				return err
 			}
 		}
 	}
 
 	for item := range newNetworksSet {
-		if err = connectVmNewPort(item, manager, vm); err != nil {
-			log.Printf("Error connecting new port: %v", err)
+		if err = connectVmNewPort(ctx, item, manager, vm); err != nil {
 			return err
 		}
 	}
 
 	if newFloating.(bool) {
 		vm.Floating = &bcc.Port{ID: "RANDOM_FIP"}
-		if err := repeatOnError(vm.Update, vm); err != nil {
+		if err := callUnlocked(ctx, vm.Update, vm); err != nil {
 			return diag.Errorf("Error with adding floating for vm: %s", err)
 		}
 	}
@@ -468,7 +598,7 @@ func parseVmNetworks(d interface{}) (networksIds []string) {
 	return
 }
 
-func connectVmNewPort(portId string, manager *bcc.Manager, vm *bcc.Vm) diag.Diagnostics {
+func connectVmNewPort(ctx context.Context, portId string, manager *bcc.Manager, vm *bcc.Vm) diag.Diagnostics {
 	port, err := manager.GetPort(portId)
 	if err != nil {
 		return diag.FromErr(err)
@@ -478,12 +608,12 @@ func connectVmNewPort(portId string, manager *bcc.Manager, vm *bcc.Vm) diag.Diag
 		if err = vm.DisconnectPort(port); err != nil {
 			return diag.FromErr(err)
 		}
-		if err = vm.WaitLock(); err != nil {
+		if err = waitLock(ctx, vm); err != nil {
 			return diag.FromErr(err)
 		}
 	}
 
-	log.Printf("Port `%s` will be Attached", port.ID)
+	tflog.Debug(ctx, "Port will be attached", map[string]interface{}{"port_id": port.ID})
 
 	if err = vm.ConnectPort(port, true); err != nil {
 		return diag.Errorf("Ports: Error Cannot attach port `%s`: %s", port.ID, err)
@@ -492,20 +622,19 @@ func connectVmNewPort(portId string, manager *bcc.Manager, vm *bcc.Vm) diag.Diag
 	return nil
 }
 
-func disconnectVmOldPort(portId string, manager *bcc.Manager, vm *bcc.Vm) diag.Diagnostics {
+func disconnectVmOldPort(ctx context.Context, portId string, manager *bcc.Manager, vm *bcc.Vm) diag.Diagnostics {
 	port, err := manager.GetPort(portId)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	if port.Connected != nil && port.Connected.ID == vm.ID {
-		log.Printf("Port %s found on vm and not mentioned in the state."+
-			" Port will be detached", port.ID)
+		tflog.Debug(ctx, "Port found on vm and not mentioned in the state, it will be detached", map[string]interface{}{"port_id": port.ID})
 
 		if err := vm.DisconnectPort(port); err != nil {
 			return diag.FromErr(err)
 		}
-		if err = vm.WaitLock(); err != nil {
+		if err = waitLock(ctx, vm); err != nil {
 			return diag.FromErr(err)
 		}
 	}
@@ -513,7 +642,7 @@ func disconnectVmOldPort(portId string, manager *bcc.Manager, vm *bcc.Vm) diag.D
 	return nil
 }
 
-func syncVmDisks(d *schema.ResourceData, manager *bcc.Manager, vdc *bcc.Vdc, vm *bcc.Vm) (err error) {
+func syncVmDisks(ctx context.Context, d *schema.ResourceData, manager *bcc.Manager, vdc *bcc.Vdc, vm *bcc.Vm) (err error) {
 	oldDisks, newDisks := d.GetChange("disks")
 	newDisksMap := make(map[string]bool)
 	oldDisksMap := make(map[string]bool)
@@ -531,7 +660,7 @@ func syncVmDisks(d *schema.ResourceData, manager *bcc.Manager, vdc *bcc.Vdc, vm
 		}
 	}
 
-	if err = detachVmDisks(oldDisksMap, manager, vm); err != nil {
+	if err = detachVmDisks(ctx, oldDisksMap, manager, vm); err != nil {
 		return fmt.Errorf("crash via detaching vm disks: %s", err)
 	}
 
@@ -539,39 +668,283 @@ func syncVmDisks(d *schema.ResourceData, manager *bcc.Manager, vdc *bcc.Vdc, vm
 		return fmt.Errorf("crash via attaching vm disks: %s", err)
 	}
 
-	// System disk resize
 	if d.HasChange("system_disk") {
-		systemDiskArgs := d.Get("system_disk.0").(map[string]interface{})
-		systemDiskId := systemDiskArgs["id"].(string)
-		diskSize := systemDiskArgs["size"].(int)
-		systemDisk, err := manager.GetDisk(systemDiskId)
+		if err = updateVmSystemDisk(ctx, d, manager, vdc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func updateVmSystemDisk(ctx context.Context, d *schema.ResourceData, manager *bcc.Manager, vdc *bcc.Vdc) error {
+	systemDisk, err := manager.GetDisk(d.Get("system_disk.0.id").(string))
+	if err != nil {
+		return err
+	}
+
+	if d.HasChange("system_disk.0.name") {
+		systemDisk.Name = d.Get("system_disk.0.name").(string)
+	}
+	if d.HasChange("system_disk.0.size") {
+		systemDisk.Size = d.Get("system_disk.0.size").(int)
+	}
+	if d.HasChange("system_disk.0.storage_profile_id") {
+		storageProfile, err := vdc.GetStorageProfile(d.Get("system_disk.0.storage_profile_id").(string))
 		if err != nil {
 			return err
 		}
+		systemDisk.StorageProfile = storageProfile
+	}
+
+	if err = systemDisk.Update(); err != nil {
+		return fmt.Errorf("crash via updating system disk: %s", err)
+	}
+	return waitLock(ctx, systemDisk)
+}
+
+// matchVmDataDisks pairs the `data_disk` blocks with the blocks of the
+// previous state by name, so a renamed block gets a new disk. It returns the
+// index of the old block for every new one, -1 for a disk to create, and the
+// indexes of the old blocks whose disks are to be deleted.
+func matchVmDataDisks(oldDisks []interface{}, newDisks []interface{}) (matches []int, removed []int) {
+	byName := make(map[string][]int)
+	for i, item := range oldDisks {
+		name := item.(map[string]interface{})["name"].(string)
+		byName[name] = append(byName[name], i)
+	}
 
-		if err = systemDisk.Resize(diskSize); err != nil {
-			return fmt.Errorf("crash via resizing disk: %s", err)
+	used := make([]bool, len(oldDisks))
+	matches = make([]int, len(newDisks))
+	for i, item := range newDisks {
+		matches[i] = -1
+		name := item.(map[string]interface{})["name"].(string)
+		if candidates := byName[name]; len(candidates) > 0 {
+			matches[i] = candidates[0]
+			used[candidates[0]] = true
+			byName[name] = candidates[1:]
 		}
+	}
+
+	for i := range oldDisks {
+		if !used[i] {
+			removed = append(removed, i)
+		}
+	}
+	return matches, removed
+}
+
+// syncVmDataDisks creates, updates and deletes the disks of the `data_disk`
+// blocks, see matchVmDataDisks for how a block finds its disk. The ids of the
+// planned blocks follow their position, so only the previous state is
+// trusted for them.
+func syncVmDataDisks(ctx context.Context, d *schema.ResourceData, meta interface{}, manager *bcc.Manager, vdc *bcc.Vdc, vm *bcc.Vm) error {
+	oldRaw, newRaw := d.GetChange("data_disk")
+	oldDisks := oldRaw.([]interface{})
+	newDisks := newRaw.([]interface{})
+	matches, removed := matchVmDataDisks(oldDisks, newDisks)
 
-		if !d.HasChange("system_disk.0.storage_profile_id") {
+	for _, i := range removed {
+		diskId := oldDisks[i].(map[string]interface{})["id"].(string)
+		if err := deleteVmDataDisk(ctx, manager, vm, diskId); err != nil {
 			return err
 		}
+	}
+
+	for i, item := range newDisks {
+		args := item.(map[string]interface{})
+		if matches[i] == -1 {
+			disk, err := createVmDataDisk(ctx, meta, manager, vdc, vm, args)
+			if err != nil {
+				return err
+			}
+			args["id"] = disk.ID
+			continue
+		}
+		oldArgs := oldDisks[matches[i]].(map[string]interface{})
+		args["id"] = oldArgs["id"]
+		if args["size"] == oldArgs["size"] && args["storage_profile_id"] == oldArgs["storage_profile_id"] &&
+			args["tags"].(*schema.Set).Equal(oldArgs["tags"].(*schema.Set)) {
+			continue
+		}
 
-		storageProfileId := d.Get("system_disk.0.storage_profile_id").(string)
-		storageProfile, err := vdc.GetStorageProfile(storageProfileId)
+		disk, err := manager.GetDisk(args["id"].(string))
 		if err != nil {
+			return fmt.Errorf("crash via getting data disk: %s", err)
+		}
+		disk.Size = args["size"].(int)
+		disk.Tags = expandTagNames(args["tags"].(*schema.Set), meta)
+		if disk.StorageProfile.ID != args["storage_profile_id"].(string) {
+			if disk.StorageProfile, err = GetStorageProfileById(args["storage_profile_id"].(string), manager, vdc); err != nil {
+				return err
+			}
+		}
+		if err = waitLock(ctx, disk); err != nil {
 			return err
 		}
+		if err = disk.Update(); err != nil {
+			return fmt.Errorf("crash via updating data disk with id='%s': %s", disk.ID, err)
+		}
+		if err = waitLock(ctx, disk); err != nil {
+			return err
+		}
+	}
 
-		err = systemDisk.UpdateStorageProfile(*storageProfile)
-		if err != nil {
+	// The ids of the new disks are needed by the read to find them.
+	return d.Set("data_disk", newDisks)
+}
+
+func createVmDataDisk(ctx context.Context, meta interface{}, manager *bcc.Manager, vdc *bcc.Vdc, vm *bcc.Vm, args map[string]interface{}) (*bcc.Disk, error) {
+	storageProfile, err := GetStorageProfileById(args["storage_profile_id"].(string), manager, vdc)
+	if err != nil {
+		return nil, err
+	}
+
+	disk := bcc.NewDisk(args["name"].(string), args["size"].(int), storageProfile)
+	disk.Vm = vm
+	disk.Tags = expandTagNames(args["tags"].(*schema.Set), meta)
+
+	// The disk is created in the vdc like a basis_disk, under the same lock.
+	unlock, err := lockObject(ctx, meta, "vdc", vdc.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = vdc.CreateDisk(&disk)
+	unlock()
+	if err != nil {
+		return nil, fmt.Errorf("crash via creating data disk '%s': %s", disk.Name, err)
+	}
+	if err = waitLock(ctx, disk); err != nil {
+		return nil, err
+	}
+	tflog.Debug(ctx, "Data disk created", map[string]interface{}{"vm_id": vm.ID, "disk_id": disk.ID})
+	return &disk, nil
+}
+
+// deleteVmDataDisk detaches the disk from the vm and deletes it. A disk which
+// is gone already is not an error.
+func deleteVmDataDisk(ctx context.Context, manager *bcc.Manager, vm *bcc.Vm, diskId string) error {
+	return releaseVmDisk(ctx, manager, vm, diskId, true)
+}
+
+// releaseVmDisk detaches the disk from the vm and, when deleteDisk is set,
+// deletes it. A disk which is gone or detached already is not an error.
+func releaseVmDisk(ctx context.Context, manager *bcc.Manager, vm *bcc.Vm, diskId string, deleteDisk bool) error {
+	disk, err := manager.GetDisk(diskId)
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("crash via getting disk: %s", err)
+	}
+
+	if disk.Vm != nil && disk.Vm.ID == vm.ID {
+		if err = vm.DetachDisk(disk); err != nil {
+			return fmt.Errorf("crash via detaching disk with id='%s': %s", disk.ID, err)
+		}
+		if err = waitLock(ctx, vm); err != nil {
 			return err
 		}
 	}
+	if !deleteDisk || (disk.Vm != nil && disk.Vm.ID != vm.ID) {
+		return nil
+	}
+	if err = disk.Delete(); err != nil {
+		return fmt.Errorf("crash via deleting disk with id='%s': %s", disk.ID, err)
+	}
+	if err = waitLock(ctx, disk); err != nil && !isNotFound(err) {
+		return err
+	}
+	tflog.Debug(ctx, "Disk deleted with the vm", map[string]interface{}{"vm_id": vm.ID, "disk_id": disk.ID})
+	return nil
+}
 
+// releaseVmPort disconnects the port from the vm and, when deletePort is set,
+// deletes it. A port which is gone or disconnected already is not an error.
+func releaseVmPort(ctx context.Context, manager *bcc.Manager, vm *bcc.Vm, portId string, deletePort bool) error {
+	port, err := manager.GetPort(portId)
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("crash via getting port: %s", err)
+	}
+
+	if port.Connected != nil && port.Connected.ID != vm.ID {
+		return nil
+	}
+	if deletePort {
+		if err = port.ForceDelete(); err != nil {
+			return fmt.Errorf("crash via deleting port with id='%s': %s", port.ID, err)
+		}
+		if err = waitLock(ctx, port); err != nil && !isNotFound(err) {
+			return err
+		}
+		tflog.Debug(ctx, "Port deleted with the vm", map[string]interface{}{"vm_id": vm.ID, "port_id": port.ID})
+		return nil
+	}
+	if port.Connected != nil {
+		if err = vm.DisconnectPort(port); err != nil {
+			return fmt.Errorf("crash via disconnecting port with id='%s': %s", port.ID, err)
+		}
+	}
 	return nil
 }
 
+// vmOnDestroy is the `on_destroy` block of the Vm.
+type vmOnDestroy struct {
+	deleteDataDisks   bool
+	deletePorts       bool
+	releaseFloatingIp bool
+}
+
+func expandVmOnDestroy(d *schema.ResourceData) vmOnDestroy {
+	onDestroy := vmOnDestroy{releaseFloatingIp: true}
+	if items := d.Get("on_destroy").([]interface{}); len(items) > 0 && items[0] != nil {
+		args := items[0].(map[string]interface{})
+		onDestroy.deleteDataDisks = args["delete_data_disks"].(bool)
+		onDestroy.deletePorts = args["delete_ports"].(bool)
+		onDestroy.releaseFloatingIp = args["release_floating_ip"].(bool)
+	}
+	return onDestroy
+}
+
+// flattenVmDataDisks returns the `data_disk` blocks in the order of the state
+// and the ids of the other disks. Data disks detached from the vm are
+// dropped, so the next apply creates them again.
+func flattenVmDataDisks(d *schema.ResourceData, meta interface{}, disks []*bcc.Disk) ([]interface{}, []string) {
+	attached := make(map[string]*bcc.Disk, len(disks))
+	for _, disk := range disks {
+		attached[disk.ID] = disk
+	}
+
+	dataDisks := make([]interface{}, 0)
+	for _, item := range d.Get("data_disk").([]interface{}) {
+		args := item.(map[string]interface{})
+		disk, ok := attached[args["id"].(string)]
+		if !ok {
+			continue
+		}
+		delete(attached, disk.ID)
+		dataDisks = append(dataDisks, map[string]interface{}{
+			"id":                 disk.ID,
+			"name":               disk.Name,
+			"size":               disk.Size,
+			"storage_profile_id": disk.StorageProfile.ID,
+			"external_id":        disk.ExternalID,
+			"tags":               flattenTagNames(args["tags"].(*schema.Set), meta, disk.Tags),
+		})
+	}
+
+	otherDisks := make([]string, 0, len(attached))
+	for _, disk := range disks {
+		if _, ok := attached[disk.ID]; ok {
+			otherDisks = append(otherDisks, disk.ID)
+		}
+	}
+	return dataDisks, otherDisks
+}
+
 func attachVmDisks(disks map[string]bool, manager *bcc.Manager, vm *bcc.Vm) (err error) {
 	for diskId, ok := range disks {
 		if !ok {
@@ -594,7 +967,7 @@ func attachVmDisks(disks map[string]bool, manager *bcc.Manager, vm *bcc.Vm) (err
 	return
 }
 
-func detachVmDisks(disks map[string]bool, manager *bcc.Manager, vm *bcc.Vm) (err error) {
+func detachVmDisks(ctx context.Context, disks map[string]bool, manager *bcc.Manager, vm *bcc.Vm) (err error) {
 	for diskId, ok := range disks {
 		if !ok {
 			continue
@@ -605,7 +978,7 @@ func detachVmDisks(disks map[string]bool, manager *bcc.Manager, vm *bcc.Vm) (err
 		}
 
 		if disk.Vm != nil && disk.Vm.ID == vm.ID {
-			log.Printf("Disk %s found on vm and not mentioned in the state. Disk will be detached", disk.ID)
+			tflog.Debug(ctx, "Disk found on vm and not mentioned in the state, it will be detached", map[string]interface{}{"disk_id": disk.ID})
 			if err = vm.DetachDisk(disk); err != nil {
 				return fmt.Errorf("crash via detaching disk with id='%s': %s", disk.ID, err)
 			}
@@ -614,3 +987,115 @@ func detachVmDisks(disks map[string]bool, manager *bcc.Manager, vm *bcc.Vm) (err
 
 	return
 }
+
+// vmSystemDiskName is the name of the system disk when `system_disk.0.name`
+// is not set.
+const vmSystemDiskName = "Основной диск"
+
+const (
+	vmPowerStateRunning = "running"
+	vmPowerStateStopped = "stopped"
+)
+
+// vmShutdownPollInterval is how often the Vm is checked during a graceful shutdown.
+var vmShutdownPollInterval = 5 * time.Second
+
+func flattenVmPowerState(power bool) string {
+	if power {
+		return vmPowerStateRunning
+	}
+	return vmPowerStateStopped
+}
+
+// vmConfiguredPower returns the power set in the configuration, power_state
+// takes precedence over the deprecated power. ok is false when neither is set.
+func vmConfiguredPower(d *schema.ResourceData) (power bool, ok bool) {
+	config := d.GetRawConfig()
+	if state := config.GetAttr("power_state"); !state.IsNull() {
+		return state.AsString() == vmPowerStateRunning, true
+	}
+	if value := config.GetAttr("power"); !value.IsNull() {
+		return value.True(), true
+	}
+	return false, false
+}
+
+func vmGracefulShutdownTimeout(d *schema.ResourceData) time.Duration {
+	timeout, err := time.ParseDuration(d.Get("graceful_shutdown_timeout").(string))
+	if err != nil {
+		return 0
+	}
+	return timeout
+}
+
+// shutdownVm powers the Vm off. With a timeout the guest is asked to shut down
+// first and the power-off is forced only when it is still running afterwards.
+func shutdownVm(ctx context.Context, manager *bcc.Manager, vm *bcc.Vm, timeout time.Duration) error {
+	if timeout > 0 && vm.Power {
+		path := fmt.Sprintf("v1/vm/%s/state", vm.ID)
+		if err := manager.Request("POST", path, map[string]string{"state": "shutdown"}, nil); err != nil {
+			return fmt.Errorf("crash via shutting down vm: %s", err)
+		}
+
+		deadline := time.Now().Add(timeout)
+		for time.Now().Before(deadline) {
+			if err := bcc.SleepWithContext(ctx, min(vmShutdownPollInterval, time.Until(deadline))); err != nil {
+				return contextError(ctx)
+			}
+			current, err := manager.GetVm(vm.ID)
+			if err != nil {
+				return err
+			}
+			if !current.Power {
+				vm.Power = false
+				return nil
+			}
+		}
+		tflog.Info(ctx, "VM did not shut down in time, powering it off", map[string]interface{}{
+			"id":      vm.ID,
+			"timeout": timeout.String(),
+		})
+	}
+	return vm.PowerOff()
+}
+
+// restartVm restarts the Vm, gracefully when a shutdown timeout is set.
+func restartVm(ctx context.Context, manager *bcc.Manager, vm *bcc.Vm, timeout time.Duration) error {
+	tflog.Info(ctx, "Restarting VM", map[string]interface{}{"id": vm.ID})
+	if timeout <= 0 {
+		return vm.Reboot()
+	}
+	if err := shutdownVm(ctx, manager, vm, timeout); err != nil {
+		return err
+	}
+	return vm.PowerOn()
+}
+
+// filterOwnedVmPorts keeps only the ports listed in `networks` or `ports`, the
+// others are connected by basis_vm_network_interface or outside of Terraform.
+func filterOwnedVmPorts(d *schema.ResourceData, ports []*bcc.Port) []*bcc.Port {
+	owned := make(map[string]bool)
+	for _, portId := range collectVmNetworks(d) {
+		owned[portId] = true
+	}
+	filtered := make([]*bcc.Port, 0, len(ports))
+	for _, port := range ports {
+		if owned[port.ID] {
+			filtered = append(filtered, port)
+		}
+	}
+	return filtered
+}
+
+// filterOwnedVmDisks keeps only the disks listed in `disks`, the others are
+// attached by basis_vm_disk_attachment or outside of Terraform.
+func filterOwnedVmDisks(d *schema.ResourceData, disks []string) []string {
+	owned := d.Get("disks").(*schema.Set)
+	filtered := make([]string, 0, len(disks))
+	for _, disk := range disks {
+		if owned.Contains(disk) {
+			filtered = append(filtered, disk)
+		}
+	}
+	return filtered
+}